@@ -1,7 +1,13 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 )
 
 // Config holds all configuration for the API Gateway
@@ -10,7 +16,42 @@ type Config struct {
 	AuthServiceURL string
 	BlogServiceURL string
 	UserServiceURL string
-	JWTSecret      string
+	JWKSURL        string
+	PATVerifyURL   string
+	Introspection  IntrospectionConfig
+	ServiceClient  ServiceClientConfig
+	DataExport     DataExportConfig
+}
+
+// ServiceClientConfig holds the gateway's client credentials for calling the services
+type ServiceClientConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Audience     string
+	AuthAudience string
+	BlogAudience string
+	UserAudience string
+}
+
+// DataExportConfig holds configuration for personal data exports.
+// Archives are kept in the directory and offered through signed links until they expire.
+type DataExportConfig struct {
+	Dir           string
+	LinkTTL       time.Duration
+	SigningSecret string
+	Timeout       time.Duration // how long gathering an export may take
+	MinInterval   time.Duration // between a user's completed exports
+}
+
+// IntrospectionConfig holds the optional mode that checks every access token with the auth service,
+// so revoked tokens stop working within the cache TTL
+type IntrospectionConfig struct {
+	Enabled      bool
+	URL          string
+	ClientID     string
+	ClientSecret string
+	CacheTTL     time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
@@ -35,9 +76,87 @@ func LoadConfig() (*Config, error) {
 		userServiceURL = "http://localhost:8083"
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "dev_secret_key"
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = authServiceURL + "/.well-known/jwks.json"
+	}
+
+	patVerifyURL := os.Getenv("PAT_VERIFY_URL")
+	if patVerifyURL == "" {
+		patVerifyURL = authServiceURL + "/api/v1/auth/tokens/verify"
+	}
+
+	introspectionEnabled, _ := strconv.ParseBool(os.Getenv("TOKEN_INTROSPECTION_ENABLED"))
+
+	introspectionURL := os.Getenv("TOKEN_INTROSPECTION_URL")
+	if introspectionURL == "" {
+		introspectionURL = authServiceURL + "/oauth/introspect"
+	}
+
+	introspectionCacheTTL, err := strconv.Atoi(os.Getenv("TOKEN_INTROSPECTION_CACHE_TTL"))
+	if err != nil || introspectionCacheTTL < 0 {
+		introspectionCacheTTL = 10 // 10 seconds
+	}
+
+	serviceTokenURL := os.Getenv("SERVICE_TOKEN_URL")
+	if serviceTokenURL == "" {
+		serviceTokenURL = authServiceURL + "/oauth/token"
+	}
+
+	serviceAudience := os.Getenv("SERVICE_AUDIENCE")
+	if serviceAudience == "" {
+		serviceAudience = "api-gateway"
+	}
+
+	authServiceAudience := os.Getenv("AUTH_SERVICE_AUDIENCE")
+	if authServiceAudience == "" {
+		authServiceAudience = "auth-service"
+	}
+
+	blogServiceAudience := os.Getenv("BLOG_SERVICE_AUDIENCE")
+	if blogServiceAudience == "" {
+		blogServiceAudience = "blog-service"
+	}
+
+	userServiceAudience := os.Getenv("USER_SERVICE_AUDIENCE")
+	if userServiceAudience == "" {
+		userServiceAudience = "user-service"
+	}
+
+	exportDir := os.Getenv("DATA_EXPORT_DIR")
+	if exportDir == "" {
+		exportDir = filepath.Join(os.TempDir(), "data-exports")
+	}
+
+	exportLinkTTL, err := strconv.Atoi(os.Getenv("DATA_EXPORT_LINK_TTL"))
+	if err != nil || exportLinkTTL <= 0 {
+		exportLinkTTL = 24 * 60 // 24 hours
+	}
+
+	exportTimeout, err := strconv.Atoi(os.Getenv("DATA_EXPORT_TIMEOUT"))
+	if err != nil || exportTimeout <= 0 {
+		exportTimeout = 5 // 5 minutes
+	}
+
+	serviceClientID := os.Getenv("SERVICE_CLIENT_ID")
+	serviceClientSecret := os.Getenv("SERVICE_CLIENT_SECRET")
+	if serviceClientID == "" || serviceClientSecret == "" {
+		return nil, errors.New("SERVICE_CLIENT_ID and SERVICE_CLIENT_SECRET must be set")
+	}
+
+	exportMinInterval, err := strconv.Atoi(os.Getenv("DATA_EXPORT_MIN_INTERVAL"))
+	if err != nil || exportMinInterval <= 0 {
+		exportMinInterval = 24 // 24 hours
+	}
+
+	// Without a configured secret, links stop working when the gateway restarts, as do the archives it kept
+	exportSigningSecret := os.Getenv("DATA_EXPORT_SIGNING_SECRET")
+	if exportSigningSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		exportSigningSecret = hex.EncodeToString(secret)
 	}
 
 	return &Config{
@@ -45,6 +164,30 @@ func LoadConfig() (*Config, error) {
 		AuthServiceURL: authServiceURL,
 		BlogServiceURL: blogServiceURL,
 		UserServiceURL: userServiceURL,
-		JWTSecret:      jwtSecret,
+		JWKSURL:        jwksURL,
+		PATVerifyURL:   patVerifyURL,
+		Introspection: IntrospectionConfig{
+			Enabled:      introspectionEnabled,
+			URL:          introspectionURL,
+			ClientID:     os.Getenv("TOKEN_INTROSPECTION_CLIENT_ID"),
+			ClientSecret: os.Getenv("TOKEN_INTROSPECTION_CLIENT_SECRET"),
+			CacheTTL:     time.Duration(introspectionCacheTTL) * time.Second,
+		},
+		ServiceClient: ServiceClientConfig{
+			TokenURL:     serviceTokenURL,
+			ClientID:     serviceClientID,
+			ClientSecret: serviceClientSecret,
+			Audience:     serviceAudience,
+			AuthAudience: authServiceAudience,
+			BlogAudience: blogServiceAudience,
+			UserAudience: userServiceAudience,
+		},
+		DataExport: DataExportConfig{
+			Dir:           exportDir,
+			LinkTTL:       time.Duration(exportLinkTTL) * time.Minute,
+			SigningSecret: exportSigningSecret,
+			Timeout:       time.Duration(exportTimeout) * time.Minute,
+			MinInterval:   time.Duration(exportMinInterval) * time.Hour,
+		},
 	}, nil
 }
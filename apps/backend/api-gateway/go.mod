@@ -5,6 +5,7 @@ go 1.24.0
 require (
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/labstack/echo/v4 v4.11.3
+	github.com/vcd-simple-blog/packages/go/common v0.0.0
 )
 
 require (
@@ -21,3 +22,5 @@ require (
 )
 
 replace github.com/vcd-simple-blog/common => ../../../packages/go/common
+
+replace github.com/vcd-simple-blog/packages/go/common => ../../../packages/go/common
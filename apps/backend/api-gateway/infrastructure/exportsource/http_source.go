@@ -0,0 +1,61 @@
+// Package exportsource calls the data export endpoints the services expose.
+package exportsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/vcd-simple-blog/packages/go/common/dataexport"
+)
+
+// HTTPSource implements the usecases.ExportSource interface for a service that exposes
+// the data export endpoint under a base URL
+type HTTPSource struct {
+	name    string
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPSource creates a new HTTP export source that calls the service with the client
+func NewHTTPSource(name, baseURL string, client *http.Client) *HTTPSource {
+	return &HTTPSource{
+		name:    name,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// Name returns the name of the service
+func (s *HTTPSource) Name() string {
+	return s.name
+}
+
+// Export asks the service for a user's data
+func (s *HTTPSource) Export(ctx context.Context, userID string) (*dataexport.Bundle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/api/v1/internal/exports/"+url.PathEscape(userID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s export failed with status %d: %s", s.name, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var bundle dataexport.Bundle
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("%s export returned an invalid bundle: %w", s.name, err)
+	}
+	return &bundle, nil
+}
@@ -8,15 +8,20 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// authAPIPath is where the auth service serves its authentication endpoints
+const authAPIPath = "/api/v1/auth"
+
 // AuthHandler handles authentication requests
 type AuthHandler struct {
 	authServiceURL string
+	csrfHeader     string
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authServiceURL string) *AuthHandler {
+func NewAuthHandler(authServiceURL, csrfHeader string) *AuthHandler {
 	return &AuthHandler{
 		authServiceURL: authServiceURL,
+		csrfHeader:     csrfHeader,
 	}
 }
 
@@ -32,7 +37,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to marshal request")
 	}
 
-	resp, err := http.Post(h.authServiceURL+"/login", "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := h.post(c, "/login", jsonBody)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to auth service")
 	}
@@ -58,7 +63,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to marshal request")
 	}
 
-	resp, err := http.Post(h.authServiceURL+"/register", "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := h.post(c, "/register", jsonBody)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to auth service")
 	}
@@ -84,7 +89,7 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to marshal request")
 	}
 
-	resp, err := http.Post(h.authServiceURL+"/refresh", "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := h.post(c, "/refresh", jsonBody)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to auth service")
 	}
@@ -110,7 +115,7 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to marshal request")
 	}
 
-	resp, err := http.Post(h.authServiceURL+"/logout", "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := h.post(c, "/logout", jsonBody)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to auth service")
 	}
@@ -123,3 +128,31 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 
 	return c.JSON(resp.StatusCode, responseBody)
 }
+
+// post forwards a JSON request to the auth service, passing session cookies and the CSRF header
+// through in both directions so cookie session mode works behind the gateway, along with the client's address
+func (h *AuthHandler) post(c echo.Context, path string, jsonBody []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.Request().Context(), http.MethodPost, h.authServiceURL+authAPIPath+path, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, cookie := range c.Request().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if csrfToken := c.Request().Header.Get(h.csrfHeader); csrfToken != "" {
+		req.Header.Set(h.csrfHeader, csrfToken)
+	}
+
+	// Pass on the client's address, which the auth service keys login lockouts by
+	req.Header.Set(echo.HeaderXForwardedFor, c.RealIP())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, cookie := range resp.Header.Values("Set-Cookie") {
+		c.Response().Header().Add("Set-Cookie", cookie)
+	}
+	return resp, nil
+}
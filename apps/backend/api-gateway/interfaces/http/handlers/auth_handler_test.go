@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+const testCSRFHeader = "X-CSRF-Token"
+
+// newCookieAuthService stands in for the auth service in cookie session mode: login sets the session cookies,
+// and refresh and logout need the refresh token cookie and a CSRF header matching the CSRF cookie
+func newCookieAuthService(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "access_token", Value: "access-1", Path: "/", HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "refresh-1", Path: "/api/v1/auth", HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "csrf_token", Value: "csrf-1", Path: "/"})
+		writeJSON(w, http.StatusOK, map[string]interface{}{"expires_in": 900, "csrf_token": "csrf-1"})
+	})
+	sessionEndpoint := func(rotate bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			refresh, err := r.Cookie("refresh_token")
+			if err != nil || refresh.Value != "refresh-1" {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing refresh token"})
+				return
+			}
+			csrf, err := r.Cookie("csrf_token")
+			if err != nil || r.Header.Get(testCSRFHeader) != csrf.Value {
+				writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid CSRF token"})
+				return
+			}
+			if rotate {
+				http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "refresh-2", Path: "/api/v1/auth", HttpOnly: true})
+				writeJSON(w, http.StatusOK, map[string]interface{}{"expires_in": 900, "csrf_token": "csrf-1"})
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "", Path: "/api/v1/auth", MaxAge: -1})
+			writeJSON(w, http.StatusOK, map[string]string{"message": "Successfully logged out"})
+		}
+	}
+	mux.HandleFunc("/api/v1/auth/refresh", sessionEndpoint(true))
+	mux.HandleFunc("/api/v1/auth/logout", sessionEndpoint(false))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// serveGateway sends a request through the gateway's auth routes
+func serveGateway(handler *AuthHandler, path, body string, cookies []*http.Cookie, csrfToken string) *httptest.ResponseRecorder {
+	e := echo.New()
+	auth := e.Group("/api/v1/auth")
+	auth.POST("/login", handler.Login)
+	auth.POST("/refresh", handler.RefreshToken)
+	auth.POST("/logout", handler.Logout)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if csrfToken != "" {
+		req.Header.Set(testCSRFHeader, csrfToken)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCookieSessionThroughGateway(t *testing.T) {
+	authService := newCookieAuthService(t)
+	handler := NewAuthHandler(authService.URL, testCSRFHeader)
+
+	// Log in; the session cookies come back through the gateway
+	rec := serveGateway(handler, "/api/v1/auth/login", `{"email":"alice@example.com","password":"secret"}`, nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	cookies := rec.Result().Cookies()
+	names := map[string]string{}
+	for _, cookie := range cookies {
+		names[cookie.Name] = cookie.Value
+	}
+	if names["access_token"] != "access-1" || names["refresh_token"] != "refresh-1" || names["csrf_token"] != "csrf-1" {
+		t.Fatalf("login: got cookies %v", names)
+	}
+
+	// Refreshing without the CSRF header is refused
+	rec = serveGateway(handler, "/api/v1/auth/refresh", "", cookies, "")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("refresh without CSRF header: got status %d", rec.Code)
+	}
+
+	// Refreshing with the cookies and CSRF header rotates the refresh token
+	rec = serveGateway(handler, "/api/v1/auth/refresh", "", cookies, "csrf-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	rotated := rec.Result().Cookies()
+	if len(rotated) != 1 || rotated[0].Name != "refresh_token" || rotated[0].Value != "refresh-2" {
+		t.Fatalf("refresh: got cookies %v", rotated)
+	}
+
+	// Logging out clears the refresh token cookie
+	rec = serveGateway(handler, "/api/v1/auth/logout", "", cookies, "csrf-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("logout: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	cleared := rec.Result().Cookies()
+	if len(cleared) != 1 || cleared[0].Name != "refresh_token" || cleared[0].MaxAge >= 0 {
+		t.Fatalf("logout: got cookies %v", cleared)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
 
 	"github.com/labstack/echo/v4"
 )
@@ -11,12 +12,14 @@ import (
 // BlogHandler handles blog-related requests
 type BlogHandler struct {
 	blogServiceURL string
+	client         *http.Client
 }
 
-// NewBlogHandler creates a new blog handler
-func NewBlogHandler(blogServiceURL string) *BlogHandler {
+// NewBlogHandler creates a new blog handler that calls the blog service with the client
+func NewBlogHandler(blogServiceURL string, client *http.Client) *BlogHandler {
 	return &BlogHandler{
 		blogServiceURL: blogServiceURL,
+		client:         client,
 	}
 }
 
@@ -29,7 +32,7 @@ func (h *BlogHandler) GetAllBlogs(c echo.Context) error {
 		url += "?" + query
 	}
 
-	resp, err := http.Get(url)
+	resp, err := h.client.Get(url)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to blog service")
 	}
@@ -46,7 +49,7 @@ func (h *BlogHandler) GetAllBlogs(c echo.Context) error {
 // GetBlogByID retrieves a blog by ID
 func (h *BlogHandler) GetBlogByID(c echo.Context) error {
 	id := c.Param("id")
-	resp, err := http.Get(h.blogServiceURL + "/blogs/" + id)
+	resp, err := h.client.Get(h.blogServiceURL + "/blogs/" + id)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to blog service")
 	}
@@ -60,6 +63,32 @@ func (h *BlogHandler) GetBlogByID(c echo.Context) error {
 	return c.JSON(resp.StatusCode, responseBody)
 }
 
+// GetBlogBySlug retrieves a blog by its slug. The blog service's redirects from former slugs
+// are passed on to the client rather than followed.
+func (h *BlogHandler) GetBlogBySlug(c echo.Context) error {
+	client := *h.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Get(h.blogServiceURL + "/blogs/by-slug/" + url.PathEscape(c.Param("slug")))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to blog service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusMovedPermanently {
+		return c.Redirect(http.StatusMovedPermanently, resp.Header.Get(echo.HeaderLocation))
+	}
+
+	var responseBody map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to decode response")
+	}
+
+	return c.JSON(resp.StatusCode, responseBody)
+}
+
 // CreateBlog creates a new blog
 func (h *BlogHandler) CreateBlog(c echo.Context) error {
 	var requestBody map[string]interface{}
@@ -67,10 +96,6 @@ func (h *BlogHandler) CreateBlog(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	// Add user ID from token
-	userID := c.Get("userID").(string)
-	requestBody["author_id"] = userID
-
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to marshal request")
@@ -81,9 +106,9 @@ func (h *BlogHandler) CreateBlog(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create request")
 	}
 	req.Header.Set("Content-Type", "application/json")
+	actAsUser(c, req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to blog service")
 	}
@@ -105,10 +130,6 @@ func (h *BlogHandler) UpdateBlog(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	// Add user ID from token for authorization check in the blog service
-	userID := c.Get("userID").(string)
-	requestBody["user_id"] = userID
-
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to marshal request")
@@ -119,9 +140,9 @@ func (h *BlogHandler) UpdateBlog(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create request")
 	}
 	req.Header.Set("Content-Type", "application/json")
+	actAsUser(c, req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to blog service")
 	}
@@ -138,15 +159,13 @@ func (h *BlogHandler) UpdateBlog(c echo.Context) error {
 // DeleteBlog deletes a blog
 func (h *BlogHandler) DeleteBlog(c echo.Context) error {
 	id := c.Param("id")
-	userID := c.Get("userID").(string)
-
-	req, err := http.NewRequest("DELETE", h.blogServiceURL+"/blogs/"+id+"?user_id="+userID, nil)
+	req, err := http.NewRequest("DELETE", h.blogServiceURL+"/blogs/"+id, nil)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create request")
 	}
+	actAsUser(c, req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to blog service")
 	}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/api-gateway/usecases"
+)
+
+// exportPath is the path of the current user's exports
+const exportPath = "/api/v1/me/export/"
+
+// dataExportResponse represents a personal data export
+type dataExportResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// DataExportHandler handles personal data export requests
+type DataExportHandler struct {
+	exportUseCase *usecases.DataExportUseCase
+}
+
+// NewDataExportHandler creates a new data export handler
+func NewDataExportHandler(exportUseCase *usecases.DataExportUseCase) *DataExportHandler {
+	return &DataExportHandler{
+		exportUseCase: exportUseCase,
+	}
+}
+
+// StartExport starts exporting the current user's data
+func (h *DataExportHandler) StartExport(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	job, err := h.exportUseCase.StartExport(userID)
+	var rateLimitedErr *usecases.ExportRateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitedErr.RetryAfter.Seconds()))))
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start export")
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, exportPath+job.ID)
+	return c.JSON(http.StatusAccepted, h.response(job))
+}
+
+// GetExport retrieves one of the current user's exports, with its download link once it is ready
+func (h *DataExportHandler) GetExport(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	job, err := h.exportUseCase.GetExport(userID, c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, h.response(job))
+}
+
+// Download serves an export's archive to anyone with a valid download link
+func (h *DataExportHandler) Download(c echo.Context) error {
+	archive, err := h.exportUseCase.OpenDownload(c.Param("id"), c.QueryParam("expires"), c.QueryParam("signature"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Attachment(archive, "personal-data.zip")
+}
+
+// response converts an export to its response
+func (h *DataExportHandler) response(job *usecases.ExportJob) dataExportResponse {
+	response := dataExportResponse{
+		ID:        job.ID,
+		Status:    string(job.Status),
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+	}
+	if job.Status != usecases.ExportProcessing {
+		response.ExpiresAt = &job.ExpiresAt
+	}
+	if job.Status == usecases.ExportReady {
+		response.DownloadURL = exportPath + job.ID + "/download?" + h.exportUseCase.DownloadQuery(job)
+	}
+	return response
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+// actAsUser names the authenticated end user in a request to a service, which trusts it because
+// the gateway's service token is granted the act on behalf scope. The user's own token is forwarded
+// so the service reads their role, verification and any impersonating admin from what auth-service issued.
+func actAsUser(c echo.Context, req *http.Request) {
+	if userID, ok := c.Get("userID").(string); ok {
+		req.Header.Set(authz.OnBehalfOfHeader, userID)
+	}
+	if accessToken, ok := c.Get("accessToken").(string); ok {
+		req.Header.Set(authz.SubjectTokenHeader, accessToken)
+	}
+}
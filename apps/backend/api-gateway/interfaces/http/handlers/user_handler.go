@@ -11,19 +11,21 @@ import (
 // UserHandler handles user-related requests
 type UserHandler struct {
 	userServiceURL string
+	client         *http.Client
 }
 
-// NewUserHandler creates a new user handler
-func NewUserHandler(userServiceURL string) *UserHandler {
+// NewUserHandler creates a new user handler that calls the user service with the client
+func NewUserHandler(userServiceURL string, client *http.Client) *UserHandler {
 	return &UserHandler{
 		userServiceURL: userServiceURL,
+		client:         client,
 	}
 }
 
 // GetCurrentUser retrieves the current user's information
 func (h *UserHandler) GetCurrentUser(c echo.Context) error {
 	userID := c.Get("userID").(string)
-	resp, err := http.Get(h.userServiceURL + "/users/" + userID)
+	resp, err := h.client.Get(h.userServiceURL + "/users/" + userID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to user service")
 	}
@@ -54,9 +56,9 @@ func (h *UserHandler) UpdateCurrentUser(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create request")
 	}
+	actAsUser(c, req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to user service")
 	}
@@ -73,7 +75,7 @@ func (h *UserHandler) UpdateCurrentUser(c echo.Context) error {
 // GetUserByID retrieves a user by ID
 func (h *UserHandler) GetUserByID(c echo.Context) error {
 	id := c.Param("id")
-	resp, err := http.Get(h.userServiceURL + "/users/" + id)
+	resp, err := h.client.Get(h.userServiceURL + "/users/" + id)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to connect to user service")
 	}
@@ -1,44 +1,75 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
-	"strings"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/introspection"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
 )
 
 // AuthMiddleware handles authentication
 type AuthMiddleware struct {
-	jwtSecret string
+	keys          jwks.KeyProvider
+	pats          pat.Verifier
+	introspection *introspection.Client
+	cookies       cookieauth.Config
+	audience      string
 }
 
-// NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(jwtSecret string) *AuthMiddleware {
+// NewAuthMiddleware creates a new auth middleware that verifies tokens against the auth service's public keys.
+// Tokens are read from the Authorization header or, for browser sessions, the access token cookie.
+// Personal access tokens are verified by the auth service.
+// With an introspection client, valid access tokens are also checked with the auth service so revoked ones are refused.
+// Service tokens must be issued for the audience.
+func NewAuthMiddleware(keys jwks.KeyProvider, pats pat.Verifier, introspection *introspection.Client, cookies cookieauth.Config, audience string) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSecret: jwtSecret,
+		keys:          keys,
+		pats:          pats,
+		introspection: introspection,
+		cookies:       cookies,
+		audience:      audience,
 	}
 }
 
 // Authenticate validates the JWT token
 func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
+		tokenString, err := m.cookies.AccessToken(c.Request())
+		switch {
+		case errors.Is(err, cookieauth.ErrMissingToken):
 			return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
+		case errors.Is(err, cookieauth.ErrInvalidCSRFToken):
+			return echo.NewHTTPError(http.StatusForbidden, "invalid CSRF token")
+		case err != nil:
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization format")
 		}
 
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization format")
+		if pat.IsToken(tokenString) {
+			principal, err := m.pats.Verify(c.Request().Context(), tokenString)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired token")
+			}
+
+			c.Set("userID", principal.UserID)
+			c.Set("tokenPrincipal", principal)
+			c.Set("accessToken", tokenString)
+			return next(c)
 		}
 
-		tokenString := tokenParts[1]
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			switch token.Method.(type) {
+			case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+			default:
 				return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid token signing method")
 			}
-			return []byte(m.jwtSecret), nil
+			kid, _ := token.Header["kid"].(string)
+			return m.keys.Key(c.Request().Context(), kid)
 		})
 
 		if err != nil || !token.Valid {
@@ -50,13 +81,63 @@ func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token claims")
 		}
 
+		// Tell end users from internal services
+		principal, err := authz.NewPrincipal(claims, m.audience, c.Request())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+
 		// Set user ID in context
-		userID, ok := claims["sub"].(string)
-		if !ok {
+		userID := principal.UserID()
+		if userID == "" {
 			return echo.NewHTTPError(http.StatusUnauthorized, "invalid user ID in token")
 		}
 
+		// Refuse tokens the auth service no longer considers active
+		if m.introspection != nil {
+			_, err := m.introspection.Introspect(c.Request().Context(), tokenString)
+			switch {
+			case errors.Is(err, introspection.ErrInactiveToken):
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired token")
+			case err != nil:
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "token introspection unavailable")
+			}
+		}
+
 		c.Set("userID", userID)
+		c.Set(authz.PrincipalKey, principal)
+		c.Set("accessToken", tokenString)
+
+		// Tag requests made by an admin acting as the user
+		if actor := authz.Actor(claims); actor != "" {
+			c.Set("impersonatorID", actor)
+			authz.LogImpersonatedRequest(c.Request(), userID, actor)
+		}
+
+		return next(c)
+	}
+}
+
+// RejectImpersonation refuses requests made with an impersonation token, so an admin acting as a user
+// cannot take actions reserved for the user themselves
+func (m *AuthMiddleware) RejectImpersonation(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := c.Get("impersonatorID").(string); ok {
+			return echo.NewHTTPError(http.StatusForbidden, "not allowed while impersonating a user")
+		}
 		return next(c)
 	}
 }
+
+// RequireScope rejects personal access tokens that were not granted the scope.
+// Session tokens act with the user's full permissions.
+func (m *AuthMiddleware) RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if principal, ok := c.Get("tokenPrincipal").(*pat.Principal); ok && !principal.HasScope(scope) {
+				return echo.NewHTTPError(http.StatusForbidden, "token is missing the "+scope+" scope")
+			}
+			return next(c)
+		}
+	}
+}
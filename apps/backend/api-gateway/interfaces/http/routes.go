@@ -1,21 +1,47 @@
 package http
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/vcd-simple-blog/apps/backend/api-gateway/config"
+	"github.com/vcd-simple-blog/apps/backend/api-gateway/infrastructure/exportsource"
 	"github.com/vcd-simple-blog/apps/backend/api-gateway/interfaces/http/handlers"
 	"github.com/vcd-simple-blog/apps/backend/api-gateway/interfaces/http/middleware"
+	"github.com/vcd-simple-blog/apps/backend/api-gateway/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/clientcredentials"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/introspection"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
 )
 
 // RegisterRoutes registers all API routes
 func RegisterRoutes(e *echo.Echo, cfg *config.Config) {
 	// Create middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret)
+	cookies := cookieauth.LoadConfig()
+	var introspectionClient *introspection.Client
+	if cfg.Introspection.Enabled {
+		introspectionClient = introspection.NewClient(cfg.Introspection.URL, cfg.Introspection.ClientID, cfg.Introspection.ClientSecret, cfg.Introspection.CacheTTL)
+	}
+	authMiddleware := middleware.NewAuthMiddleware(
+		jwks.NewClient(cfg.JWKSURL, 5*time.Minute),
+		pat.NewClient(cfg.PATVerifyURL, 30*time.Second),
+		introspectionClient,
+		cookies,
+		cfg.ServiceClient.Audience,
+	)
 
 	// Create handlers
-	authHandler := handlers.NewAuthHandler(cfg.AuthServiceURL)
-	blogHandler := handlers.NewBlogHandler(cfg.BlogServiceURL)
-	userHandler := handlers.NewUserHandler(cfg.UserServiceURL)
+	authHandler := handlers.NewAuthHandler(cfg.AuthServiceURL, cookies.CSRFHeader)
+	blogHandler := handlers.NewBlogHandler(cfg.BlogServiceURL, serviceClient(cfg.ServiceClient, cfg.ServiceClient.BlogAudience, 10*time.Second))
+	userHandler := handlers.NewUserHandler(cfg.UserServiceURL, serviceClient(cfg.ServiceClient, cfg.ServiceClient.UserAudience, 10*time.Second))
+	exportHandler := handlers.NewDataExportHandler(usecases.NewDataExportUseCase([]usecases.ExportSource{
+		exportsource.NewHTTPSource("account", cfg.AuthServiceURL, serviceClient(cfg.ServiceClient, cfg.ServiceClient.AuthAudience, cfg.DataExport.Timeout)),
+		exportsource.NewHTTPSource("profile", cfg.UserServiceURL, serviceClient(cfg.ServiceClient, cfg.ServiceClient.UserAudience, cfg.DataExport.Timeout)),
+		exportsource.NewHTTPSource("blogs", cfg.BlogServiceURL, serviceClient(cfg.ServiceClient, cfg.ServiceClient.BlogAudience, cfg.DataExport.Timeout)),
+	}, cfg.DataExport))
 
 	// API v1 group
 	v1 := e.Group("/api/v1")
@@ -30,19 +56,39 @@ func RegisterRoutes(e *echo.Echo, cfg *config.Config) {
 	// Blog routes
 	blog := v1.Group("/blogs")
 	blog.GET("", blogHandler.GetAllBlogs)
+	blog.GET("/by-slug/:slug", blogHandler.GetBlogBySlug)
 	blog.GET("/:id", blogHandler.GetBlogByID)
-	blog.POST("", blogHandler.CreateBlog, authMiddleware.Authenticate)
-	blog.PUT("/:id", blogHandler.UpdateBlog, authMiddleware.Authenticate)
-	blog.DELETE("/:id", blogHandler.DeleteBlog, authMiddleware.Authenticate)
+	blogsWrite := authMiddleware.RequireScope("blogs:write")
+	blog.POST("", blogHandler.CreateBlog, authMiddleware.Authenticate, blogsWrite)
+	blog.PUT("/:id", blogHandler.UpdateBlog, authMiddleware.Authenticate, blogsWrite)
+	blog.DELETE("/:id", blogHandler.DeleteBlog, authMiddleware.Authenticate, blogsWrite)
 
 	// User routes
 	user := v1.Group("/users", authMiddleware.Authenticate)
-	user.GET("/me", userHandler.GetCurrentUser)
-	user.PUT("/me", userHandler.UpdateCurrentUser)
-	user.GET("/:id", userHandler.GetUserByID)
+	user.GET("/me", userHandler.GetCurrentUser, authMiddleware.RequireScope("users:read"))
+	user.PUT("/me", userHandler.UpdateCurrentUser, authMiddleware.RequireScope("users:write"))
+	user.GET("/:id", userHandler.GetUserByID, authMiddleware.RequireScope("users:read"))
+
+	// Personal data export routes; the download link is signed, so it works without a session
+	me := v1.Group("/me")
+	usersExport := authMiddleware.RequireScope("users:export")
+	me.POST("/export", exportHandler.StartExport, authMiddleware.Authenticate, authMiddleware.RejectImpersonation, usersExport)
+	me.GET("/export/:id", exportHandler.GetExport, authMiddleware.Authenticate, authMiddleware.RejectImpersonation, usersExport)
+	me.GET("/export/:id/download", exportHandler.Download)
 
 	// Health check
 	e.GET("/health", func(c echo.Context) error {
 		return c.JSON(200, map[string]string{"status": "ok"})
 	})
 }
+
+// serviceClient creates an HTTP client that authenticates to a service with a client-credentials token
+func serviceClient(cfg config.ServiceClientConfig, audience string, timeout time.Duration) *http.Client {
+	source := clientcredentials.NewTokenSource(clientcredentials.Config{
+		TokenURL:     cfg.TokenURL,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Audience:     audience,
+	})
+	return clientcredentials.NewHTTPClient(source, timeout)
+}
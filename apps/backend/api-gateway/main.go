@@ -20,6 +20,9 @@ func main() {
 	// Create Echo instance
 	e := echo.New()
 
+	// The gateway faces clients directly, so their X-Forwarded-For headers are not believed
+	e.IPExtractor = echo.ExtractIPDirect()
+
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
@@ -0,0 +1,303 @@
+package usecases
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/api-gateway/config"
+	"github.com/vcd-simple-blog/packages/go/common/dataexport"
+)
+
+// ErrExportNotFound is returned for unknown exports and exports of other users
+var ErrExportNotFound = errors.New("export not found")
+
+// ErrInvalidDownloadLink is returned for download links that are forged, expired or for an unfinished export
+var ErrInvalidDownloadLink = errors.New("download link is invalid or has expired")
+
+// ExportRateLimitedError is returned when a user asks for another export too soon after one completed
+type ExportRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *ExportRateLimitedError) Error() string {
+	return "your data was exported recently, please try again later"
+}
+
+// ExportStatus is the state of a personal data export
+type ExportStatus string
+
+const (
+	// ExportProcessing means the export is being gathered from the services
+	ExportProcessing ExportStatus = "processing"
+
+	// ExportReady means the archive can be downloaded
+	ExportReady ExportStatus = "ready"
+
+	// ExportFailed means a service could not export its share of the data
+	ExportFailed ExportStatus = "failed"
+)
+
+// ExportSource is a service that holds part of a user's data
+type ExportSource interface {
+	Name() string
+	Export(ctx context.Context, userID string) (*dataexport.Bundle, error)
+}
+
+// ExportJob is a personal data export of a user
+type ExportJob struct {
+	ID        string
+	UserID    string
+	Status    ExportStatus
+	Error     string
+	CreatedAt time.Time
+	ExpiresAt time.Time // when a ready export's archive and download link expire
+}
+
+// DataExportUseCase builds personal data exports: it gathers a user's data from every service in the background
+// and packages it as a ZIP archive, which is offered through a signed, time-limited download link.
+// Jobs are kept in memory, so exports in progress are lost when the gateway restarts.
+// A user can only export again once the minimum interval has passed since their last export completed.
+type DataExportUseCase struct {
+	sources []ExportSource
+	config  config.DataExportConfig
+
+	mu        sync.Mutex
+	jobs      map[string]*ExportJob
+	completed map[string]time.Time // when each user's last export became ready
+}
+
+// NewDataExportUseCase creates a new data export use case
+func NewDataExportUseCase(sources []ExportSource, config config.DataExportConfig) *DataExportUseCase {
+	return &DataExportUseCase{
+		sources:   sources,
+		config:    config,
+		jobs:      make(map[string]*ExportJob),
+		completed: make(map[string]time.Time),
+	}
+}
+
+// StartExport starts exporting a user's data. A user has at most one export in progress; asking again returns it.
+// Asking for a new export before the minimum interval has passed returns an ExportRateLimitedError.
+func (uc *DataExportUseCase) StartExport(userID string) (*ExportJob, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pruneExpired()
+
+	for _, job := range uc.jobs {
+		if job.UserID == userID && job.Status == ExportProcessing {
+			copied := *job
+			return &copied, nil
+		}
+	}
+
+	// Limit how often a user's data is gathered
+	if completedAt, ok := uc.completed[userID]; ok {
+		if wait := time.Until(completedAt.Add(uc.config.MinInterval)); wait > 0 {
+			return nil, &ExportRateLimitedError{RetryAfter: wait}
+		}
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	job := &ExportJob{
+		ID:        id,
+		UserID:    userID,
+		Status:    ExportProcessing,
+		CreatedAt: time.Now(),
+	}
+	uc.jobs[id] = job
+
+	go uc.run(id, userID)
+
+	copied := *job
+	return &copied, nil
+}
+
+// GetExport returns one of a user's exports
+func (uc *DataExportUseCase) GetExport(userID, id string) (*ExportJob, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pruneExpired()
+
+	job, ok := uc.jobs[id]
+	if !ok || job.UserID != userID {
+		return nil, ErrExportNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// DownloadQuery returns the signed query string of a ready export's download link
+func (uc *DataExportUseCase) DownloadQuery(job *ExportJob) string {
+	expires := strconv.FormatInt(job.ExpiresAt.Unix(), 10)
+	return "expires=" + expires + "&signature=" + uc.sign(job.ID, expires)
+}
+
+// OpenDownload checks a download link and returns the path of the export's archive
+func (uc *DataExportUseCase) OpenDownload(id, expires, signature string) (string, error) {
+	if !hmac.Equal([]byte(signature), []byte(uc.sign(id, expires))) {
+		return "", ErrInvalidDownloadLink
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return "", ErrInvalidDownloadLink
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pruneExpired()
+
+	job, ok := uc.jobs[id]
+	if !ok || job.Status != ExportReady {
+		return "", ErrInvalidDownloadLink
+	}
+	return uc.archivePath(id), nil
+}
+
+// run gathers a user's data and records the outcome on the job
+func (uc *DataExportUseCase) run(id, userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), uc.config.Timeout)
+	defer cancel()
+
+	err := uc.writeArchive(ctx, id, userID)
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	job, ok := uc.jobs[id]
+	if !ok {
+		return
+	}
+	if err != nil {
+		log.Printf("failed to export data of user %s: %v", userID, err)
+		os.Remove(uc.archivePath(id))
+		job.Status = ExportFailed
+		job.Error = "failed to gather your data, please try again later"
+		job.ExpiresAt = time.Now().Add(uc.config.LinkTTL)
+		return
+	}
+	job.Status = ExportReady
+	job.ExpiresAt = time.Now().Add(uc.config.LinkTTL)
+	uc.completed[userID] = time.Now()
+}
+
+// writeArchive writes a ZIP archive with a directory per service holding the files it exported
+func (uc *DataExportUseCase) writeArchive(ctx context.Context, id, userID string) error {
+	// Gather the data first, so nothing is written when a service fails
+	bundles := make([]*dataexport.Bundle, len(uc.sources))
+	for i, source := range uc.sources {
+		bundle, err := source.Export(ctx, userID)
+		if err != nil {
+			return err
+		}
+		bundles[i] = bundle
+	}
+
+	if err := os.MkdirAll(uc.config.Dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(uc.archivePath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	if err := writeArchiveFile(archive, "README.md", uc.readme(userID)); err != nil {
+		return err
+	}
+	for i, bundle := range bundles {
+		for _, f := range bundle.Files {
+			// Keep every file inside its service's directory
+			name := path.Clean("/" + f.Path)
+			if name == "/" {
+				return fmt.Errorf("%s exported a file without a name", uc.sources[i].Name())
+			}
+			if err := writeArchiveFile(archive, uc.sources[i].Name()+name, f.Content); err != nil {
+				return err
+			}
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// readme describes the contents of an archive
+func (uc *DataExportUseCase) readme(userID string) string {
+	var b strings.Builder
+	b.WriteString("# Your personal data\n\n")
+	fmt.Fprintf(&b, "This archive holds the data we store about user %s, exported on %s.\n\n", userID, time.Now().UTC().Format(time.RFC3339))
+	b.WriteString("Each directory holds the data of one of our services:\n\n")
+	for _, source := range uc.sources {
+		fmt.Fprintf(&b, "- `%s/`\n", source.Name())
+	}
+	return b.String()
+}
+
+// pruneExpired forgets expired exports and deletes their archives, and forgets completions
+// that no longer limit a new export. The caller must hold the lock.
+func (uc *DataExportUseCase) pruneExpired() {
+	now := time.Now()
+	for id, job := range uc.jobs {
+		if job.Status != ExportProcessing && now.After(job.ExpiresAt) {
+			if err := os.Remove(uc.archivePath(id)); err != nil && !os.IsNotExist(err) {
+				log.Printf("failed to delete data export %s: %v", id, err)
+			}
+			delete(uc.jobs, id)
+		}
+	}
+	for userID, completedAt := range uc.completed {
+		if now.Sub(completedAt) >= uc.config.MinInterval {
+			delete(uc.completed, userID)
+		}
+	}
+}
+
+// archivePath returns where an export's archive is kept
+func (uc *DataExportUseCase) archivePath(id string) string {
+	return filepath.Join(uc.config.Dir, id+".zip")
+}
+
+// sign returns the signature of a download link
+func (uc *DataExportUseCase) sign(id, expires string) string {
+	mac := hmac.New(sha256.New, []byte(uc.config.SigningSecret))
+	mac.Write([]byte(id + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeArchiveFile adds a file to an archive
+func writeArchiveFile(archive *zip.Writer, name, content string) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(content))
+	return err
+}
+
+// randomID returns a random export ID
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,77 @@
+// Command build-breached-passwords builds the breached password filter loaded through
+// PASSWORD_BREACHED_FILE from a newline-separated list of plaintext passwords.
+//
+//	go run ./cmd/build-breached-passwords -in passwords.txt -out breached-passwords.bf -fp-rate 0.001
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/password"
+)
+
+func main() {
+	in := flag.String("in", "", "newline-separated password list")
+	out := flag.String("out", "breached-passwords.bf", "filter file to write")
+	fpRate := flag.Float64("fp-rate", 0.001, "false positive rate")
+	flag.Parse()
+
+	if *in == "" {
+		log.Fatal("-in is required")
+	}
+
+	// Count passwords to size the filter
+	count, err := forEachPassword(*in, func(string) {})
+	if err != nil {
+		log.Fatalf("Failed to read password list: %v", err)
+	}
+
+	filter, err := password.NewBloomFilter(count, *fpRate)
+	if err != nil {
+		log.Fatalf("Failed to create filter: %v", err)
+	}
+	if _, err := forEachPassword(*in, filter.Add); err != nil {
+		log.Fatalf("Failed to read password list: %v", err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Failed to create filter file: %v", err)
+	}
+	w := bufio.NewWriter(f)
+	size, err := filter.WriteTo(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Failed to write filter file: %v", err)
+	}
+
+	fmt.Printf("Wrote %d passwords to %s (%d bytes)\n", count, *out, size)
+}
+
+// forEachPassword calls fn for each non-empty line of a file and returns the number of lines
+func forEachPassword(path string, fn func(string)) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var count uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			fn(line)
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
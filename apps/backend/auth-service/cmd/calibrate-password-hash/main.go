@@ -0,0 +1,33 @@
+// Command calibrate-password-hash measures password hashing on this machine and prints
+// the PASSWORD_* settings that make one hash take about the target duration.
+//
+// Run it on hardware like production's:
+//
+//	go run ./cmd/calibrate-password-hash -target 250ms -memory 65536 -threads 4
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/password"
+)
+
+func main() {
+	target := flag.Duration("target", 250*time.Millisecond, "target duration of one password hash")
+	memory := flag.Uint("memory", 64*1024, "argon2id memory in KiB")
+	threads := flag.Uint("threads", 4, "argon2id parallelism")
+	flag.Parse()
+
+	params, argon2Elapsed := password.CalibrateArgon2id(*target, uint32(*memory), uint8(*threads))
+	bcryptCost, bcryptElapsed := password.CalibrateBcrypt(*target)
+
+	fmt.Printf("# argon2id: %s per hash\n", argon2Elapsed.Round(time.Millisecond))
+	fmt.Println("PASSWORD_HASH_ALG=argon2id")
+	fmt.Printf("PASSWORD_ARGON2_MEMORY=%d\n", params.Memory)
+	fmt.Printf("PASSWORD_ARGON2_TIME=%d\n", params.Time)
+	fmt.Printf("PASSWORD_ARGON2_THREADS=%d\n", params.Threads)
+	fmt.Printf("# bcrypt: %s per hash\n", bcryptElapsed.Round(time.Millisecond))
+	fmt.Printf("PASSWORD_BCRYPT_COST=%d\n", bcryptCost)
+}
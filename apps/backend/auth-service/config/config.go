@@ -1,16 +1,45 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the Auth Service
 type Config struct {
-	Environment string
-	Database    DatabaseConfig
-	JWT         JWTConfig
+	Environment   string
+	Database      DatabaseConfig
+	JWT           JWTConfig
+	SMTP          SMTPConfig
+	PasswordReset PasswordResetConfig
+	Verification  EmailVerificationConfig
+	MFA           MFAConfig
+	Lockout       LockoutConfig
+	Cookies       SessionCookieConfig
+	OIDC          OIDCConfig
+	SocialLogin   SocialLoginConfig
+	MagicLink     MagicLinkConfig
+	EmailChange   EmailChangeConfig
+	PasswordHash  PasswordHashConfig
+	Password      PasswordPolicyConfig
+	Impersonation ImpersonationConfig
+	Outbox        OutboxConfig
+	Deletion      AccountDeletionConfig
+	ClientIP      ClientIPConfig
+}
+
+// ClientIPConfig holds configuration for telling the address of the client behind proxies
+type ClientIPConfig struct {
+	// TrustedProxies are the address ranges of proxies, such as the API gateway, whose X-Forwarded-For
+	// header is believed. Without any, the connecting address is taken as the client's.
+	TrustedProxies []*net.IPNet
 }
 
 // DatabaseConfig holds database configuration
@@ -25,12 +54,171 @@ type DatabaseConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret           string
-	AccessTokenTTL   time.Duration
-	RefreshTokenTTL  time.Duration
-	Issuer           string
-	AccessTokenCookie  string
-	RefreshTokenCookie string
+	Secret              string
+	AccessTokenTTL      time.Duration
+	RefreshTokenTTL     time.Duration
+	Issuer              string
+	Audiences           []string // the services that accept first-party access tokens
+	ClientAudience      string   // the userinfo endpoint, the only audience of access tokens issued to OAuth clients
+	AccessTokenCookie   string
+	RefreshTokenCookie  string
+	SigningAlgorithm    string
+	KeyRotationInterval time.Duration
+}
+
+// SMTPConfig holds SMTP mailer configuration
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// PasswordHashConfig holds password hashing configuration
+type PasswordHashConfig struct {
+	Algorithm     string // "argon2id" or "bcrypt"
+	BcryptCost    int
+	Argon2Memory  uint32 // KiB
+	Argon2Time    uint32
+	Argon2Threads uint8
+}
+
+// PasswordPolicyConfig holds the policy new passwords must meet
+type PasswordPolicyConfig struct {
+	MinLength              int
+	MaxLength              int
+	MinCharacterClasses    int // of lowercase, uppercase, digits and symbols
+	RejectSimilarToAccount bool
+	Denylist               []string
+	BreachedPasswordsFile  string // optional bloom filter of breached passwords
+}
+
+// PasswordResetConfig holds password reset configuration
+type PasswordResetConfig struct {
+	URL      string
+	TokenTTL time.Duration
+}
+
+// EmailChangeConfig holds configuration for users changing their email address.
+// The confirmation link goes to the new address; the revert link goes to the old one and outlives it.
+type EmailChangeConfig struct {
+	ConfirmURL string
+	RevertURL  string
+	TokenTTL   time.Duration
+	RevertTTL  time.Duration
+}
+
+// ImpersonationConfig holds admin impersonation configuration
+type ImpersonationConfig struct {
+	TokenTTL time.Duration
+}
+
+// AccountDeletionConfig holds configuration for users deleting their own accounts
+type AccountDeletionConfig struct {
+	GracePeriod         time.Duration // during which the user can cancel
+	Mode                string        // "delete" or "anonymize" the auth user
+	PollInterval        time.Duration
+	MaxAttempts         int // of a step before the deletion is rolled back
+	RetryBaseDelay      time.Duration
+	RetryMaxDelay       time.Duration
+	Lease               time.Duration // how long a claimed deletion is held before another worker may retry its step
+	BlogServiceURL      string        // base URL of the blog service's erasure endpoints
+	BlogServiceAudience string
+	UserServiceURL      string // base URL of the user service's erasure endpoints
+	UserServiceAudience string
+}
+
+// OutboxConfig holds configuration for relaying account events to other services
+type OutboxConfig struct {
+	ConsumerURL      string
+	ConsumerAudience string
+	PollInterval     time.Duration
+	BatchSize        int
+	MaxAttempts      int // before a message is dead-lettered
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	Retention        time.Duration // of delivered messages
+	Lease            time.Duration // how long a claimed batch is held before another relay may retry it
+}
+
+// MagicLinkConfig holds passwordless magic link login configuration
+type MagicLinkConfig struct {
+	URL      string
+	TokenTTL time.Duration
+}
+
+// EmailVerificationConfig holds email verification configuration
+type EmailVerificationConfig struct {
+	URL             string
+	Secret          string
+	TokenTTL        time.Duration
+	ResendInterval  time.Duration
+	RequireVerified bool
+}
+
+// MFAConfig holds two-factor authentication configuration
+type MFAConfig struct {
+	Issuer          string
+	ChallengeSecret string
+	ChallengeTTL    time.Duration
+	MaxAttempts     int // wrong codes allowed per challenge before it is rejected
+}
+
+// LockoutConfig holds failed login tracking and lockout configuration
+type LockoutConfig struct {
+	Store            string // "postgres" or "memory"
+	AccountThreshold int
+	IPThreshold      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	Window           time.Duration
+}
+
+// SessionCookieConfig holds cookie session mode configuration.
+// Token cookie names are configured in JWTConfig.
+type SessionCookieConfig struct {
+	Enabled    bool
+	Domain     string
+	Secure     bool
+	SameSite   string // "lax", "strict" or "none"
+	CSRFCookie string
+	CSRFHeader string
+}
+
+// OIDCConfig holds OpenID Connect provider configuration
+type OIDCConfig struct {
+	IssuerURL            string
+	LoginURL             string
+	CodeTTL              time.Duration
+	IDTokenTTL           time.Duration
+	ClientCredentialsTTL time.Duration
+	ServiceAudience      string
+	GatewayClient        ServiceClientConfig
+}
+
+// ServiceClientConfig holds an internal service's client credentials, registered at startup
+// so the service can be deployed with a known client ID and secret
+type ServiceClientConfig struct {
+	ID        string
+	Secret    string
+	Audiences []string // services the client may get client-credentials tokens for
+}
+
+// SocialLoginConfig holds configuration for logging in through external OpenID Connect providers
+type SocialLoginConfig struct {
+	Providers   map[string]IdentityProviderConfig
+	CallbackURL string // the provider name and "/callback" are appended
+	StateSecret string
+	StateTTL    time.Duration
+}
+
+// IdentityProviderConfig holds the registration of auth-service at an external OpenID Connect provider
+type IdentityProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
 }
 
 // LoadConfig loads configuration from environment variables
@@ -92,6 +280,16 @@ func LoadConfig() (*Config, error) {
 		jwtIssuer = "vcd-simple-blog"
 	}
 
+	var accessTokenAudiences []string
+	for _, audience := range strings.Split(os.Getenv("ACCESS_TOKEN_AUDIENCES"), ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			accessTokenAudiences = append(accessTokenAudiences, audience)
+		}
+	}
+	if len(accessTokenAudiences) == 0 {
+		accessTokenAudiences = []string{"api-gateway", "auth-service", "blog-service", "user-service"}
+	}
+
 	accessTokenCookie := os.Getenv("ACCESS_TOKEN_COOKIE")
 	if accessTokenCookie == "" {
 		accessTokenCookie = "access_token"
@@ -102,6 +300,435 @@ func LoadConfig() (*Config, error) {
 		refreshTokenCookie = "refresh_token"
 	}
 
+	signingAlgorithm := os.Getenv("JWT_SIGNING_ALG")
+	if signingAlgorithm == "" {
+		signingAlgorithm = "RS256"
+	}
+
+	keyRotationInterval, err := strconv.Atoi(os.Getenv("JWT_KEY_ROTATION_INTERVAL"))
+	if err != nil || keyRotationInterval == 0 {
+		keyRotationInterval = 30 * 24 * 60 // 30 days
+	}
+
+	// SMTP config
+	smtpHost := os.Getenv("SMTP_HOST")
+	if smtpHost == "" {
+		smtpHost = "localhost"
+	}
+
+	smtpPort, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil || smtpPort == 0 {
+		smtpPort = 1025
+	}
+
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = "no-reply@vcd-simple-blog.local"
+	}
+
+	// Password hash config
+	passwordHashAlgorithm := os.Getenv("PASSWORD_HASH_ALG")
+	if passwordHashAlgorithm == "" {
+		passwordHashAlgorithm = "argon2id"
+	}
+
+	bcryptCost, err := strconv.Atoi(os.Getenv("PASSWORD_BCRYPT_COST"))
+	if err != nil || bcryptCost == 0 {
+		bcryptCost = 10
+	}
+
+	argon2Memory, err := strconv.Atoi(os.Getenv("PASSWORD_ARGON2_MEMORY"))
+	if err != nil || argon2Memory <= 0 {
+		argon2Memory = 64 * 1024 // 64 MiB
+	}
+
+	argon2Time, err := strconv.Atoi(os.Getenv("PASSWORD_ARGON2_TIME"))
+	if err != nil || argon2Time <= 0 {
+		argon2Time = 3
+	}
+
+	argon2Threads, err := strconv.Atoi(os.Getenv("PASSWORD_ARGON2_THREADS"))
+	if err != nil || argon2Threads <= 0 || argon2Threads > 255 {
+		argon2Threads = 4
+	}
+
+	// Password policy config
+	passwordMinLength, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err != nil || passwordMinLength <= 0 {
+		passwordMinLength = 8
+	}
+
+	passwordMaxLength, err := strconv.Atoi(os.Getenv("PASSWORD_MAX_LENGTH"))
+	if err != nil || passwordMaxLength <= 0 {
+		passwordMaxLength = 128
+	}
+
+	passwordMinClasses, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_CHARACTER_CLASSES"))
+	if err != nil || passwordMinClasses < 0 {
+		passwordMinClasses = 1
+	}
+
+	passwordRejectSimilar, err := strconv.ParseBool(os.Getenv("PASSWORD_REJECT_SIMILAR_TO_ACCOUNT"))
+	if err != nil {
+		passwordRejectSimilar = true
+	}
+
+	var passwordDenylist []string
+	for _, word := range strings.Split(os.Getenv("PASSWORD_DENYLIST"), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			passwordDenylist = append(passwordDenylist, word)
+		}
+	}
+
+	// Password reset config
+	passwordResetURL := os.Getenv("PASSWORD_RESET_URL")
+	if passwordResetURL == "" {
+		passwordResetURL = "http://localhost:3000/auth/reset-password"
+	}
+
+	passwordResetTTL, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL"))
+	if err != nil || passwordResetTTL == 0 {
+		passwordResetTTL = 30 // 30 minutes
+	}
+
+	// Magic link config
+	magicLinkURL := os.Getenv("MAGIC_LINK_URL")
+	if magicLinkURL == "" {
+		magicLinkURL = "http://localhost:3000/auth/magic-link"
+	}
+
+	magicLinkTTL, err := strconv.Atoi(os.Getenv("MAGIC_LINK_TTL"))
+	if err != nil || magicLinkTTL == 0 {
+		magicLinkTTL = 15 // 15 minutes
+	}
+
+	// Email change config
+	emailChangeConfirmURL := os.Getenv("EMAIL_CHANGE_CONFIRM_URL")
+	if emailChangeConfirmURL == "" {
+		emailChangeConfirmURL = "http://localhost:3000/auth/confirm-email-change"
+	}
+
+	emailChangeRevertURL := os.Getenv("EMAIL_CHANGE_REVERT_URL")
+	if emailChangeRevertURL == "" {
+		emailChangeRevertURL = "http://localhost:3000/auth/revert-email-change"
+	}
+
+	emailChangeTTL, err := strconv.Atoi(os.Getenv("EMAIL_CHANGE_TTL"))
+	if err != nil || emailChangeTTL == 0 {
+		emailChangeTTL = 24 * 60 // 24 hours
+	}
+
+	emailChangeRevertTTL, err := strconv.Atoi(os.Getenv("EMAIL_CHANGE_REVERT_TTL"))
+	if err != nil || emailChangeRevertTTL == 0 {
+		emailChangeRevertTTL = 7 * 24 * 60 // 7 days
+	}
+
+	impersonationTTL, err := strconv.Atoi(os.Getenv("IMPERSONATION_TTL"))
+	if err != nil || impersonationTTL == 0 {
+		impersonationTTL = 15 // 15 minutes
+	}
+
+	// Outbox config
+	outboxConsumerURL := os.Getenv("OUTBOX_CONSUMER_URL")
+	if outboxConsumerURL == "" {
+		outboxConsumerURL = "http://localhost:8083/api/v1/internal/account-events"
+	}
+
+	outboxConsumerAudience := os.Getenv("OUTBOX_CONSUMER_AUDIENCE")
+	if outboxConsumerAudience == "" {
+		outboxConsumerAudience = "user-service"
+	}
+
+	outboxPollInterval, err := strconv.Atoi(os.Getenv("OUTBOX_POLL_INTERVAL"))
+	if err != nil || outboxPollInterval == 0 {
+		outboxPollInterval = 5 // 5 seconds
+	}
+
+	outboxBatchSize, err := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE"))
+	if err != nil || outboxBatchSize == 0 {
+		outboxBatchSize = 50
+	}
+
+	outboxMaxAttempts, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS"))
+	if err != nil || outboxMaxAttempts == 0 {
+		outboxMaxAttempts = 10
+	}
+
+	outboxRetryBaseDelay, err := strconv.Atoi(os.Getenv("OUTBOX_RETRY_BASE_DELAY"))
+	if err != nil || outboxRetryBaseDelay == 0 {
+		outboxRetryBaseDelay = 5 // 5 seconds
+	}
+
+	outboxRetryMaxDelay, err := strconv.Atoi(os.Getenv("OUTBOX_RETRY_MAX_DELAY"))
+	if err != nil || outboxRetryMaxDelay == 0 {
+		outboxRetryMaxDelay = 60 // 60 minutes
+	}
+
+	outboxRetention, err := strconv.Atoi(os.Getenv("OUTBOX_RETENTION"))
+	if err != nil || outboxRetention == 0 {
+		outboxRetention = 7 * 24 // 7 days
+	}
+
+	outboxLease, err := strconv.Atoi(os.Getenv("OUTBOX_LEASE"))
+	if err != nil || outboxLease == 0 {
+		outboxLease = 10 * 60 // 10 minutes
+	}
+
+	// Account deletion config
+	deletionGracePeriod, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD"))
+	if err != nil || deletionGracePeriod == 0 {
+		deletionGracePeriod = 14 * 24 * 60 // 14 days
+	}
+
+	deletionMode := os.Getenv("ACCOUNT_DELETION_MODE")
+	if deletionMode == "" {
+		deletionMode = "delete"
+	}
+	if deletionMode != "delete" && deletionMode != "anonymize" {
+		return nil, fmt.Errorf("unsupported account deletion mode %q", deletionMode)
+	}
+
+	deletionPollInterval, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_POLL_INTERVAL"))
+	if err != nil || deletionPollInterval == 0 {
+		deletionPollInterval = 30 // 30 seconds
+	}
+
+	deletionMaxAttempts, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_MAX_ATTEMPTS"))
+	if err != nil || deletionMaxAttempts == 0 {
+		deletionMaxAttempts = 10
+	}
+
+	deletionRetryBaseDelay, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_RETRY_BASE_DELAY"))
+	if err != nil || deletionRetryBaseDelay == 0 {
+		deletionRetryBaseDelay = 30 // 30 seconds
+	}
+
+	deletionRetryMaxDelay, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_RETRY_MAX_DELAY"))
+	if err != nil || deletionRetryMaxDelay == 0 {
+		deletionRetryMaxDelay = 60 // 60 minutes
+	}
+
+	deletionLease, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_LEASE"))
+	if err != nil || deletionLease == 0 {
+		deletionLease = 10 * 60 // 10 minutes
+	}
+
+	blogServiceErasureURL := os.Getenv("BLOG_SERVICE_ERASURE_URL")
+	if blogServiceErasureURL == "" {
+		blogServiceErasureURL = "http://localhost:8082/api/v1/internal/erasures"
+	}
+
+	blogServiceAudience := os.Getenv("BLOG_SERVICE_AUDIENCE")
+	if blogServiceAudience == "" {
+		blogServiceAudience = "blog-service"
+	}
+
+	userServiceErasureURL := os.Getenv("USER_SERVICE_ERASURE_URL")
+	if userServiceErasureURL == "" {
+		userServiceErasureURL = "http://localhost:8083/api/v1/internal/erasures"
+	}
+
+	userServiceAudience := os.Getenv("USER_SERVICE_AUDIENCE")
+	if userServiceAudience == "" {
+		userServiceAudience = "user-service"
+	}
+
+	// Email verification config
+	verificationURL := os.Getenv("EMAIL_VERIFICATION_URL")
+	if verificationURL == "" {
+		verificationURL = "http://localhost:8081/api/v1/auth/verify-email"
+	}
+
+	verificationSecret, err := dedicatedSecret("EMAIL_VERIFICATION_SECRET", env)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationTTL, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL"))
+	if err != nil || verificationTTL == 0 {
+		verificationTTL = 24 * 60 // 24 hours
+	}
+
+	verificationResendInterval, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_RESEND_INTERVAL"))
+	if err != nil || verificationResendInterval == 0 {
+		verificationResendInterval = 60 // 60 seconds
+	}
+
+	requireVerified, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+
+	// MFA config
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "VCD Simple Blog"
+	}
+
+	mfaChallengeSecret, err := dedicatedSecret("MFA_CHALLENGE_SECRET", env)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaChallengeTTL, err := strconv.Atoi(os.Getenv("MFA_CHALLENGE_TTL"))
+	if err != nil || mfaChallengeTTL == 0 {
+		mfaChallengeTTL = 5 // 5 minutes
+	}
+
+	mfaMaxAttempts, err := strconv.Atoi(os.Getenv("MFA_MAX_ATTEMPTS"))
+	if err != nil || mfaMaxAttempts == 0 {
+		mfaMaxAttempts = 5
+	}
+
+	// Lockout config
+	lockoutStore := os.Getenv("LOCKOUT_STORE")
+	if lockoutStore == "" {
+		lockoutStore = "postgres"
+	}
+
+	lockoutAccountThreshold, err := strconv.Atoi(os.Getenv("LOCKOUT_ACCOUNT_THRESHOLD"))
+	if err != nil || lockoutAccountThreshold == 0 {
+		lockoutAccountThreshold = 5
+	}
+
+	lockoutIPThreshold, err := strconv.Atoi(os.Getenv("LOCKOUT_IP_THRESHOLD"))
+	if err != nil || lockoutIPThreshold == 0 {
+		lockoutIPThreshold = 20
+	}
+
+	lockoutBaseDelay, err := strconv.Atoi(os.Getenv("LOCKOUT_BASE_DELAY"))
+	if err != nil || lockoutBaseDelay == 0 {
+		lockoutBaseDelay = 30 // 30 seconds
+	}
+
+	lockoutMaxDelay, err := strconv.Atoi(os.Getenv("LOCKOUT_MAX_DELAY"))
+	if err != nil || lockoutMaxDelay == 0 {
+		lockoutMaxDelay = 60 // 60 minutes
+	}
+
+	lockoutWindow, err := strconv.Atoi(os.Getenv("LOCKOUT_WINDOW"))
+	if err != nil || lockoutWindow == 0 {
+		lockoutWindow = 24 * 60 // 24 hours
+	}
+
+	// Session cookie config
+	sessionCookiesEnabled, _ := strconv.ParseBool(os.Getenv("SESSION_COOKIES_ENABLED"))
+
+	cookieSecure, err := strconv.ParseBool(os.Getenv("COOKIE_SECURE"))
+	if err != nil {
+		cookieSecure = true
+	}
+
+	cookieSameSite := os.Getenv("COOKIE_SAMESITE")
+	if cookieSameSite == "" {
+		cookieSameSite = "lax"
+	}
+
+	csrfCookie := os.Getenv("CSRF_COOKIE")
+	if csrfCookie == "" {
+		csrfCookie = "csrf_token"
+	}
+
+	csrfHeader := os.Getenv("CSRF_HEADER")
+	if csrfHeader == "" {
+		csrfHeader = "X-CSRF-Token"
+	}
+
+	// OIDC config
+	oidcIssuerURL := os.Getenv("OIDC_ISSUER_URL")
+	if oidcIssuerURL == "" {
+		oidcIssuerURL = "http://localhost:8081"
+	}
+
+	oidcLoginURL := os.Getenv("OIDC_LOGIN_URL")
+	if oidcLoginURL == "" {
+		oidcLoginURL = "http://localhost:3000/auth/login"
+	}
+
+	oidcCodeTTL, err := strconv.Atoi(os.Getenv("OIDC_CODE_TTL"))
+	if err != nil || oidcCodeTTL == 0 {
+		oidcCodeTTL = 5 // 5 minutes
+	}
+
+	oidcIDTokenTTL, err := strconv.Atoi(os.Getenv("OIDC_ID_TOKEN_TTL"))
+	if err != nil || oidcIDTokenTTL == 0 {
+		oidcIDTokenTTL = 60 // 60 minutes
+	}
+
+	oidcClientCredentialsTTL, err := strconv.Atoi(os.Getenv("OIDC_CLIENT_CREDENTIALS_TTL"))
+	if err != nil || oidcClientCredentialsTTL == 0 {
+		oidcClientCredentialsTTL = 10 // 10 minutes
+	}
+
+	serviceAudience := os.Getenv("SERVICE_AUDIENCE")
+	if serviceAudience == "" {
+		serviceAudience = "auth-service"
+	}
+
+	gatewayClientSecret := os.Getenv("GATEWAY_CLIENT_SECRET")
+	if os.Getenv("GATEWAY_CLIENT_ID") != "" && gatewayClientSecret == "" {
+		return nil, errors.New("GATEWAY_CLIENT_SECRET must be set with GATEWAY_CLIENT_ID")
+	}
+
+	var gatewayClientAudiences []string
+	for _, audience := range strings.Split(os.Getenv("GATEWAY_CLIENT_AUDIENCES"), ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			gatewayClientAudiences = append(gatewayClientAudiences, audience)
+		}
+	}
+	if len(gatewayClientAudiences) == 0 {
+		gatewayClientAudiences = []string{"auth-service", "blog-service", "user-service"}
+	}
+
+	// Social login config
+	socialProviders := make(map[string]IdentityProviderConfig)
+	for _, name := range strings.Split(os.Getenv("SOCIAL_LOGIN_PROVIDERS"), ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "SOCIAL_LOGIN_" + strings.ToUpper(name) + "_"
+		scopes := strings.Fields(os.Getenv(prefix + "SCOPES"))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		socialProviders[name] = IdentityProviderConfig{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       scopes,
+		}
+	}
+
+	socialCallbackURL := os.Getenv("SOCIAL_LOGIN_CALLBACK_URL")
+	if socialCallbackURL == "" {
+		socialCallbackURL = "http://localhost:8081/api/v1/auth/oidc"
+	}
+
+	socialStateSecret := os.Getenv("SOCIAL_LOGIN_STATE_SECRET")
+	if socialStateSecret == "" {
+		socialStateSecret = jwtSecret
+	}
+
+	socialStateTTL, err := strconv.Atoi(os.Getenv("SOCIAL_LOGIN_STATE_TTL"))
+	if err != nil || socialStateTTL == 0 {
+		socialStateTTL = 10 // 10 minutes
+	}
+
+	// Client IP config
+	var trustedProxies []*net.IPNet
+	for _, cidr := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", cidr, err)
+		}
+		trustedProxies = append(trustedProxies, ipRange)
+	}
+
 	return &Config{
 		Environment: env,
 		Database: DatabaseConfig{
@@ -113,12 +740,148 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  dbSSLMode,
 		},
 		JWT: JWTConfig{
-			Secret:             jwtSecret,
-			AccessTokenTTL:     time.Duration(accessTokenTTL) * time.Minute,
-			RefreshTokenTTL:    time.Duration(refreshTokenTTL) * time.Minute,
-			Issuer:             jwtIssuer,
-			AccessTokenCookie:  accessTokenCookie,
-			RefreshTokenCookie: refreshTokenCookie,
+			Secret:              jwtSecret,
+			AccessTokenTTL:      time.Duration(accessTokenTTL) * time.Minute,
+			RefreshTokenTTL:     time.Duration(refreshTokenTTL) * time.Minute,
+			Issuer:              jwtIssuer,
+			Audiences:           accessTokenAudiences,
+			ClientAudience:      strings.TrimSuffix(oidcIssuerURL, "/") + "/oauth/userinfo",
+			AccessTokenCookie:   accessTokenCookie,
+			RefreshTokenCookie:  refreshTokenCookie,
+			SigningAlgorithm:    signingAlgorithm,
+			KeyRotationInterval: time.Duration(keyRotationInterval) * time.Minute,
+		},
+		SMTP: SMTPConfig{
+			Host:     smtpHost,
+			Port:     smtpPort,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     smtpFrom,
+		},
+		PasswordHash: PasswordHashConfig{
+			Algorithm:     passwordHashAlgorithm,
+			BcryptCost:    bcryptCost,
+			Argon2Memory:  uint32(argon2Memory),
+			Argon2Time:    uint32(argon2Time),
+			Argon2Threads: uint8(argon2Threads),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:              passwordMinLength,
+			MaxLength:              passwordMaxLength,
+			MinCharacterClasses:    passwordMinClasses,
+			RejectSimilarToAccount: passwordRejectSimilar,
+			Denylist:               passwordDenylist,
+			BreachedPasswordsFile:  os.Getenv("PASSWORD_BREACHED_FILE"),
+		},
+		PasswordReset: PasswordResetConfig{
+			URL:      passwordResetURL,
+			TokenTTL: time.Duration(passwordResetTTL) * time.Minute,
+		},
+		Verification: EmailVerificationConfig{
+			URL:             verificationURL,
+			Secret:          verificationSecret,
+			TokenTTL:        time.Duration(verificationTTL) * time.Minute,
+			ResendInterval:  time.Duration(verificationResendInterval) * time.Second,
+			RequireVerified: requireVerified,
+		},
+		MFA: MFAConfig{
+			Issuer:          mfaIssuer,
+			ChallengeSecret: mfaChallengeSecret,
+			ChallengeTTL:    time.Duration(mfaChallengeTTL) * time.Minute,
+			MaxAttempts:     mfaMaxAttempts,
+		},
+		Lockout: LockoutConfig{
+			Store:            lockoutStore,
+			AccountThreshold: lockoutAccountThreshold,
+			IPThreshold:      lockoutIPThreshold,
+			BaseDelay:        time.Duration(lockoutBaseDelay) * time.Second,
+			MaxDelay:         time.Duration(lockoutMaxDelay) * time.Minute,
+			Window:           time.Duration(lockoutWindow) * time.Minute,
+		},
+		Cookies: SessionCookieConfig{
+			Enabled:    sessionCookiesEnabled,
+			Domain:     os.Getenv("COOKIE_DOMAIN"),
+			Secure:     cookieSecure,
+			SameSite:   cookieSameSite,
+			CSRFCookie: csrfCookie,
+			CSRFHeader: csrfHeader,
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:            oidcIssuerURL,
+			LoginURL:             oidcLoginURL,
+			CodeTTL:              time.Duration(oidcCodeTTL) * time.Minute,
+			IDTokenTTL:           time.Duration(oidcIDTokenTTL) * time.Minute,
+			ClientCredentialsTTL: time.Duration(oidcClientCredentialsTTL) * time.Minute,
+			ServiceAudience:      serviceAudience,
+			GatewayClient: ServiceClientConfig{
+				ID:        os.Getenv("GATEWAY_CLIENT_ID"),
+				Secret:    gatewayClientSecret,
+				Audiences: gatewayClientAudiences,
+			},
+		},
+		SocialLogin: SocialLoginConfig{
+			Providers:   socialProviders,
+			CallbackURL: socialCallbackURL,
+			StateSecret: socialStateSecret,
+			StateTTL:    time.Duration(socialStateTTL) * time.Minute,
+		},
+		MagicLink: MagicLinkConfig{
+			URL:      magicLinkURL,
+			TokenTTL: time.Duration(magicLinkTTL) * time.Minute,
+		},
+		EmailChange: EmailChangeConfig{
+			ConfirmURL: emailChangeConfirmURL,
+			RevertURL:  emailChangeRevertURL,
+			TokenTTL:   time.Duration(emailChangeTTL) * time.Minute,
+			RevertTTL:  time.Duration(emailChangeRevertTTL) * time.Minute,
+		},
+		Impersonation: ImpersonationConfig{
+			TokenTTL: time.Duration(impersonationTTL) * time.Minute,
+		},
+		Deletion: AccountDeletionConfig{
+			GracePeriod:         time.Duration(deletionGracePeriod) * time.Minute,
+			Mode:                deletionMode,
+			PollInterval:        time.Duration(deletionPollInterval) * time.Second,
+			MaxAttempts:         deletionMaxAttempts,
+			RetryBaseDelay:      time.Duration(deletionRetryBaseDelay) * time.Second,
+			RetryMaxDelay:       time.Duration(deletionRetryMaxDelay) * time.Minute,
+			Lease:               time.Duration(deletionLease) * time.Second,
+			BlogServiceURL:      blogServiceErasureURL,
+			BlogServiceAudience: blogServiceAudience,
+			UserServiceURL:      userServiceErasureURL,
+			UserServiceAudience: userServiceAudience,
+		},
+		Outbox: OutboxConfig{
+			ConsumerURL:      outboxConsumerURL,
+			ConsumerAudience: outboxConsumerAudience,
+			PollInterval:     time.Duration(outboxPollInterval) * time.Second,
+			BatchSize:        outboxBatchSize,
+			MaxAttempts:      outboxMaxAttempts,
+			RetryBaseDelay:   time.Duration(outboxRetryBaseDelay) * time.Second,
+			RetryMaxDelay:    time.Duration(outboxRetryMaxDelay) * time.Minute,
+			Retention:        time.Duration(outboxRetention) * time.Hour,
+			Lease:            time.Duration(outboxLease) * time.Second,
+		},
+		ClientIP: ClientIPConfig{
+			TrustedProxies: trustedProxies,
 		},
 	}, nil
 }
+
+// dedicatedSecret reads a secret that only signs this service's own tokens, so it must not be shared
+// with any other setting. Outside development it is required; in development a random one is generated,
+// which invalidates tokens signed with it whenever the service restarts.
+func dedicatedSecret(name, env string) (string, error) {
+	if secret := os.Getenv(name); secret != "" {
+		return secret, nil
+	}
+	if env != "development" {
+		return "", fmt.Errorf("%s must be set", name)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
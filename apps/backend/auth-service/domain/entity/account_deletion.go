@@ -0,0 +1,144 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// AccountDeletionStatus is the state of an account deletion
+type AccountDeletionStatus string
+
+const (
+	// AccountDeletionScheduled is waiting out the grace period, during which the user can cancel
+	AccountDeletionScheduled AccountDeletionStatus = "scheduled"
+
+	// AccountDeletionCancelled was cancelled by the user during the grace period
+	AccountDeletionCancelled AccountDeletionStatus = "cancelled"
+
+	// AccountDeletionRunning is executing its steps
+	AccountDeletionRunning AccountDeletionStatus = "running"
+
+	// AccountDeletionCompensating is undoing its completed steps after a step kept failing
+	AccountDeletionCompensating AccountDeletionStatus = "compensating"
+
+	// AccountDeletionCompleted has erased the account everywhere
+	AccountDeletionCompleted AccountDeletionStatus = "completed"
+
+	// AccountDeletionRolledBack has undone its steps, leaving the account as it was
+	AccountDeletionRolledBack AccountDeletionStatus = "rolled_back"
+)
+
+// AccountDeletion tracks the saga that deletes a user's account across services.
+// Step is the index of the next step to run, or while compensating, the next step to undo.
+// Progress is saved after every step so the saga resumes where it stopped after a crash.
+type AccountDeletion struct {
+	ID            string                `gorm:"primaryKey"`
+	UserID        string                `gorm:"index"`
+	Status        AccountDeletionStatus `gorm:"index"`
+	Step          int
+	WasDisabled   bool // the account was disabled before the deletion locked it
+	Attempts      int
+	LastError     string
+	ScheduledFor  time.Time
+	NextAttemptAt time.Time `gorm:"index"`
+	CompletedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// NewAccountDeletion creates a deletion of a user's account that starts when the grace period ends
+func NewAccountDeletion(id, userID string, gracePeriod time.Duration) (*AccountDeletion, error) {
+	if id == "" {
+		return nil, errors.New("deletion ID cannot be empty")
+	}
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	now := time.Now()
+	return &AccountDeletion{
+		ID:            id,
+		UserID:        userID,
+		Status:        AccountDeletionScheduled,
+		ScheduledFor:  now.Add(gracePeriod),
+		NextAttemptAt: now.Add(gracePeriod),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// IsActive checks if the deletion is scheduled or in progress
+func (d *AccountDeletion) IsActive() bool {
+	switch d.Status {
+	case AccountDeletionScheduled, AccountDeletionRunning, AccountDeletionCompensating:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cancel stops a deletion that is still in its grace period
+func (d *AccountDeletion) Cancel() error {
+	if d.Status != AccountDeletionScheduled {
+		return errors.New("account deletion can only be cancelled during the grace period")
+	}
+
+	d.Status = AccountDeletionCancelled
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// Lease holds the deletion for a worker until the given time, ending the grace period if it has not ended yet
+func (d *AccountDeletion) Lease(until time.Time) {
+	if d.Status == AccountDeletionScheduled {
+		d.Status = AccountDeletionRunning
+	}
+	d.NextAttemptAt = until
+}
+
+// StepSucceeded moves to the next step, finishing the saga after the last step or the last compensation
+func (d *AccountDeletion) StepSucceeded(steps int) {
+	now := time.Now()
+	d.Attempts = 0
+	d.LastError = ""
+	d.NextAttemptAt = now
+	d.UpdatedAt = now
+
+	switch d.Status {
+	case AccountDeletionScheduled, AccountDeletionRunning:
+		d.Status = AccountDeletionRunning
+		d.Step++
+		if d.Step >= steps {
+			d.Status = AccountDeletionCompleted
+			d.CompletedAt = &now
+		}
+	case AccountDeletionCompensating:
+		d.Step--
+		if d.Step < 0 {
+			d.Status = AccountDeletionRolledBack
+			d.CompletedAt = &now
+		}
+	}
+}
+
+// StepFailed counts a failed attempt at the current step and schedules a retry after backoff
+func (d *AccountDeletion) StepFailed(err error, backoff time.Duration) {
+	now := time.Now()
+	if d.Status == AccountDeletionScheduled {
+		d.Status = AccountDeletionRunning
+	}
+	d.Attempts++
+	d.LastError = err.Error()
+	d.NextAttemptAt = now.Add(backoff)
+	d.UpdatedAt = now
+}
+
+// Compensate gives up on the current step and starts undoing it and the steps before it.
+// The failed step is undone too, as it may have taken effect without reporting success.
+func (d *AccountDeletion) Compensate() {
+	now := time.Now()
+	d.Status = AccountDeletionCompensating
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	d.UpdatedAt = now
+}
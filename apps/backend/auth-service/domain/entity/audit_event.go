@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// AuditEvent is an append-only record of a security event.
+// Details are stored as a JSON object.
+type AuditEvent struct {
+	ID         string `gorm:"primaryKey"`
+	Type       string `gorm:"index"`
+	UserID     string `gorm:"index"`
+	ActorID    string `gorm:"index"`
+	IPAddress  string
+	UserAgent  string
+	Outcome    string
+	Reason     string
+	Details    string    `gorm:"type:text"`
+	OccurredAt time.Time `gorm:"index"`
+}
+
+// NewAuditEvent creates a new audit event entity
+func NewAuditEvent(id, eventType, userID, actorID, ipAddress, userAgent, outcome, reason string, details map[string]string, occurredAt time.Time) (*AuditEvent, error) {
+	if id == "" {
+		return nil, errors.New("audit event ID cannot be empty")
+	}
+	if eventType == "" {
+		return nil, errors.New("audit event type cannot be empty")
+	}
+
+	encodedDetails := ""
+	if len(details) > 0 {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return nil, err
+		}
+		encodedDetails = string(encoded)
+	}
+
+	return &AuditEvent{
+		ID:         id,
+		Type:       eventType,
+		UserID:     userID,
+		ActorID:    actorID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Outcome:    outcome,
+		Reason:     reason,
+		Details:    encodedDetails,
+		OccurredAt: occurredAt,
+	}, nil
+}
+
+// DetailMap decodes the event's details
+func (e *AuditEvent) DetailMap() map[string]string {
+	details := map[string]string{}
+	if e.Details != "" {
+		_ = json.Unmarshal([]byte(e.Details), &details)
+	}
+	return details
+}
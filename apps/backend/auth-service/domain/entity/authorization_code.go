@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// AuthorizationCode represents a single-use OAuth 2.0 authorization code bound to a PKCE challenge.
+// Only the SHA-256 hash of the code is stored.
+type AuthorizationCode struct {
+	ID            string
+	CodeHash      string `gorm:"uniqueIndex"`
+	ClientID      string
+	UserID        string
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// NewAuthorizationCode creates a new authorization code entity
+func NewAuthorizationCode(id, codeHash, clientID, userID, redirectURI, scope, nonce, codeChallenge string, expiresAt time.Time) (*AuthorizationCode, error) {
+	if codeHash == "" {
+		return nil, errors.New("code hash cannot be empty")
+	}
+
+	if clientID == "" {
+		return nil, errors.New("client ID cannot be empty")
+	}
+
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	if codeChallenge == "" {
+		return nil, errors.New("code challenge cannot be empty")
+	}
+
+	if expiresAt.Before(time.Now()) {
+		return nil, errors.New("expiration time must be in the future")
+	}
+
+	now := time.Now()
+	return &AuthorizationCode{
+		ID:            id,
+		CodeHash:      codeHash,
+		ClientID:      clientID,
+		UserID:        userID,
+		RedirectURI:   redirectURI,
+		Scope:         scope,
+		Nonce:         nonce,
+		CodeChallenge: codeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}, nil
+}
+
+// IsExpired checks if the code is expired
+func (c *AuthorizationCode) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// IsUsed checks if the code has already been exchanged
+func (c *AuthorizationCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+// MarkUsed marks the code as exchanged
+func (c *AuthorizationCode) MarkUsed() error {
+	if c.IsUsed() {
+		return errors.New("authorization code has already been used")
+	}
+
+	now := time.Now()
+	c.UsedAt = &now
+	return nil
+}
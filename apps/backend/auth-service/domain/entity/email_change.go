@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// EmailChange represents a user's request to change their email address.
+// The change is applied once confirmed from the new address, and can be reverted from the old one
+// until the revert link expires. Only the SHA-256 hashes of both tokens are stored.
+type EmailChange struct {
+	ID               string
+	UserID           string `gorm:"index"`
+	PreviousEmail    string
+	PreviousVerified bool
+	NewEmail         string
+	ConfirmTokenHash string `gorm:"uniqueIndex"`
+	RevertTokenHash  string `gorm:"uniqueIndex"`
+	ConfirmExpiresAt time.Time
+	RevertExpiresAt  time.Time
+	ConfirmedAt      *time.Time
+	RevertedAt       *time.Time
+	CreatedAt        time.Time
+}
+
+// NewEmailChange creates a new email change entity for a user's current address
+func NewEmailChange(id string, user *User, newEmail, confirmTokenHash, revertTokenHash string, confirmExpiresAt, revertExpiresAt time.Time) (*EmailChange, error) {
+	if newEmail == "" {
+		return nil, errors.New("email cannot be empty")
+	}
+
+	if newEmail == user.Email {
+		return nil, errors.New("email is unchanged")
+	}
+
+	if confirmTokenHash == "" || revertTokenHash == "" {
+		return nil, errors.New("token hash cannot be empty")
+	}
+
+	return &EmailChange{
+		ID:               id,
+		UserID:           user.ID,
+		PreviousEmail:    user.Email,
+		PreviousVerified: user.Verified,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: confirmTokenHash,
+		RevertTokenHash:  revertTokenHash,
+		ConfirmExpiresAt: confirmExpiresAt,
+		RevertExpiresAt:  revertExpiresAt,
+		CreatedAt:        time.Now(),
+	}, nil
+}
+
+// IsConfirmed checks if the new address was confirmed and applied
+func (c *EmailChange) IsConfirmed() bool {
+	return c.ConfirmedAt != nil
+}
+
+// IsReverted checks if the change was cancelled or undone
+func (c *EmailChange) IsReverted() bool {
+	return c.RevertedAt != nil
+}
+
+// CanConfirm checks if the confirmation link can still be used
+func (c *EmailChange) CanConfirm() bool {
+	return !c.IsConfirmed() && !c.IsReverted() && time.Now().Before(c.ConfirmExpiresAt)
+}
+
+// CanRevert checks if the revert link can still be used
+func (c *EmailChange) CanRevert() bool {
+	return !c.IsReverted() && time.Now().Before(c.RevertExpiresAt)
+}
+
+// Confirm marks the change as confirmed
+func (c *EmailChange) Confirm() error {
+	if !c.CanConfirm() {
+		return errors.New("email change can no longer be confirmed")
+	}
+
+	now := time.Now()
+	c.ConfirmedAt = &now
+	return nil
+}
+
+// Revert marks the change as reverted
+func (c *EmailChange) Revert() error {
+	if !c.CanRevert() {
+		return errors.New("email change can no longer be reverted")
+	}
+
+	now := time.Now()
+	c.RevertedAt = &now
+	return nil
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// LinkedIdentity maps an account at an external identity provider to a user
+type LinkedIdentity struct {
+	ID        string
+	UserID    string `gorm:"index"`
+	Provider  string `gorm:"uniqueIndex:idx_linked_identity_subject"`
+	Subject   string `gorm:"uniqueIndex:idx_linked_identity_subject"`
+	Email     string
+	CreatedAt time.Time
+}
+
+// NewLinkedIdentity creates a new linked identity entity
+func NewLinkedIdentity(id, userID, provider, subject, email string) (*LinkedIdentity, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	if provider == "" || subject == "" {
+		return nil, errors.New("provider and subject cannot be empty")
+	}
+
+	return &LinkedIdentity{
+		ID:        id,
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+// LoginAttempt tracks consecutive failed logins for a key, such as an account or an IP address
+type LoginAttempt struct {
+	Key           string `gorm:"primaryKey"`
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	UpdatedAt     time.Time
+}
+
+// NewLoginAttempt creates an empty login attempt record for a key
+func NewLoginAttempt(key string) *LoginAttempt {
+	return &LoginAttempt{
+		Key: key,
+	}
+}
+
+// IsLocked checks if logins for the key are locked at the given time
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// RetryAfter returns how long until logins for the key are unlocked
+func (a *LoginAttempt) RetryAfter(now time.Time) time.Duration {
+	if !a.IsLocked(now) {
+		return 0
+	}
+	return a.LockedUntil.Sub(now)
+}
+
+// IsStale checks if the last failure is older than the window and no lock is in effect
+func (a *LoginAttempt) IsStale(now time.Time, window time.Duration) bool {
+	return !a.IsLocked(now) && now.Sub(a.LastFailureAt) > window
+}
+
+// RegisterFailure counts a failed login and locks the key for lockFor(failures), if positive.
+// Failures older than the window are forgotten first.
+func (a *LoginAttempt) RegisterFailure(now time.Time, window time.Duration, lockFor func(failures int) time.Duration) {
+	if a.IsStale(now, window) {
+		a.Failures = 0
+	}
+
+	a.Failures++
+	a.LastFailureAt = now
+	a.UpdatedAt = now
+
+	if d := lockFor(a.Failures); d > 0 {
+		lockedUntil := now.Add(d)
+		a.LockedUntil = &lockedUntil
+	}
+}
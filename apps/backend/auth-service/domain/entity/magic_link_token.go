@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// MagicLinkToken represents a single-use passwordless sign-in token entity.
+// Only SHA-256 hashes are stored: the raw token is emailed to the user and the raw nonce
+// is kept in a cookie of the browser that requested the link.
+type MagicLinkToken struct {
+	ID        string
+	UserID    string `gorm:"index"`
+	TokenHash string `gorm:"uniqueIndex"`
+	NonceHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewMagicLinkToken creates a new magic link token entity
+func NewMagicLinkToken(id, userID, tokenHash, nonceHash string, expiresAt time.Time) (*MagicLinkToken, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	if tokenHash == "" {
+		return nil, errors.New("token hash cannot be empty")
+	}
+
+	if nonceHash == "" {
+		return nil, errors.New("nonce hash cannot be empty")
+	}
+
+	if expiresAt.Before(time.Now()) {
+		return nil, errors.New("expiration time must be in the future")
+	}
+
+	return &MagicLinkToken{
+		ID:        id,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		NonceHash: nonceHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsExpired checks if the token is expired
+func (t *MagicLinkToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the token has already been used
+func (t *MagicLinkToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// MarkUsed marks the token as used so it cannot be redeemed again
+func (t *MagicLinkToken) MarkUsed() error {
+	if t.IsUsed() {
+		return errors.New("token has already been used")
+	}
+
+	now := time.Now()
+	t.UsedAt = &now
+	return nil
+}
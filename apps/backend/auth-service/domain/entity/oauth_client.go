@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuthClient represents an application registered to log users in through the OpenID Connect provider,
+// or an internal service that authenticates as itself with the client credentials grant.
+// Public clients have no secret; only the SHA-256 hash of a confidential client's secret is stored.
+type OAuthClient struct {
+	ID           string
+	Name         string
+	SecretHash   string
+	RedirectURIs string // space-separated
+	Audiences    string // space-separated; services the client may get client-credentials tokens for
+	Scopes       string // space-separated; scopes the client may get client-credentials tokens with
+	CreatedAt    time.Time
+}
+
+// NewOAuthClient creates a new OAuth client entity.
+// A client needs redirect URIs to log users in, or audiences to get client-credentials tokens.
+func NewOAuthClient(id, name, secretHash string, redirectURIs, audiences, scopes []string) (*OAuthClient, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	if len(redirectURIs) == 0 && len(audiences) == 0 {
+		return nil, errors.New("at least one redirect URI or audience is required")
+	}
+
+	if len(audiences) > 0 && secretHash == "" {
+		return nil, errors.New("public clients cannot use client credentials")
+	}
+
+	for _, value := range append(append([]string{}, audiences...), scopes...) {
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, " ") {
+			return nil, errors.New("audiences and scopes must be non-empty and contain no spaces")
+		}
+	}
+
+	for _, uri := range redirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" || strings.ContainsAny(uri, " ") {
+			return nil, errors.New("redirect URIs must be absolute URLs without fragments")
+		}
+	}
+
+	return &OAuthClient{
+		ID:           id,
+		Name:         strings.TrimSpace(name),
+		SecretHash:   secretHash,
+		RedirectURIs: strings.Join(redirectURIs, " "),
+		Audiences:    strings.Join(audiences, " "),
+		Scopes:       strings.Join(scopes, " "),
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// IsPublic checks if the client cannot keep a secret, such as a single-page or native app
+func (c *OAuthClient) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+// RedirectURIList returns the client's registered redirect URIs
+func (c *OAuthClient) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+// AllowsRedirectURI checks if a redirect URI exactly matches one of the registered URIs
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIList() {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// AudienceList returns the services the client may get client-credentials tokens for
+func (c *OAuthClient) AudienceList() []string {
+	return strings.Fields(c.Audiences)
+}
+
+// ScopeList returns the scopes the client may get client-credentials tokens with
+func (c *OAuthClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
+
+// AllowsClientCredentials checks if the client is a confidential client registered for at least one audience
+func (c *OAuthClient) AllowsClientCredentials() bool {
+	return !c.IsPublic() && c.Audiences != ""
+}
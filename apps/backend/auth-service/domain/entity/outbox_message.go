@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// OutboxMessage is an event recorded in the same transaction as the change it describes,
+// waiting to be delivered to other services. Messages for the same user are delivered in order.
+type OutboxMessage struct {
+	ID            string `gorm:"primaryKey"`
+	Type          string
+	UserID        string `gorm:"index"`
+	Payload       string `gorm:"type:text"`
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time `gorm:"index"`
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time `gorm:"index"`
+}
+
+// NewOutboxMessage creates a new outbox message with a JSON encoded payload
+func NewOutboxMessage(id, messageType, userID string, payload interface{}) (*OutboxMessage, error) {
+	if id == "" {
+		return nil, errors.New("message ID cannot be empty")
+	}
+	if messageType == "" {
+		return nil, errors.New("message type cannot be empty")
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &OutboxMessage{
+		ID:            id,
+		Type:          messageType,
+		UserID:        userID,
+		Payload:       string(encoded),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// MarkDelivered records a successful delivery
+func (m *OutboxMessage) MarkDelivered() {
+	now := time.Now()
+	m.DeliveredAt = &now
+}
+
+// RecordFailure counts a failed delivery and schedules the next attempt after backoff
+func (m *OutboxMessage) RecordFailure(err error, backoff time.Duration) {
+	m.Attempts++
+	m.LastError = err.Error()
+	m.NextAttemptAt = time.Now().Add(backoff)
+}
+
+// DeadLetterMessage is an outbox message that could not be delivered and needs manual attention
+type DeadLetterMessage struct {
+	ID        string `gorm:"primaryKey"`
+	Type      string
+	UserID    string `gorm:"index"`
+	Payload   string `gorm:"type:text"`
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	FailedAt  time.Time `gorm:"index"`
+}
+
+// NewDeadLetterMessage creates a dead letter from an outbox message that gave up
+func NewDeadLetterMessage(message *OutboxMessage) *DeadLetterMessage {
+	return &DeadLetterMessage{
+		ID:        message.ID,
+		Type:      message.Type,
+		UserID:    message.UserID,
+		Payload:   message.Payload,
+		Attempts:  message.Attempts,
+		LastError: message.LastError,
+		CreatedAt: message.CreatedAt,
+		FailedAt:  time.Now(),
+	}
+}
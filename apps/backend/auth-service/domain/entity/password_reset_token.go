@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// PasswordResetToken represents a single-use password reset token entity.
+// Only the SHA-256 hash of the token is stored; the raw value is sent to the user.
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	TokenHash string `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewPasswordResetToken creates a new password reset token entity
+func NewPasswordResetToken(id, userID, tokenHash string, expiresAt time.Time) (*PasswordResetToken, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	if tokenHash == "" {
+		return nil, errors.New("token hash cannot be empty")
+	}
+
+	if expiresAt.Before(time.Now()) {
+		return nil, errors.New("expiration time must be in the future")
+	}
+
+	return &PasswordResetToken{
+		ID:        id,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsExpired checks if the token is expired
+func (t *PasswordResetToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the token has already been used
+func (t *PasswordResetToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// MarkUsed marks the token as used so it cannot be redeemed again
+func (t *PasswordResetToken) MarkUsed() error {
+	if t.IsUsed() {
+		return errors.New("token has already been used")
+	}
+
+	now := time.Now()
+	t.UsedAt = &now
+	return nil
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+)
+
+// PersonalAccessToken represents a named, scoped token for scripted clients.
+// Only the SHA-256 hash of the token is stored; the raw value is shown once at creation.
+type PersonalAccessToken struct {
+	ID          string
+	UserID      string `gorm:"index"`
+	Name        string
+	TokenHash   string `gorm:"uniqueIndex"`
+	TokenPrefix string
+	Scopes      string // space-separated
+	ExpiresAt   time.Time
+	LastUsedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+// NewPersonalAccessToken creates a new personal access token entity
+func NewPersonalAccessToken(id, userID, name, tokenHash, tokenPrefix string, scopes []valueobject.Scope, expiresAt time.Time) (*PersonalAccessToken, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	if tokenHash == "" {
+		return nil, errors.New("token hash cannot be empty")
+	}
+
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	if expiresAt.Before(time.Now()) {
+		return nil, errors.New("expiration time must be in the future")
+	}
+
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+
+	return &PersonalAccessToken{
+		ID:          id,
+		UserID:      userID,
+		Name:        strings.TrimSpace(name),
+		TokenHash:   tokenHash,
+		TokenPrefix: tokenPrefix,
+		Scopes:      strings.Join(names, " "),
+		ExpiresAt:   expiresAt,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// ScopeList returns the token's scopes
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// IsExpired checks if the token is expired
+func (t *PersonalAccessToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// MarkUsed records that the token was used
+func (t *PersonalAccessToken) MarkUsed() {
+	now := time.Now()
+	t.LastUsedAt = &now
+}
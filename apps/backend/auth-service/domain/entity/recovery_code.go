@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// RecoveryCode represents a one-time two-factor authentication recovery code.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        string
+	UserID    string `gorm:"index"`
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewRecoveryCode creates a new recovery code entity
+func NewRecoveryCode(id, userID, codeHash string) (*RecoveryCode, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	if codeHash == "" {
+		return nil, errors.New("code hash cannot be empty")
+	}
+
+	return &RecoveryCode{
+		ID:        id,
+		UserID:    userID,
+		CodeHash:  codeHash,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsUsed checks if the recovery code has already been used
+func (c *RecoveryCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+// MarkUsed marks the recovery code as used
+func (c *RecoveryCode) MarkUsed() error {
+	if c.IsUsed() {
+		return errors.New("recovery code has already been used")
+	}
+
+	now := time.Now()
+	c.UsedAt = &now
+	return nil
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// RevokedToken records an access token revoked before it expires, by its JWT ID.
+// The record is only needed until the token would have expired anyway.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"index"`
+	RevokedAt time.Time
+}
+
+// NewRevokedToken creates a new revoked token entity
+func NewRevokedToken(jti string, expiresAt time.Time) (*RevokedToken, error) {
+	if jti == "" {
+		return nil, errors.New("token ID cannot be empty")
+	}
+
+	return &RevokedToken{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+		RevokedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// SigningKey represents an asymmetric key used to sign access tokens.
+// Retired keys no longer sign but stay published until ExpiresAt so that
+// tokens they signed can still be verified.
+type SigningKey struct {
+	ID            string
+	Algorithm     string
+	PrivateKeyPEM string `gorm:"type:text"`
+	RetiredAt     *time.Time
+	ExpiresAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// NewSigningKey creates a new signing key entity
+func NewSigningKey(id, algorithm, privateKeyPEM string) (*SigningKey, error) {
+	if algorithm == "" {
+		return nil, errors.New("algorithm cannot be empty")
+	}
+
+	if privateKeyPEM == "" {
+		return nil, errors.New("private key cannot be empty")
+	}
+
+	return &SigningKey{
+		ID:            id,
+		Algorithm:     algorithm,
+		PrivateKeyPEM: privateKeyPEM,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// IsActive checks if the key may sign new tokens
+func (k *SigningKey) IsActive() bool {
+	return k.RetiredAt == nil
+}
+
+// IsExpired checks if the key can no longer verify tokens
+func (k *SigningKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// Retire stops the key from signing and keeps it verifiable for the given duration
+func (k *SigningKey) Retire(verifyFor time.Duration) {
+	now := time.Now()
+	expiresAt := now.Add(verifyFor)
+	k.RetiredAt = &now
+	k.ExpiresAt = &expiresAt
+}
@@ -3,37 +3,62 @@ package entity
 import (
 	"errors"
 	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
 )
 
-// Token represents a refresh token entity
+// Token represents a refresh token entity.
+// Tokens issued by rotating one another share a FamilyID; ParentID points to the rotated token.
+// A token family corresponds to one login session on one device.
+// Tokens issued to an OAuth client through the OpenID Connect provider record its ID and granted scope,
+// and only refresh for that client.
 type Token struct {
-	ID        string
-	UserID    string
-	Token     string
-	ExpiresAt time.Time
-	CreatedAt time.Time
+	ID               string
+	UserID           string
+	TokenHash        string `gorm:"uniqueIndex"`
+	FamilyID         string `gorm:"index"`
+	ParentID         string
+	OAuthClientID    string
+	OAuthScope       string
+	UserAgent        string
+	IPAddress        string
+	DeviceLabel      valueobject.DeviceLabel
+	ExpiresAt        time.Time
+	RotatedAt        *time.Time
+	SessionCreatedAt time.Time
+	LastUsedAt       time.Time
+	CreatedAt        time.Time
 }
 
 // NewToken creates a new token entity
-func NewToken(id, userID, token string, expiresAt time.Time) (*Token, error) {
+func NewToken(id, userID, tokenHash, familyID, parentID string, expiresAt time.Time) (*Token, error) {
 	if userID == "" {
 		return nil, errors.New("user ID cannot be empty")
 	}
 
-	if token == "" {
-		return nil, errors.New("token cannot be empty")
+	if tokenHash == "" {
+		return nil, errors.New("token hash cannot be empty")
+	}
+
+	if familyID == "" {
+		return nil, errors.New("family ID cannot be empty")
 	}
 
 	if expiresAt.Before(time.Now()) {
 		return nil, errors.New("expiration time must be in the future")
 	}
 
+	now := time.Now()
 	return &Token{
-		ID:        id,
-		UserID:    userID,
-		Token:     token,
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now(),
+		ID:               id,
+		UserID:           userID,
+		TokenHash:        tokenHash,
+		FamilyID:         familyID,
+		ParentID:         parentID,
+		ExpiresAt:        expiresAt,
+		SessionCreatedAt: now,
+		LastUsedAt:       now,
+		CreatedAt:        now,
 	}, nil
 }
 
@@ -41,3 +66,31 @@ func NewToken(id, userID, token string, expiresAt time.Time) (*Token, error) {
 func (t *Token) IsExpired() bool {
 	return time.Now().After(t.ExpiresAt)
 }
+
+// IsRotated checks if the token has already been exchanged for a new one
+func (t *Token) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+// MarkRotated marks the token as exchanged for a new one
+func (t *Token) MarkRotated() error {
+	if t.IsRotated() {
+		return errors.New("token has already been rotated")
+	}
+
+	now := time.Now()
+	t.RotatedAt = &now
+	return nil
+}
+
+// IsActive checks if the token can still be exchanged for new tokens
+func (t *Token) IsActive() bool {
+	return !t.IsRotated() && !t.IsExpired()
+}
+
+// SetClient records the client the token was issued to
+func (t *Token) SetClient(client valueobject.ClientInfo) {
+	t.UserAgent = client.UserAgent
+	t.IPAddress = client.IPAddress
+	t.DeviceLabel = valueobject.NewDeviceLabel(client.UserAgent)
+}
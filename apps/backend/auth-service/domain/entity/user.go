@@ -4,24 +4,30 @@ import (
 	"errors"
 	"time"
 
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // User represents a user entity
 type User struct {
-	ID             string
-	Email          string
-	Username       string
-	HashedPassword string
-	Role           valueobject.UserRole
-	Verified       bool
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID               string
+	Email            string
+	Username         string
+	HashedPassword   string
+	Role             valueobject.UserRole
+	Verified         bool
+	MFAEnabled       bool
+	MFASecret        string
+	MFAPendingSecret string
+	MFALastUsedStep  int64
+	Disabled         bool
+	DisabledAt       *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
 // NewUser creates a new user entity
-func NewUser(id, email, username, password string) (*User, error) {
+func NewUser(id, email, username, password string, hasher service.PasswordHasher) (*User, error) {
 	if email == "" {
 		return nil, errors.New("email cannot be empty")
 	}
@@ -34,7 +40,7 @@ func NewUser(id, email, username, password string) (*User, error) {
 		return nil, errors.New("password cannot be empty")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := hasher.Hash(password)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +50,7 @@ func NewUser(id, email, username, password string) (*User, error) {
 		ID:             id,
 		Email:          email,
 		Username:       username,
-		HashedPassword: string(hashedPassword),
+		HashedPassword: hashedPassword,
 		Role:           valueobject.RoleUser,
 		Verified:       false,
 		CreatedAt:      now,
@@ -53,23 +59,28 @@ func NewUser(id, email, username, password string) (*User, error) {
 }
 
 // VerifyPassword checks if the provided password matches the stored hashed password
-func (u *User) VerifyPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.HashedPassword), []byte(password))
-	return err == nil
+func (u *User) VerifyPassword(password string, hasher service.PasswordHasher) bool {
+	ok, err := hasher.Verify(u.HashedPassword, password)
+	return err == nil && ok
+}
+
+// PasswordNeedsRehash checks if the stored hash uses an outdated algorithm or parameters
+func (u *User) PasswordNeedsRehash(hasher service.PasswordHasher) bool {
+	return hasher.NeedsRehash(u.HashedPassword)
 }
 
 // ChangePassword changes the user's password
-func (u *User) ChangePassword(newPassword string) error {
+func (u *User) ChangePassword(newPassword string, hasher service.PasswordHasher) error {
 	if newPassword == "" {
 		return errors.New("new password cannot be empty")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := hasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
 
-	u.HashedPassword = string(hashedPassword)
+	u.HashedPassword = hashedPassword
 	u.UpdatedAt = time.Now()
 	return nil
 }
@@ -80,8 +91,116 @@ func (u *User) VerifyEmail() {
 	u.UpdatedAt = time.Now()
 }
 
+// ChangeEmail changes the user's email address. The new address is not verified yet.
+func (u *User) ChangeEmail(email string) error {
+	if email == "" {
+		return errors.New("email cannot be empty")
+	}
+
+	if email == u.Email {
+		return errors.New("email is unchanged")
+	}
+
+	u.Email = email
+	u.Verified = false
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeginMFAEnrollment stores a TOTP secret awaiting confirmation
+func (u *User) BeginMFAEnrollment(secret string) error {
+	if u.MFAEnabled {
+		return errors.New("two-factor authentication is already enabled")
+	}
+
+	u.MFAPendingSecret = secret
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// ConfirmMFAEnrollment activates the pending TOTP secret
+func (u *User) ConfirmMFAEnrollment() error {
+	if u.MFAPendingSecret == "" {
+		return errors.New("two-factor authentication enrollment has not been started")
+	}
+
+	u.MFASecret = u.MFAPendingSecret
+	u.MFAPendingSecret = ""
+	u.MFAEnabled = true
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// DisableMFA turns off two-factor authentication
+func (u *User) DisableMFA() error {
+	if u.Role == valueobject.RoleAdmin {
+		return errors.New("administrators must keep two-factor authentication enabled")
+	}
+
+	u.MFAEnabled = false
+	u.MFASecret = ""
+	u.MFAPendingSecret = ""
+	u.MFALastUsedStep = 0
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// RequiresMFAEnrollment checks if the user must enroll in two-factor authentication before logging in
+func (u *User) RequiresMFAEnrollment() bool {
+	return u.Role == valueobject.RoleAdmin && !u.MFAEnabled
+}
+
 // PromoteToAdmin promotes the user to admin role
 func (u *User) PromoteToAdmin() {
 	u.Role = valueobject.RoleAdmin
 	u.UpdatedAt = time.Now()
 }
+
+// ChangeRole changes the user's role
+func (u *User) ChangeRole(role valueobject.UserRole) error {
+	switch role {
+	case valueobject.RoleAdmin:
+		u.PromoteToAdmin()
+		return nil
+	case valueobject.RoleUser, valueobject.RoleAuthor:
+		u.Role = role
+		u.UpdatedAt = time.Now()
+		return nil
+	default:
+		return errors.New("invalid role")
+	}
+}
+
+// Disable prevents the user from logging in or refreshing tokens
+func (u *User) Disable() {
+	now := time.Now()
+	u.Disabled = true
+	u.DisabledAt = &now
+	u.UpdatedAt = now
+}
+
+// Enable lets a disabled user log in again
+func (u *User) Enable() {
+	u.Disabled = false
+	u.DisabledAt = nil
+	u.UpdatedAt = time.Now()
+}
+
+// Anonymize replaces everything that identifies the user, leaving a disabled account that cannot log in.
+// The password becomes the given random value, which is never disclosed.
+func (u *User) Anonymize(randomPassword string, hasher service.PasswordHasher) error {
+	if err := u.ChangePassword(randomPassword, hasher); err != nil {
+		return err
+	}
+
+	u.Email = "deleted-" + u.ID + "@deleted.invalid"
+	u.Username = "deleted-" + u.ID
+	u.Role = valueobject.RoleUser
+	u.Verified = false
+	u.MFAEnabled = false
+	u.MFASecret = ""
+	u.MFAPendingSecret = ""
+	u.MFALastUsedStep = 0
+	u.Disable()
+	return nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// AccountDeletionRepository defines the interface for account deletion saga data access
+type AccountDeletionRepository interface {
+	Create(ctx context.Context, deletion *entity.AccountDeletion) error
+	FindLatestByUserID(ctx context.Context, userID string) (*entity.AccountDeletion, error)
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.AccountDeletion, error)
+	UpdateProgress(ctx context.Context, deletion *entity.AccountDeletion, step int, status entity.AccountDeletionStatus) error
+	MarkCancelled(ctx context.Context, deletion *entity.AccountDeletion) error
+}
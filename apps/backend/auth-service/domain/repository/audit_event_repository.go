@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// AuditEventFilter narrows down audit events. Empty fields and zero times match everything.
+type AuditEventFilter struct {
+	UserID string
+	Type   string
+	From   time.Time
+	To     time.Time
+}
+
+// AuditEventRepository defines the interface for the append-only audit log.
+// Events are never updated or deleted.
+type AuditEventRepository interface {
+	Append(ctx context.Context, event *entity.AuditEvent) error
+	Find(ctx context.Context, filter AuditEventFilter, limit, offset int) ([]*entity.AuditEvent, int64, error)
+	Each(ctx context.Context, filter AuditEventFilter, fn func(*entity.AuditEvent) error) error
+}
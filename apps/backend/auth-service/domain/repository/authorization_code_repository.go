@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// AuthorizationCodeRepository defines the interface for authorization code data access
+type AuthorizationCodeRepository interface {
+	FindByCodeHash(ctx context.Context, codeHash string) (*entity.AuthorizationCode, error)
+	Create(ctx context.Context, code *entity.AuthorizationCode) error
+	MarkUsed(ctx context.Context, code *entity.AuthorizationCode) error
+	DeleteExpired(ctx context.Context) error
+}
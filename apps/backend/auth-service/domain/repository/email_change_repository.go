@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// EmailChangeRepository defines the interface for email change data access
+type EmailChangeRepository interface {
+	FindByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error)
+	FindByRevertTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error)
+	Create(ctx context.Context, change *entity.EmailChange) error
+	MarkConfirmed(ctx context.Context, change *entity.EmailChange) error
+	MarkReverted(ctx context.Context, change *entity.EmailChange) error
+	DeletePendingByUserID(ctx context.Context, userID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
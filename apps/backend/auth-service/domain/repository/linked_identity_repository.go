@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// LinkedIdentityRepository defines the interface for linked identity data access
+type LinkedIdentityRepository interface {
+	FindByID(ctx context.Context, id string) (*entity.LinkedIdentity, error)
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.LinkedIdentity, error)
+	FindByUserID(ctx context.Context, userID string) ([]*entity.LinkedIdentity, error)
+	Create(ctx context.Context, identity *entity.LinkedIdentity) error
+	Delete(ctx context.Context, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
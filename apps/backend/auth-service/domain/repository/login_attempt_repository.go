@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// LoginAttemptRepository defines the interface for failed login tracking.
+// FindByKey returns an empty record for untracked keys, and RecordFailure must be atomic per key.
+type LoginAttemptRepository interface {
+	FindByKey(ctx context.Context, key string) (*entity.LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, lockFor func(failures int) time.Duration) (*entity.LoginAttempt, error)
+	Reset(ctx context.Context, key string) error
+	DeleteStale(ctx context.Context, now time.Time) error
+}
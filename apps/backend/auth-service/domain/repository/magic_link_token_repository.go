@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// MagicLinkTokenRepository defines the interface for magic link token data access
+type MagicLinkTokenRepository interface {
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.MagicLinkToken, error)
+	Create(ctx context.Context, token *entity.MagicLinkToken) error
+	MarkUsed(ctx context.Context, token *entity.MagicLinkToken) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// OAuthClientRepository defines the interface for OAuth client data access
+type OAuthClientRepository interface {
+	FindByID(ctx context.Context, id string) (*entity.OAuthClient, error)
+	FindAll(ctx context.Context) ([]*entity.OAuthClient, error)
+	Create(ctx context.Context, client *entity.OAuthClient) error
+	Save(ctx context.Context, client *entity.OAuthClient) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// OutboxRepository defines the interface for the transactional outbox and its dead-letter table
+type OutboxRepository interface {
+	Create(ctx context.Context, message *entity.OutboxMessage) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.OutboxMessage, error)
+	Update(ctx context.Context, message *entity.OutboxMessage) error
+	MoveToDeadLetter(ctx context.Context, message *entity.OutboxMessage) error
+	DeleteDelivered(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// PasswordResetTokenRepository defines the interface for password reset token data access
+type PasswordResetTokenRepository interface {
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+	MarkUsed(ctx context.Context, token *entity.PasswordResetToken) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
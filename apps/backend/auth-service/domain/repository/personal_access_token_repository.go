@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// PersonalAccessTokenRepository defines the interface for personal access token data access
+type PersonalAccessTokenRepository interface {
+	FindByID(ctx context.Context, id string) (*entity.PersonalAccessToken, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error)
+	FindByUserID(ctx context.Context, userID string) ([]*entity.PersonalAccessToken, error)
+	Create(ctx context.Context, token *entity.PersonalAccessToken) error
+	UpdateLastUsed(ctx context.Context, token *entity.PersonalAccessToken) error
+	Delete(ctx context.Context, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// RecoveryCodeRepository defines the interface for recovery code data access
+type RecoveryCodeRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]*entity.RecoveryCode, error)
+	ReplaceForUser(ctx context.Context, userID string, codes []*entity.RecoveryCode) error
+	MarkUsed(ctx context.Context, code *entity.RecoveryCode) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// RevokedTokenRepository defines the interface for the revoked access token denylist
+type RevokedTokenRepository interface {
+	Create(ctx context.Context, token *entity.RevokedToken) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// SigningKeyRepository defines the interface for signing key data access
+type SigningKeyRepository interface {
+	FindAll(ctx context.Context) ([]*entity.SigningKey, error)
+	Create(ctx context.Context, key *entity.SigningKey) error
+	Update(ctx context.Context, key *entity.SigningKey) error
+	DeleteExpired(ctx context.Context) error
+}
@@ -8,10 +8,12 @@ import (
 
 // TokenRepository defines the interface for token data access
 type TokenRepository interface {
-	FindByToken(ctx context.Context, token string) (*entity.Token, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Token, error)
 	FindByUserID(ctx context.Context, userID string) ([]*entity.Token, error)
 	Create(ctx context.Context, token *entity.Token) error
+	MarkRotated(ctx context.Context, token *entity.Token) error
 	Delete(ctx context.Context, id string) error
+	DeleteByFamilyID(ctx context.Context, familyID string) error
 	DeleteByUserID(ctx context.Context, userID string) error
 	DeleteExpired(ctx context.Context) error
 }
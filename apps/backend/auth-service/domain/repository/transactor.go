@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// Transactor defines the interface for running work in a database transaction.
+// Repository calls made with the context passed to fn take part in the transaction,
+// which commits when fn returns nil and rolls back otherwise. Nested calls roll back only their own changes.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
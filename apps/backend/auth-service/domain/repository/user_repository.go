@@ -2,16 +2,22 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
 )
 
+// ErrUserNotFound is returned when no user matches a lookup
+var ErrUserNotFound = errors.New("user not found")
+
 // UserRepository defines the interface for user data access
 type UserRepository interface {
 	FindByID(ctx context.Context, id string) (*entity.User, error)
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByUsername(ctx context.Context, username string) (*entity.User, error)
+	Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error)
 	Create(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
+	MarkMFAStepUsed(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id string) error
 }
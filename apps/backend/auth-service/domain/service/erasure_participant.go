@@ -0,0 +1,12 @@
+package service
+
+import "context"
+
+// ErasureParticipant defines the interface for a service that holds data about users and erases it
+// when their account is deleted. Erasing is reversible until it is finalized, and every call is idempotent.
+type ErasureParticipant interface {
+	Name() string
+	Erase(ctx context.Context, erasureID, userID string) error
+	Revert(ctx context.Context, erasureID string) error
+	Finalize(ctx context.Context, erasureID string) error
+}
@@ -0,0 +1,18 @@
+package service
+
+import "context"
+
+// ExternalIdentity represents a user as asserted by an external identity provider
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// IdentityProvider defines the interface for an external OpenID Connect provider users can log in with
+type IdentityProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge, redirectURI string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, redirectURI, nonce string) (*ExternalIdentity, error)
+}
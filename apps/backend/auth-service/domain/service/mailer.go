@@ -0,0 +1,15 @@
+package service
+
+import "context"
+
+// Email represents an outgoing email message
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer defines the interface for sending emails
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
@@ -0,0 +1,9 @@
+package service
+
+// PasswordHasher defines the interface for hashing and verifying passwords.
+// Hashes encode their algorithm and parameters, so hashes made with older settings still verify.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	NeedsRehash(hash string) bool
+}
@@ -0,0 +1,18 @@
+package service
+
+// PasswordViolation describes one password policy rule a password breaks
+type PasswordViolation struct {
+	Rule    string
+	Message string
+}
+
+// PasswordPolicy defines the interface for checking new passwords.
+// The email and username are used to reject passwords that resemble the account.
+type PasswordPolicy interface {
+	Validate(password, email, username string) []PasswordViolation
+}
+
+// BreachedPasswordChecker defines the interface for checking passwords against known breaches
+type BreachedPasswordChecker interface {
+	IsBreached(password string) bool
+}
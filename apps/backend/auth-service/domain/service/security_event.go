@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SecurityEventType identifies a kind of security event
+type SecurityEventType string
+
+const (
+	// SecurityEventRegister is emitted when someone signs up
+	SecurityEventRegister SecurityEventType = "register"
+
+	// SecurityEventLoginSucceeded is emitted when a user is issued tokens at the end of a login
+	SecurityEventLoginSucceeded SecurityEventType = "login_succeeded"
+
+	// SecurityEventLoginFailed is emitted when a login attempt is refused
+	SecurityEventLoginFailed SecurityEventType = "login_failed"
+
+	// SecurityEventTokenRefreshed is emitted when a refresh token is rotated, or refused
+	SecurityEventTokenRefreshed SecurityEventType = "token_refreshed"
+
+	// SecurityEventLogout is emitted when a user logs out of a session
+	SecurityEventLogout SecurityEventType = "logout"
+
+	// SecurityEventSessionRevoked is emitted when a user revokes one of their sessions
+	SecurityEventSessionRevoked SecurityEventType = "session_revoked"
+
+	// SecurityEventLogoutAll is emitted when a user revokes all of their sessions
+	SecurityEventLogoutAll SecurityEventType = "logout_all"
+
+	// SecurityEventMFAEnabled is emitted when a user confirms their enrollment in two-factor authentication
+	SecurityEventMFAEnabled SecurityEventType = "mfa_enabled"
+
+	// SecurityEventMFADisabled is emitted when a user turns off two-factor authentication, or is refused
+	SecurityEventMFADisabled SecurityEventType = "mfa_disabled"
+
+	// SecurityEventRecoveryCodesRegenerated is emitted when a user replaces their recovery codes, or is refused
+	SecurityEventRecoveryCodesRegenerated SecurityEventType = "recovery_codes_regenerated"
+
+	// SecurityEventPersonalAccessTokenCreated is emitted when a user creates a personal access token
+	SecurityEventPersonalAccessTokenCreated SecurityEventType = "personal_access_token_created"
+
+	// SecurityEventPersonalAccessTokenRevoked is emitted when a user revokes a personal access token
+	SecurityEventPersonalAccessTokenRevoked SecurityEventType = "personal_access_token_revoked"
+
+	// SecurityEventPasswordChanged is emitted when a signed-in user changes their password
+	SecurityEventPasswordChanged SecurityEventType = "password_changed"
+
+	// SecurityEventPasswordReset is emitted when a password is reset with a reset token
+	SecurityEventPasswordReset SecurityEventType = "password_reset"
+
+	// SecurityEventEmailChangeRequested is emitted when a user asks to change their email address
+	SecurityEventEmailChangeRequested SecurityEventType = "email_change_requested"
+
+	// SecurityEventEmailChanged is emitted when a new email address is confirmed and applied
+	SecurityEventEmailChanged SecurityEventType = "email_changed"
+
+	// SecurityEventEmailChangeReverted is emitted when an email change is cancelled or undone from the old address
+	SecurityEventEmailChangeReverted SecurityEventType = "email_change_reverted"
+
+	// SecurityEventAccountDeletionScheduled is emitted when a user asks for their account to be deleted
+	SecurityEventAccountDeletionScheduled SecurityEventType = "account_deletion_scheduled"
+
+	// SecurityEventAccountDeletionCancelled is emitted when a user cancels the deletion of their account
+	SecurityEventAccountDeletionCancelled SecurityEventType = "account_deletion_cancelled"
+
+	// SecurityEventAccountDeleted is emitted when an account deletion has erased the account everywhere
+	SecurityEventAccountDeleted SecurityEventType = "account_deleted"
+
+	// SecurityEventAccountDeletionRolledBack is emitted when an account deletion failed and was undone
+	SecurityEventAccountDeletionRolledBack SecurityEventType = "account_deletion_rolled_back"
+
+	// SecurityEventRefreshTokenReuse is emitted when an already-rotated refresh token is presented
+	SecurityEventRefreshTokenReuse SecurityEventType = "refresh_token_reuse"
+
+	// SecurityEventLoginLockout is emitted when repeated failed logins lock an account or IP address
+	SecurityEventLoginLockout SecurityEventType = "login_lockout"
+
+	// SecurityEventAdminRoleChanged is emitted when an admin changes a user's role
+	SecurityEventAdminRoleChanged SecurityEventType = "admin_role_changed"
+
+	// SecurityEventAdminPasswordResetForced is emitted when an admin forces a user to reset their password
+	SecurityEventAdminPasswordResetForced SecurityEventType = "admin_password_reset_forced"
+
+	// SecurityEventAdminUserDisabled is emitted when an admin disables a user's account
+	SecurityEventAdminUserDisabled SecurityEventType = "admin_user_disabled"
+
+	// SecurityEventAdminUserEnabled is emitted when an admin enables a disabled account
+	SecurityEventAdminUserEnabled SecurityEventType = "admin_user_enabled"
+
+	// SecurityEventAdminUserUnlocked is emitted when an admin lifts a login lockout
+	SecurityEventAdminUserUnlocked SecurityEventType = "admin_user_unlocked"
+
+	// SecurityEventAdminImpersonationStarted is emitted when an admin is issued a token to act as a user
+	SecurityEventAdminImpersonationStarted SecurityEventType = "admin_impersonation_started"
+
+	// SecurityEventAdminUserDeleted is emitted when an admin deletes a user's account
+	SecurityEventAdminUserDeleted SecurityEventType = "admin_user_deleted"
+)
+
+// SecurityEventOutcome records whether the action behind a security event succeeded
+type SecurityEventOutcome string
+
+const (
+	// SecurityEventSuccess marks an action that succeeded
+	SecurityEventSuccess SecurityEventOutcome = "success"
+
+	// SecurityEventFailure marks an action that was refused; the event's reason says why
+	SecurityEventFailure SecurityEventOutcome = "failure"
+)
+
+// SecurityEvent represents a security-relevant occurrence.
+// ActorID is set when someone other than the user, such as an admin, caused the event.
+// IPAddress and UserAgent describe the client that made the request, when there was one.
+type SecurityEvent struct {
+	Type       SecurityEventType
+	UserID     string
+	ActorID    string
+	IPAddress  string
+	UserAgent  string
+	Outcome    SecurityEventOutcome
+	Reason     string
+	Details    map[string]string
+	OccurredAt time.Time
+}
+
+// SecurityEventPublisher defines the interface for emitting security events
+type SecurityEventPublisher interface {
+	Publish(ctx context.Context, event SecurityEvent) error
+}
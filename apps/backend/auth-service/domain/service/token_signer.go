@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+)
+
+// TokenSigner defines the interface for signing access tokens with a rotating key set
+type TokenSigner interface {
+	jwks.KeyProvider
+	Sign(ctx context.Context, claims map[string]interface{}) (string, error)
+	PublicKeys(ctx context.Context) ([]jwks.JSONWebKey, error)
+}
@@ -0,0 +1,23 @@
+package valueobject
+
+import "context"
+
+// ClientInfo describes the client that made a request
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// clientInfoKey is the context key ClientInfo is stored under
+type clientInfoKey struct{}
+
+// ContextWithClientInfo returns a copy of ctx that carries the client that made the request
+func ContextWithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, client)
+}
+
+// ClientInfoFromContext returns the client stored in ctx, or an empty ClientInfo when there is none
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return client
+}
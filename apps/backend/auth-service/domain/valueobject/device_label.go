@@ -0,0 +1,60 @@
+package valueobject
+
+import "strings"
+
+// DeviceLabel is a human-readable description of a client device, e.g. "Chrome on macOS"
+type DeviceLabel string
+
+// browsers and platforms are matched in order; more specific tokens must come first
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+var platforms = []struct{ token, name string }{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// NewDeviceLabel derives a device label from a User-Agent header
+func NewDeviceLabel(userAgent string) DeviceLabel {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, p := range platforms {
+		if strings.Contains(userAgent, p.token) {
+			platform = p.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return DeviceLabel(browser + " on " + platform)
+	case browser != "":
+		return DeviceLabel(browser)
+	case platform != "":
+		return DeviceLabel("Unknown browser on " + platform)
+	default:
+		return "Unknown device"
+	}
+}
@@ -0,0 +1,57 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Scope represents a permission granted to a personal access token
+type Scope string
+
+const (
+	// ScopeBlogsRead allows reading blogs
+	ScopeBlogsRead Scope = "blogs:read"
+
+	// ScopeBlogsWrite allows creating, updating, publishing and deleting blogs
+	ScopeBlogsWrite Scope = "blogs:write"
+
+	// ScopeUsersRead allows reading user profiles
+	ScopeUsersRead Scope = "users:read"
+
+	// ScopeUsersWrite allows updating the user's own profile
+	ScopeUsersWrite Scope = "users:write"
+
+	// ScopeUsersExport allows exporting all of the user's personal data
+	ScopeUsersExport Scope = "users:export"
+)
+
+// knownScopes lists every scope a token may be granted
+var knownScopes = map[Scope]bool{
+	ScopeBlogsRead:   true,
+	ScopeBlogsWrite:  true,
+	ScopeUsersRead:   true,
+	ScopeUsersWrite:  true,
+	ScopeUsersExport: true,
+}
+
+// ParseScopes validates scope names, dropping duplicates
+func ParseScopes(values []string) ([]Scope, error) {
+	if len(values) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	seen := make(map[Scope]bool, len(values))
+	scopes := make([]Scope, 0, len(values))
+	for _, v := range values {
+		scope := Scope(v)
+		if !knownScopes[scope] {
+			return nil, fmt.Errorf("unknown scope %q", v)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
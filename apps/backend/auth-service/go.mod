@@ -6,12 +6,15 @@ require (
 	github.com/golang-jwt/jwt/v4 v4.5.0
 	github.com/google/uuid v1.3.1
 	github.com/labstack/echo/v4 v4.11.3
+	github.com/pquerna/otp v1.4.0
+	github.com/vcd-simple-blog/packages/go/common v0.0.0
 	golang.org/x/crypto v0.14.0
 	gorm.io/driver/postgres v1.5.4
 	gorm.io/gorm v1.25.5
 )
 
 require (
+	github.com/boombuler/barcode v1.0.1-0.20190219062509-6c824513bacc // indirect
 	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
@@ -30,3 +33,5 @@ require (
 )
 
 replace github.com/vcd-simple-blog/common => ../../../packages/go/common
+
+replace github.com/vcd-simple-blog/packages/go/common => ../../../packages/go/common
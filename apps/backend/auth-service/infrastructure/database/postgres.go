@@ -19,7 +19,25 @@ func NewPostgresDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	}
 
 	// Auto migrate the schema
-	if err := db.AutoMigrate(&entity.User{}, &entity.Token{}); err != nil {
+	if err := db.AutoMigrate(
+		&entity.User{},
+		&entity.Token{},
+		&entity.PasswordResetToken{},
+		&entity.SigningKey{},
+		&entity.RecoveryCode{},
+		&entity.LoginAttempt{},
+		&entity.PersonalAccessToken{},
+		&entity.OAuthClient{},
+		&entity.AuthorizationCode{},
+		&entity.LinkedIdentity{},
+		&entity.MagicLinkToken{},
+		&entity.RevokedToken{},
+		&entity.AuditEvent{},
+		&entity.OutboxMessage{},
+		&entity.DeadLetterMessage{},
+		&entity.AccountDeletion{},
+		&entity.EmailChange{},
+	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
@@ -0,0 +1,91 @@
+// Package erasure calls the erasure endpoints other services expose for account deletion.
+package erasure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/servicetoken"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/erasure"
+)
+
+// HTTPParticipant implements the domain.service.ErasureParticipant interface for a service
+// that exposes the erasure endpoints under a base URL
+type HTTPParticipant struct {
+	name     string
+	baseURL  string
+	audience string
+	tokens   *servicetoken.Issuer
+	client   *http.Client
+}
+
+// NewHTTPParticipant creates a new HTTP erasure participant
+func NewHTTPParticipant(name, baseURL, audience string, tokens *servicetoken.Issuer) *HTTPParticipant {
+	return &HTTPParticipant{
+		name:     name,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		audience: audience,
+		tokens:   tokens,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the name of the participating service
+func (p *HTTPParticipant) Name() string {
+	return p.name
+}
+
+// Erase asks the service to erase a user's data
+func (p *HTTPParticipant) Erase(ctx context.Context, erasureID, userID string) error {
+	body, err := json.Marshal(erasure.Request{UserID: userID})
+	if err != nil {
+		return err
+	}
+	return p.do(ctx, http.MethodPut, "/"+erasureID, body)
+}
+
+// Revert asks the service to undo an erasure
+func (p *HTTPParticipant) Revert(ctx context.Context, erasureID string) error {
+	return p.do(ctx, http.MethodDelete, "/"+erasureID, nil)
+}
+
+// Finalize asks the service to make an erasure permanent
+func (p *HTTPParticipant) Finalize(ctx context.Context, erasureID string) error {
+	return p.do(ctx, http.MethodPost, "/"+erasureID+"/finalize", nil)
+}
+
+// do sends an authenticated request and checks for a successful response
+func (p *HTTPParticipant) do(ctx context.Context, method, path string, body []byte) error {
+	token, err := p.tokens.Token(ctx, p.audience, string(authz.UserErase))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s erasure %s %s failed with status %d: %s", p.name, method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+)
+
+// AuditPublisher implements the domain.service.SecurityEventPublisher interface by
+// appending each event to the audit log
+type AuditPublisher struct {
+	auditRepo repository.AuditEventRepository
+}
+
+// NewAuditPublisher creates a new audit publisher
+func NewAuditPublisher(auditRepo repository.AuditEventRepository) *AuditPublisher {
+	return &AuditPublisher{
+		auditRepo: auditRepo,
+	}
+}
+
+// Publish records a security event in the audit log
+func (p *AuditPublisher) Publish(ctx context.Context, event service.SecurityEvent) error {
+	auditEvent, err := entity.NewAuditEvent(
+		uuid.New().String(),
+		string(event.Type),
+		event.UserID,
+		event.ActorID,
+		event.IPAddress,
+		event.UserAgent,
+		string(event.Outcome),
+		event.Reason,
+		event.Details,
+		event.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return p.auditRepo.Append(ctx, auditEvent)
+}
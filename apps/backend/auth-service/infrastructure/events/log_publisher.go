@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+)
+
+// LogPublisher implements the domain.service.SecurityEventPublisher interface by
+// writing each event as a JSON line to the standard logger
+type LogPublisher struct{}
+
+// NewLogPublisher creates a new log publisher
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+// Publish writes a security event to the log
+func (p *LogPublisher) Publish(ctx context.Context, event service.SecurityEvent) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":        event.Type,
+		"user_id":     event.UserID,
+		"actor_id":    event.ActorID,
+		"ip_address":  event.IPAddress,
+		"user_agent":  event.UserAgent,
+		"outcome":     event.Outcome,
+		"reason":      event.Reason,
+		"details":     event.Details,
+		"occurred_at": event.OccurredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("SECURITY EVENT: %s", payload)
+	return nil
+}
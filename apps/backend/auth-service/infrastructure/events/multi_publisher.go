@@ -0,0 +1,32 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+)
+
+// MultiPublisher implements the domain.service.SecurityEventPublisher interface by
+// handing each event to several publishers
+type MultiPublisher struct {
+	publishers []service.SecurityEventPublisher
+}
+
+// NewMultiPublisher creates a new publisher that fans events out to publishers
+func NewMultiPublisher(publishers ...service.SecurityEventPublisher) *MultiPublisher {
+	return &MultiPublisher{
+		publishers: publishers,
+	}
+}
+
+// Publish hands a security event to every publisher, even when an earlier one fails
+func (p *MultiPublisher) Publish(ctx context.Context, event service.SecurityEvent) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
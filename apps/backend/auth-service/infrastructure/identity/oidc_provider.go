@@ -0,0 +1,197 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+)
+
+// discoveryDocument holds the fields of an OpenID Connect discovery document the relying party needs
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider implements the domain.service.IdentityProvider interface for any OpenID Connect provider.
+// Endpoints and signing keys are discovered from the issuer on first use.
+type OIDCProvider struct {
+	name       string
+	cfg        config.IdentityProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *jwks.Client
+}
+
+// NewOIDCProvider creates a new OpenID Connect identity provider
+func NewOIDCProvider(name string, cfg config.IdentityProviderConfig) *OIDCProvider {
+	return &OIDCProvider{
+		name:       name,
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the provider URL that starts an authorization code flow with PKCE
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge, redirectURI string) (string, error) {
+	doc, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the identity asserted by the verified ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, redirectURI, nonce string) (*service.ExternalIdentity, error) {
+	doc, keys, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redeem code
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s token endpoint: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s rejected the authorization code with status %d", p.name, resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode %s token response: %w", p.name, err)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("%s did not return an ID token", p.name)
+	}
+
+	// Verify ID token
+	token, err := jwt.Parse(tokenResponse.IDToken, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		default:
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return keys.Key(ctx, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid %s ID token: %v", p.name, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyIssuer(doc.Issuer, true) || !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return nil, fmt.Errorf("%s ID token was not issued for this client", p.name)
+	}
+	if claims["nonce"] != nonce {
+		return nil, fmt.Errorf("%s ID token nonce does not match", p.name)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%s ID token has no subject", p.name)
+	}
+
+	identity := &service.ExternalIdentity{
+		Provider: p.name,
+		Subject:  subject,
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+
+	// Some providers send email_verified as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	return identity, nil
+}
+
+// discover fetches and caches the provider's discovery document and key set
+func (p *OIDCProvider) discover(ctx context.Context) (*discoveryDocument, *jwks.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, p.keys, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover %s: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %d discovering %s", resp.StatusCode, p.name)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode %s discovery document: %w", p.name, err)
+	}
+
+	// The document must describe the configured issuer (OpenID Connect Discovery section 4.3)
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, nil, fmt.Errorf("%s discovery document is for issuer %q", p.name, doc.Issuer)
+	}
+
+	p.discovery = &doc
+	p.keys = jwks.NewClient(doc.JWKSURI, time.Hour)
+	return p.discovery, p.keys, nil
+}
@@ -0,0 +1,302 @@
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+)
+
+// reloadInterval is how often keys are reloaded so rotations by other replicas are picked up
+const reloadInterval = time.Minute
+
+// loadedKey is a signing key with its parsed private key
+type loadedKey struct {
+	key     *entity.SigningKey
+	private crypto.Signer
+	method  jwt.SigningMethod
+}
+
+// KeyManager implements the domain.service.TokenSigner interface.
+// Keys are persisted so every replica signs and publishes the same key set.
+type KeyManager struct {
+	repo             repository.SigningKeyRepository
+	algorithm        string
+	rotationInterval time.Duration
+	verifyFor        time.Duration
+
+	mu       sync.RWMutex
+	keys     []*loadedKey
+	loadedAt time.Time
+}
+
+// NewKeyManager creates a new key manager
+func NewKeyManager(repo repository.SigningKeyRepository, jwtConfig config.JWTConfig) *KeyManager {
+	return &KeyManager{
+		repo:             repo,
+		algorithm:        jwtConfig.SigningAlgorithm,
+		rotationInterval: jwtConfig.KeyRotationInterval,
+		verifyFor:        jwtConfig.AccessTokenTTL,
+	}
+}
+
+// Init loads the key set and creates the first signing key if none exists
+func (m *KeyManager) Init(ctx context.Context) error {
+	if _, err := newSigningMethod(m.algorithm); err != nil {
+		return err
+	}
+
+	if err := m.reload(ctx); err != nil {
+		return err
+	}
+
+	if _, err := m.activeKey(); err != nil {
+		return m.Rotate(ctx)
+	}
+	return nil
+}
+
+// Run periodically reloads, rotates and prunes keys until the context is cancelled
+func (m *KeyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.maintain(ctx); err != nil {
+				log.Printf("signing key maintenance failed: %v", err)
+			}
+		}
+	}
+}
+
+// Rotate creates a new signing key and retires the current one
+func (m *KeyManager) Rotate(ctx context.Context) error {
+	method, err := newSigningMethod(m.algorithm)
+	if err != nil {
+		return err
+	}
+
+	privatePEM, err := generatePrivateKey(method)
+	if err != nil {
+		return err
+	}
+
+	key, err := entity.NewSigningKey(uuid.New().String(), method.Alg(), privatePEM)
+	if err != nil {
+		return err
+	}
+
+	if err := m.repo.Create(ctx, key); err != nil {
+		return err
+	}
+
+	// Retire every previously active key
+	m.mu.RLock()
+	previous := m.keys
+	m.mu.RUnlock()
+	for _, k := range previous {
+		if k.key.IsActive() {
+			retired := *k.key
+			retired.Retire(m.verifyFor)
+			if err := m.repo.Update(ctx, &retired); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.reload(ctx)
+}
+
+// Sign signs the claims with the active key and sets the kid header
+func (m *KeyManager) Sign(ctx context.Context, claims map[string]interface{}) (string, error) {
+	if err := m.reloadIfStale(ctx); err != nil {
+		return "", err
+	}
+
+	k, err := m.activeKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(k.method, jwt.MapClaims(claims))
+	token.Header["kid"] = k.key.ID
+	return token.SignedString(k.private)
+}
+
+// Key returns the public key with the given key ID
+func (m *KeyManager) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if err := m.reloadIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.key.ID == kid && !k.key.IsExpired() {
+			return k.private.Public(), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// PublicKeys returns the active and retired-but-unexpired public keys
+func (m *KeyManager) PublicKeys(ctx context.Context) ([]jwks.JSONWebKey, error) {
+	if err := m.reloadIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]jwks.JSONWebKey, 0, len(m.keys))
+	for _, k := range m.keys {
+		if k.key.IsExpired() {
+			continue
+		}
+		jwk, err := jwks.NewJSONWebKey(k.key.ID, k.key.Algorithm, k.private.Public())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, jwk)
+	}
+	return result, nil
+}
+
+// maintain reloads keys, rotates the active key when due and prunes expired keys
+func (m *KeyManager) maintain(ctx context.Context) error {
+	if err := m.reload(ctx); err != nil {
+		return err
+	}
+
+	k, err := m.activeKey()
+	if err != nil || time.Since(k.key.CreatedAt) >= m.rotationInterval {
+		if err := m.Rotate(ctx); err != nil {
+			return err
+		}
+	}
+
+	return m.repo.DeleteExpired(ctx)
+}
+
+// activeKey returns the newest key that may sign tokens
+func (m *KeyManager) activeKey() (*loadedKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.key.IsActive() {
+			return k, nil
+		}
+	}
+	return nil, errors.New("no active signing key")
+}
+
+// reloadIfStale reloads keys if they have not been loaded recently
+func (m *KeyManager) reloadIfStale(ctx context.Context) error {
+	m.mu.RLock()
+	stale := time.Since(m.loadedAt) >= reloadInterval
+	m.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+	return m.reload(ctx)
+}
+
+// reload loads and parses all keys from the repository
+func (m *KeyManager) reload(ctx context.Context) error {
+	stored, err := m.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	loaded := make([]*loadedKey, 0, len(stored))
+	for _, key := range stored {
+		method, err := newSigningMethod(key.Algorithm)
+		if err != nil {
+			return err
+		}
+		private, err := parsePrivateKey(key.PrivateKeyPEM)
+		if err != nil {
+			return fmt.Errorf("failed to parse signing key %s: %w", key.ID, err)
+		}
+		loaded = append(loaded, &loadedKey{key: key, private: private, method: method})
+	}
+
+	m.mu.Lock()
+	m.keys = loaded
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+// newSigningMethod returns the JWT signing method for a supported algorithm
+func newSigningMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// generatePrivateKey generates a private key for the signing method and encodes it as PKCS #8 PEM
+func generatePrivateKey(method jwt.SigningMethod) (string, error) {
+	var private crypto.Signer
+	var err error
+	switch method {
+	case jwt.SigningMethodRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", fmt.Errorf("unsupported signing method %q", method.Alg())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// parsePrivateKey decodes a PKCS #8 PEM private key
+func parsePrivateKey(privatePEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid PEM block")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	return signer, nil
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+)
+
+// SMTPMailer implements the domain.service.Mailer interface over SMTP.
+// Point it at a local catcher such as MailHog during development.
+type SMTPMailer struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPMailer creates a new SMTP mailer
+func NewSMTPMailer(cfg config.SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{
+		cfg: cfg,
+	}
+}
+
+// Send sends an email
+func (m *SMTPMailer) Send(ctx context.Context, email service.Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{email.To}, buildMessage(m.cfg.From, email)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// buildMessage builds an RFC 5322 plain text message
+func buildMessage(from string, email service.Email) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + email.To + "\r\n")
+	b.WriteString("Subject: " + email.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/servicetoken"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+// HTTPDeliverer implements the Deliverer interface by posting each message
+// to the consumer as an account event envelope. Requests carry a service token scoped to user:sync
+// and issued for the consumer's audience.
+type HTTPDeliverer struct {
+	url      string
+	audience string
+	tokens   *servicetoken.Issuer
+	client   *http.Client
+}
+
+// NewHTTPDeliverer creates a new HTTP deliverer
+func NewHTTPDeliverer(url, audience string, tokens *servicetoken.Issuer) *HTTPDeliverer {
+	return &HTTPDeliverer{
+		url:      url,
+		audience: audience,
+		tokens:   tokens,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Deliver posts a message to the consumer. Client errors other than authentication failures and
+// rate limiting mean the consumer will never accept the event, and are reported as ErrEventRejected.
+func (d *HTTPDeliverer) Deliver(ctx context.Context, message *entity.OutboxMessage) error {
+	body, err := json.Marshal(accountevents.Envelope{
+		ID:         message.ID,
+		Type:       accountevents.Type(message.Type),
+		UserID:     message.UserID,
+		OccurredAt: message.CreatedAt,
+		Payload:    json.RawMessage(message.Payload),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrEventRejected, err)
+	}
+
+	token, err := d.tokens.Token(ctx, d.audience, string(authz.UserSync))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("consumer responded with status %d", resp.StatusCode)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return fmt.Errorf("%w: consumer responded with status %d", ErrEventRejected, resp.StatusCode)
+	default:
+		return fmt.Errorf("consumer responded with status %d", resp.StatusCode)
+	}
+}
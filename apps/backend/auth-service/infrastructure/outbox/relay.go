@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+)
+
+// ErrEventRejected is returned when a consumer permanently refuses an event, so retrying cannot help
+var ErrEventRejected = errors.New("event rejected by consumer")
+
+// Deliverer defines the interface for delivering outbox messages to the service that consumes them
+type Deliverer interface {
+	Deliver(ctx context.Context, message *entity.OutboxMessage) error
+}
+
+// Relay delivers outbox messages to their consumer, retrying failures with exponential backoff
+// and moving messages that keep failing, or that the consumer rejects, to the dead-letter table
+type Relay struct {
+	outboxRepo repository.OutboxRepository
+	deliverer  Deliverer
+	config     config.OutboxConfig
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(outboxRepo repository.OutboxRepository, deliverer Deliverer, config config.OutboxConfig) *Relay {
+	return &Relay{
+		outboxRepo: outboxRepo,
+		deliverer:  deliverer,
+		config:     config,
+	}
+}
+
+// Run relays due messages every poll interval until the context is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RelayDue(ctx); err != nil {
+				log.Printf("outbox relay failed: %v", err)
+			}
+		}
+	}
+}
+
+// RelayDue attempts one batch of due messages and returns how many were delivered.
+// Messages are claimed up front, then delivered and updated one at a time outside any transaction.
+func (r *Relay) RelayDue(ctx context.Context) (int, error) {
+	messages, err := r.outboxRepo.ClaimDue(ctx, time.Now(), r.config.Lease, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, message := range messages {
+		deliverErr := r.deliverer.Deliver(ctx, message)
+		if deliverErr == nil {
+			message.MarkDelivered()
+			if err := r.outboxRepo.Update(ctx, message); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		message.RecordFailure(deliverErr, r.backoff(message.Attempts+1))
+		if errors.Is(deliverErr, ErrEventRejected) || message.Attempts >= r.config.MaxAttempts {
+			log.Printf("dead-lettering outbox message %s (%s) after %d attempts: %v", message.ID, message.Type, message.Attempts, deliverErr)
+			if err := r.outboxRepo.MoveToDeadLetter(ctx, message); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := r.outboxRepo.Update(ctx, message); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// backoff returns the delay before an attempt: the base delay doubling with each attempt up to the maximum
+func (r *Relay) backoff(attempt int) time.Duration {
+	delay := r.config.RetryBaseDelay
+	for i := 1; i < attempt && delay < r.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.config.RetryMaxDelay {
+		delay = r.config.RetryMaxDelay
+	}
+	return delay
+}
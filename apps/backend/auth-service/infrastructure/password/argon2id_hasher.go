@@ -0,0 +1,120 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// argon2idPrefix starts every encoded argon2id hash
+	argon2idPrefix = "$argon2id$"
+
+	// argon2idSaltLength is the salt length in bytes
+	argon2idSaltLength = 16
+
+	// argon2idKeyLength is the derived key length in bytes
+	argon2idKeyLength = 32
+)
+
+// Argon2Params holds the argon2id cost parameters
+type Argon2Params struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+}
+
+// Argon2idHasher implements the domain.service.PasswordHasher interface with argon2id.
+// Hashes are encoded as "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>".
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+// NewArgon2idHasher creates a new argon2id hasher
+func NewArgon2idHasher(params Argon2Params) (*Argon2idHasher, error) {
+	if params.Memory < 8*uint32(params.Threads) || params.Time < 1 || params.Threads < 1 {
+		return nil, errors.New("invalid argon2id parameters")
+	}
+
+	return &Argon2idHasher{
+		params: params,
+	}, nil
+}
+
+// Hash hashes a password
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Time, h.params.Memory, h.params.Threads, argon2idKeyLength)
+	return encodeArgon2id(h.params, salt, key), nil
+}
+
+// Verify checks a password against an argon2id hash using the parameters encoded in it
+func (h *Argon2idHasher) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+// NeedsRehash checks if a hash is not an argon2id hash with the configured parameters
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params != h.params || len(salt) != argon2idSaltLength || len(key) != argon2idKeyLength
+}
+
+// encodeArgon2id encodes an argon2id hash in the PHC string format
+func encodeArgon2id(params Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// decodeArgon2id decodes an argon2id hash in the PHC string format
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return params, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, errors.New("malformed argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errors.New("malformed argon2id salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("malformed argon2id key")
+	}
+
+	return params, salt, key, nil
+}
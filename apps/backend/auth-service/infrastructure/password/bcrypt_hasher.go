@@ -0,0 +1,60 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptHasher implements the domain.service.PasswordHasher interface with bcrypt
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a new bcrypt hasher
+func NewBcryptHasher(cost int) (*BcryptHasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, errors.New("invalid bcrypt cost")
+	}
+
+	return &BcryptHasher{
+		cost: cost,
+	}, nil
+}
+
+// Hash hashes a password
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify checks a password against a bcrypt hash of any cost
+func (h *BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NeedsRehash checks if a hash is not a bcrypt hash of the configured cost
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	if !isBcryptHash(hash) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// isBcryptHash checks if a hash is in the modular crypt format used by bcrypt
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,41 @@
+package password
+
+import (
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// benchmarkPassword is hashed while calibrating parameters
+const benchmarkPassword = "correct horse battery staple"
+
+// CalibrateArgon2id returns the smallest time cost at which one hash with the given memory and threads
+// takes at least the target duration on this machine
+func CalibrateArgon2id(target time.Duration, memory uint32, threads uint8) (Argon2Params, time.Duration) {
+	salt := make([]byte, argon2idSaltLength)
+	params := Argon2Params{Memory: memory, Time: 1, Threads: threads}
+	for {
+		start := time.Now()
+		argon2.IDKey([]byte(benchmarkPassword), salt, params.Time, params.Memory, params.Threads, argon2idKeyLength)
+		elapsed := time.Since(start)
+		if elapsed >= target || params.Time >= 64 {
+			return params, elapsed
+		}
+		params.Time++
+	}
+}
+
+// CalibrateBcrypt returns the smallest cost at which one hash takes at least the target duration on this machine
+func CalibrateBcrypt(target time.Duration) (int, time.Duration) {
+	cost := bcrypt.DefaultCost
+	for {
+		start := time.Now()
+		_, _ = bcrypt.GenerateFromPassword([]byte(benchmarkPassword), cost)
+		elapsed := time.Since(start)
+		if elapsed >= target || cost >= bcrypt.MaxCost {
+			return cost, elapsed
+		}
+		cost++
+	}
+}
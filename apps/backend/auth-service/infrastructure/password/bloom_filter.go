@@ -0,0 +1,122 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+// bloomFilterMagic starts every breached password filter file
+var bloomFilterMagic = [4]byte{'P', 'W', 'B', 'F'}
+
+// bloomFilterVersion is the version of the filter file format
+const bloomFilterVersion = 1
+
+// BloomFilter implements the domain.service.BreachedPasswordChecker interface with a bloom filter.
+// It never misses a breached password but reports a small fraction of other passwords as breached.
+//
+// The file format is the magic "PWBF", a version byte, the number of hash functions as a byte,
+// the number of bits as a big-endian uint64 and the bits.
+type BloomFilter struct {
+	bits   []byte
+	m      uint64
+	hashes uint8
+}
+
+// NewBloomFilter creates an empty filter sized for n passwords at the given false positive rate
+func NewBloomFilter(n uint64, falsePositiveRate float64) (*BloomFilter, error) {
+	if n == 0 || falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("invalid bloom filter size")
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint8(math.Max(1, math.Min(255, math.Round(float64(m)/float64(n)*math.Ln2))))
+	return &BloomFilter{
+		bits:   make([]byte, (m+7)/8),
+		m:      m,
+		hashes: hashes,
+	}, nil
+}
+
+// LoadBloomFilter reads a filter file
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var header struct {
+		Magic   [4]byte
+		Version uint8
+		Hashes  uint8
+		Bits    uint64
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != bloomFilterMagic || header.Version != bloomFilterVersion || header.Hashes == 0 || header.Bits == 0 {
+		return nil, errors.New("invalid breached password filter file")
+	}
+
+	bits := make([]byte, (header.Bits+7)/8)
+	if _, err := io.ReadFull(r, bits); err != nil {
+		return nil, err
+	}
+
+	return &BloomFilter{
+		bits:   bits,
+		m:      header.Bits,
+		hashes: header.Hashes,
+	}, nil
+}
+
+// Add adds a password to the filter
+func (f *BloomFilter) Add(password string) {
+	h1, h2 := bloomHashes(password)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// IsBreached checks if a password may be in the filter
+func (f *BloomFilter) IsBreached(password string) bool {
+	h1, h2 := bloomHashes(password)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo writes the filter in the file format read by LoadBloomFilter
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := struct {
+		Magic   [4]byte
+		Version uint8
+		Hashes  uint8
+		Bits    uint64
+	}{bloomFilterMagic, bloomFilterVersion, f.hashes, f.m}
+	if err := binary.Write(w, binary.BigEndian, header); err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(f.bits)
+	return int64(binary.Size(header) + n), err
+}
+
+// bloomHashes derives the two hashes combined into each bit index from the SHA-256 of a password
+func bloomHashes(password string) (uint64, uint64) {
+	sum := sha256.Sum256([]byte(password))
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	return h1, h2
+}
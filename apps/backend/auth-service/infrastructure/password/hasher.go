@@ -0,0 +1,74 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+)
+
+// Hasher implements the domain.service.PasswordHasher interface.
+// New hashes use the configured algorithm, while hashes of every supported algorithm verify,
+// so stored hashes can be upgraded on the next successful login.
+type Hasher struct {
+	bcrypt   *BcryptHasher
+	argon2id *Argon2idHasher
+	current  string
+}
+
+// NewHasher creates a new password hasher from configuration
+func NewHasher(cfg config.PasswordHashConfig) (*Hasher, error) {
+	bcryptHasher, err := NewBcryptHasher(cfg.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	argon2idHasher, err := NewArgon2idHasher(Argon2Params{
+		Memory:  cfg.Argon2Memory,
+		Time:    cfg.Argon2Time,
+		Threads: cfg.Argon2Threads,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := strings.ToLower(cfg.Algorithm)
+	if algorithm != "bcrypt" && algorithm != "argon2id" {
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.Algorithm)
+	}
+
+	return &Hasher{
+		bcrypt:   bcryptHasher,
+		argon2id: argon2idHasher,
+		current:  algorithm,
+	}, nil
+}
+
+// Hash hashes a password with the configured algorithm
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.current == "bcrypt" {
+		return h.bcrypt.Hash(password)
+	}
+	return h.argon2id.Hash(password)
+}
+
+// Verify checks a password against a hash of any supported algorithm
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return h.argon2id.Verify(hash, password)
+	case isBcryptHash(hash):
+		return h.bcrypt.Verify(hash, password)
+	default:
+		return false, errors.New("unsupported password hash")
+	}
+}
+
+// NeedsRehash checks if a hash uses another algorithm or other parameters than configured
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if h.current == "bcrypt" {
+		return h.bcrypt.NeedsRehash(hash)
+	}
+	return h.argon2id.NeedsRehash(hash)
+}
@@ -0,0 +1,144 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+)
+
+// defaultDenylist holds passwords refused regardless of configuration. They are compared case-insensitively,
+// both as given and after stripping trailing digits and symbols.
+var defaultDenylist = []string{
+	"12345678", "123456789", "1234567890", "87654321", "11111111", "00000000", "password", "passw0rd", "qwerty", "qwertyuiop", "letmein", "welcome", "admin", "iloveyou",
+	"monkey", "dragon", "football", "baseball", "sunshine", "princess", "abc", "abcdef", "abcdefgh",
+	"changeme", "secret", "blog", "simpleblog",
+}
+
+// Policy implements the domain.service.PasswordPolicy interface
+type Policy struct {
+	cfg      config.PasswordPolicyConfig
+	denylist map[string]bool
+	breached service.BreachedPasswordChecker
+}
+
+// NewPolicy creates a new password policy.
+// The breached password checker is optional.
+func NewPolicy(cfg config.PasswordPolicyConfig, breached service.BreachedPasswordChecker) *Policy {
+	denylist := make(map[string]bool, len(defaultDenylist)+len(cfg.Denylist))
+	for _, word := range append(defaultDenylist, cfg.Denylist...) {
+		denylist[strings.ToLower(strings.TrimSpace(word))] = true
+		denylist[normalizeForDenylist(word)] = true
+	}
+	delete(denylist, "")
+
+	return &Policy{
+		cfg:      cfg,
+		denylist: denylist,
+		breached: breached,
+	}
+}
+
+// Validate checks a password against every rule and returns all violations
+func (p *Policy) Validate(password, email, username string) []service.PasswordViolation {
+	var violations []service.PasswordViolation
+
+	// Length
+	length := utf8.RuneCountInString(password)
+	if length < p.cfg.MinLength {
+		violations = append(violations, service.PasswordViolation{
+			Rule:    "min_length",
+			Message: fmt.Sprintf("Password must be at least %d characters long", p.cfg.MinLength),
+		})
+	}
+	if p.cfg.MaxLength > 0 && length > p.cfg.MaxLength {
+		violations = append(violations, service.PasswordViolation{
+			Rule:    "max_length",
+			Message: fmt.Sprintf("Password must be at most %d characters long", p.cfg.MaxLength),
+		})
+	}
+
+	// Character classes
+	if classes := characterClasses(password); classes < p.cfg.MinCharacterClasses {
+		violations = append(violations, service.PasswordViolation{
+			Rule:    "character_classes",
+			Message: fmt.Sprintf("Password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.cfg.MinCharacterClasses),
+		})
+	}
+
+	// Similarity to the account
+	if p.cfg.RejectSimilarToAccount && similarToAccount(password, email, username) {
+		violations = append(violations, service.PasswordViolation{
+			Rule:    "similar_to_account",
+			Message: "Password must not contain your email address or username",
+		})
+	}
+
+	// Denylist
+	if p.denylist[strings.ToLower(strings.TrimSpace(password))] || p.denylist[normalizeForDenylist(password)] {
+		violations = append(violations, service.PasswordViolation{
+			Rule:    "denylist",
+			Message: "Password is too common",
+		})
+	}
+
+	// Breached passwords
+	if p.breached != nil && p.breached.IsBreached(password) {
+		violations = append(violations, service.PasswordViolation{
+			Rule:    "breached",
+			Message: "Password has appeared in a data breach; choose a different one",
+		})
+	}
+
+	return violations
+}
+
+// characterClasses counts the classes of lowercase letters, uppercase letters, digits and other characters used
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	count := 0
+	for _, used := range []bool{lower, upper, digit, other} {
+		if used {
+			count++
+		}
+	}
+	return count
+}
+
+// similarToAccount checks if a password contains the username or email local part, or is contained in them
+func similarToAccount(password, email, username string) bool {
+	normalized := strings.ToLower(password)
+	localPart := strings.ToLower(strings.SplitN(email, "@", 2)[0])
+	for _, part := range []string{localPart, strings.ToLower(username)} {
+		if len(part) < 3 {
+			continue
+		}
+		if strings.Contains(normalized, part) || strings.Contains(part, normalized) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeForDenylist lowercases a password and strips the digits and symbols commonly appended to weak passwords
+func normalizeForDenylist(password string) string {
+	return strings.TrimRightFunc(strings.ToLower(strings.TrimSpace(password)), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountDeletionRepository implements the domain.repository.AccountDeletionRepository interface
+type AccountDeletionRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountDeletionRepository creates a new account deletion repository
+func NewAccountDeletionRepository(db *gorm.DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{
+		db: db,
+	}
+}
+
+// Create creates a new account deletion
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *entity.AccountDeletion) error {
+	return conn(ctx, r.db).Create(deletion).Error
+}
+
+// FindLatestByUserID finds the most recent account deletion of a user
+func (r *AccountDeletionRepository) FindLatestByUserID(ctx context.Context, userID string) (*entity.AccountDeletion, error) {
+	var deletion entity.AccountDeletion
+	result := conn(ctx, r.db).Order("created_at DESC").First(&deletion, "user_id = ?", userID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("account deletion not found")
+		}
+		return nil, result.Error
+	}
+	return &deletion, nil
+}
+
+// ClaimDue leases up to limit active deletions whose next step is due, skipping rows other workers are claiming.
+// Claiming ends the grace period of scheduled deletions, moves the next attempt to the end of the lease and commits
+// at once, so no lock is held while steps run; a deletion whose worker stops before saving it is retried when the lease runs out.
+func (r *AccountDeletionRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.AccountDeletion, error) {
+	var deletions []*entity.AccountDeletion
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []entity.AccountDeletionStatus{
+				entity.AccountDeletionScheduled,
+				entity.AccountDeletionRunning,
+				entity.AccountDeletionCompensating,
+			}, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deletions)
+		if result.Error != nil || len(deletions) == 0 {
+			return result.Error
+		}
+
+		ids := make([]string, len(deletions))
+		for i, deletion := range deletions {
+			deletion.Lease(now.Add(lease))
+			ids[i] = deletion.ID
+		}
+		if err := tx.Model(&entity.AccountDeletion{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.AccountDeletion{}).
+			Where("id IN ? AND status = ?", ids, entity.AccountDeletionScheduled).
+			Update("status", entity.AccountDeletionRunning).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+// UpdateProgress saves an account deletion's progress, failing if it moved on from the step and status it was claimed at
+func (r *AccountDeletionRepository) UpdateProgress(ctx context.Context, deletion *entity.AccountDeletion, step int, status entity.AccountDeletionStatus) error {
+	result := conn(ctx, r.db).Model(&entity.AccountDeletion{}).
+		Where("id = ? AND step = ? AND status = ?", deletion.ID, step, status).
+		Select("status", "step", "was_disabled", "attempts", "last_error", "next_attempt_at", "completed_at", "updated_at").
+		Updates(deletion)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("account deletion changed since it was claimed")
+	}
+	return nil
+}
+
+// MarkCancelled persists the deletion's cancellation, failing if its grace period has already ended
+func (r *AccountDeletionRepository) MarkCancelled(ctx context.Context, deletion *entity.AccountDeletion) error {
+	result := conn(ctx, r.db).Model(&entity.AccountDeletion{}).
+		Where("id = ? AND status = ?", deletion.ID, entity.AccountDeletionScheduled).
+		Updates(map[string]interface{}{"status": deletion.Status, "updated_at": deletion.UpdatedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("account deletion already started")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"gorm.io/gorm"
+)
+
+// AuditEventRepository implements the domain.repository.AuditEventRepository interface
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditEventRepository creates a new audit event repository
+func NewAuditEventRepository(db *gorm.DB) *AuditEventRepository {
+	return &AuditEventRepository{
+		db: db,
+	}
+}
+
+// Append records a new audit event
+func (r *AuditEventRepository) Append(ctx context.Context, event *entity.AuditEvent) error {
+	return conn(ctx, r.db).Create(event).Error
+}
+
+// Find finds audit events matching the filter, newest first, and counts all matches
+func (r *AuditEventRepository) Find(ctx context.Context, filter repository.AuditEventFilter, limit, offset int) ([]*entity.AuditEvent, int64, error) {
+	db := r.filtered(ctx, filter)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*entity.AuditEvent
+	result := db.Order("occurred_at DESC, id DESC").Limit(limit).Offset(offset).Find(&events)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return events, total, nil
+}
+
+// Each calls fn for every audit event matching the filter, oldest first, reading rows one at a time.
+// It stops at the first error fn returns.
+func (r *AuditEventRepository) Each(ctx context.Context, filter repository.AuditEventFilter, fn func(*entity.AuditEvent) error) error {
+	db := r.filtered(ctx, filter).Order("occurred_at ASC, id ASC")
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event entity.AuditEvent
+		if err := db.ScanRows(rows, &event); err != nil {
+			return err
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filtered starts a query over the audit events matching the filter
+func (r *AuditEventRepository) filtered(ctx context.Context, filter repository.AuditEventFilter) *gorm.DB {
+	db := conn(ctx, r.db).Model(&entity.AuditEvent{})
+	if filter.UserID != "" {
+		db = db.Where("user_id = ? OR actor_id = ?", filter.UserID, filter.UserID)
+	}
+	if filter.Type != "" {
+		db = db.Where("type = ?", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		db = db.Where("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		db = db.Where("occurred_at < ?", filter.To)
+	}
+	return db
+}
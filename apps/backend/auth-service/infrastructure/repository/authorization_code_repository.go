@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// AuthorizationCodeRepository implements the domain.repository.AuthorizationCodeRepository interface
+type AuthorizationCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthorizationCodeRepository creates a new authorization code repository
+func NewAuthorizationCodeRepository(db *gorm.DB) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{
+		db: db,
+	}
+}
+
+// FindByCodeHash finds an authorization code by its hash
+func (r *AuthorizationCodeRepository) FindByCodeHash(ctx context.Context, codeHash string) (*entity.AuthorizationCode, error) {
+	var c entity.AuthorizationCode
+	result := conn(ctx, r.db).First(&c, "code_hash = ?", codeHash)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("authorization code not found")
+		}
+		return nil, result.Error
+	}
+	return &c, nil
+}
+
+// Create creates a new authorization code
+func (r *AuthorizationCodeRepository) Create(ctx context.Context, code *entity.AuthorizationCode) error {
+	return conn(ctx, r.db).Create(code).Error
+}
+
+// MarkUsed persists the code's used timestamp, failing if it was already used
+func (r *AuthorizationCodeRepository) MarkUsed(ctx context.Context, code *entity.AuthorizationCode) error {
+	result := conn(ctx, r.db).Model(&entity.AuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", code.UsedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("authorization code already used")
+	}
+	return nil
+}
+
+// DeleteExpired deletes all expired authorization codes
+func (r *AuthorizationCodeRepository) DeleteExpired(ctx context.Context) error {
+	return conn(ctx, r.db).Delete(&entity.AuthorizationCode{}, "expires_at < ?", time.Now()).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// EmailChangeRepository implements the domain.repository.EmailChangeRepository interface
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailChangeRepository creates a new email change repository
+func NewEmailChangeRepository(db *gorm.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{
+		db: db,
+	}
+}
+
+// FindByConfirmTokenHash finds an email change by the hash of its confirmation token
+func (r *EmailChangeRepository) FindByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	return r.findBy(ctx, "confirm_token_hash = ?", tokenHash)
+}
+
+// FindByRevertTokenHash finds an email change by the hash of its revert token
+func (r *EmailChangeRepository) FindByRevertTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	return r.findBy(ctx, "revert_token_hash = ?", tokenHash)
+}
+
+// Create creates a new email change
+func (r *EmailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	return conn(ctx, r.db).Create(change).Error
+}
+
+// MarkConfirmed persists the change's confirmation, failing if it was already confirmed or reverted
+func (r *EmailChangeRepository) MarkConfirmed(ctx context.Context, change *entity.EmailChange) error {
+	result := conn(ctx, r.db).Model(&entity.EmailChange{}).
+		Where("id = ? AND confirmed_at IS NULL AND reverted_at IS NULL", change.ID).
+		Update("confirmed_at", change.ConfirmedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("email change already confirmed or reverted")
+	}
+	return nil
+}
+
+// MarkReverted persists the change's revert, failing if it was already reverted
+func (r *EmailChangeRepository) MarkReverted(ctx context.Context, change *entity.EmailChange) error {
+	result := conn(ctx, r.db).Model(&entity.EmailChange{}).
+		Where("id = ? AND reverted_at IS NULL", change.ID).
+		Update("reverted_at", change.RevertedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("email change already reverted")
+	}
+	return nil
+}
+
+// DeletePendingByUserID deletes a user's email changes that were neither confirmed nor reverted
+func (r *EmailChangeRepository) DeletePendingByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).
+		Delete(&entity.EmailChange{}, "user_id = ? AND confirmed_at IS NULL AND reverted_at IS NULL", userID).Error
+}
+
+// DeleteByUserID deletes all email changes for a user
+func (r *EmailChangeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Delete(&entity.EmailChange{}, "user_id = ?", userID).Error
+}
+
+// findBy finds an email change matching a condition
+func (r *EmailChangeRepository) findBy(ctx context.Context, query string, args ...interface{}) (*entity.EmailChange, error) {
+	var change entity.EmailChange
+	result := conn(ctx, r.db).First(&change, append([]interface{}{query}, args...)...)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("email change not found")
+		}
+		return nil, result.Error
+	}
+	return &change, nil
+}
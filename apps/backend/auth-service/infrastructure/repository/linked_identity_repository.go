@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// LinkedIdentityRepository implements the domain.repository.LinkedIdentityRepository interface
+type LinkedIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewLinkedIdentityRepository creates a new linked identity repository
+func NewLinkedIdentityRepository(db *gorm.DB) *LinkedIdentityRepository {
+	return &LinkedIdentityRepository{
+		db: db,
+	}
+}
+
+// FindByID finds a linked identity by ID
+func (r *LinkedIdentityRepository) FindByID(ctx context.Context, id string) (*entity.LinkedIdentity, error) {
+	var i entity.LinkedIdentity
+	result := conn(ctx, r.db).First(&i, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("linked identity not found")
+		}
+		return nil, result.Error
+	}
+	return &i, nil
+}
+
+// FindByProviderSubject finds a linked identity by provider and external subject
+func (r *LinkedIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.LinkedIdentity, error) {
+	var i entity.LinkedIdentity
+	result := conn(ctx, r.db).First(&i, "provider = ? AND subject = ?", provider, subject)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("linked identity not found")
+		}
+		return nil, result.Error
+	}
+	return &i, nil
+}
+
+// FindByUserID finds a user's linked identities
+func (r *LinkedIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.LinkedIdentity, error) {
+	var identities []*entity.LinkedIdentity
+	result := conn(ctx, r.db).Order("created_at").Find(&identities, "user_id = ?", userID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return identities, nil
+}
+
+// Create creates a new linked identity
+func (r *LinkedIdentityRepository) Create(ctx context.Context, identity *entity.LinkedIdentity) error {
+	return conn(ctx, r.db).Create(identity).Error
+}
+
+// Delete deletes a linked identity
+func (r *LinkedIdentityRepository) Delete(ctx context.Context, id string) error {
+	return conn(ctx, r.db).Delete(&entity.LinkedIdentity{}, "id = ?", id).Error
+}
+
+// DeleteByUserID deletes all linked identities for a user
+func (r *LinkedIdentityRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Delete(&entity.LinkedIdentity{}, "user_id = ?", userID).Error
+}
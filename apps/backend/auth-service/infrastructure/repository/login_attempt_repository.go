@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptRepository implements the domain.repository.LoginAttemptRepository interface in Postgres,
+// so lockouts are shared by every replica
+type LoginAttemptRepository struct {
+	db     *gorm.DB
+	window time.Duration
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *gorm.DB, window time.Duration) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db:     db,
+		window: window,
+	}
+}
+
+// FindByKey finds the login attempt record for a key
+func (r *LoginAttemptRepository) FindByKey(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	var a entity.LoginAttempt
+	result := conn(ctx, r.db).First(&a, "key = ?", key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return entity.NewLoginAttempt(key), nil
+		}
+		return nil, result.Error
+	}
+	return &a, nil
+}
+
+// RecordFailure registers a failed login while holding a row lock on the key
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time, lockFor func(failures int) time.Duration) (*entity.LoginAttempt, error) {
+	var a entity.LoginAttempt
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Make sure the row exists so it can be locked
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entity.NewLoginAttempt(key)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&a, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		a.RegisterFailure(now, r.window, lockFor)
+		return tx.Save(&a).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Reset deletes the login attempt record for a key
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return conn(ctx, r.db).Delete(&entity.LoginAttempt{}, "key = ?", key).Error
+}
+
+// DeleteStale deletes records whose failures have expired and that are not locked
+func (r *LoginAttemptRepository) DeleteStale(ctx context.Context, now time.Time) error {
+	return conn(ctx, r.db).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until <= ?)", now.Add(-r.window), now).
+		Delete(&entity.LoginAttempt{}).Error
+}
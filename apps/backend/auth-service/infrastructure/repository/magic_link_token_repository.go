@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// MagicLinkTokenRepository implements the domain.repository.MagicLinkTokenRepository interface
+type MagicLinkTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewMagicLinkTokenRepository creates a new magic link token repository
+func NewMagicLinkTokenRepository(db *gorm.DB) *MagicLinkTokenRepository {
+	return &MagicLinkTokenRepository{
+		db: db,
+	}
+}
+
+// FindByTokenHash finds a magic link token by its hash
+func (r *MagicLinkTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.MagicLinkToken, error) {
+	var t entity.MagicLinkToken
+	result := conn(ctx, r.db).First(&t, "token_hash = ?", tokenHash)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("magic link token not found")
+		}
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+// Create creates a new magic link token
+func (r *MagicLinkTokenRepository) Create(ctx context.Context, token *entity.MagicLinkToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+// MarkUsed persists the token's used timestamp, failing if it was already used
+func (r *MagicLinkTokenRepository) MarkUsed(ctx context.Context, token *entity.MagicLinkToken) error {
+	result := conn(ctx, r.db).Model(&entity.MagicLinkToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", token.UsedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("magic link token already used")
+	}
+	return nil
+}
+
+// DeleteByUserID deletes all magic link tokens for a user
+func (r *MagicLinkTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Delete(&entity.MagicLinkToken{}, "user_id = ?", userID).Error
+}
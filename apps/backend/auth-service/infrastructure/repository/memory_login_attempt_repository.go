@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+)
+
+// MemoryLoginAttemptRepository implements the domain.repository.LoginAttemptRepository interface in memory.
+// Lockouts are per process, which suits single-replica and development deployments.
+type MemoryLoginAttemptRepository struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	attempts map[string]*entity.LoginAttempt
+}
+
+// NewMemoryLoginAttemptRepository creates a new in-memory login attempt repository
+func NewMemoryLoginAttemptRepository(window time.Duration) *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{
+		window:   window,
+		attempts: make(map[string]*entity.LoginAttempt),
+	}
+}
+
+// FindByKey finds the login attempt record for a key
+func (r *MemoryLoginAttemptRepository) FindByKey(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		return entity.NewLoginAttempt(key), nil
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// RecordFailure registers a failed login for a key
+func (r *MemoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time, lockFor func(failures int) time.Duration) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		a = entity.NewLoginAttempt(key)
+		r.attempts[key] = a
+	}
+	a.RegisterFailure(now, r.window, lockFor)
+
+	copied := *a
+	return &copied, nil
+}
+
+// Reset forgets the login attempt record for a key
+func (r *MemoryLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// DeleteStale forgets records whose failures have expired and that are not locked
+func (r *MemoryLoginAttemptRepository) DeleteStale(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, a := range r.attempts {
+		if a.IsStale(now, r.window) {
+			delete(r.attempts, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// OAuthClientRepository implements the domain.repository.OAuthClientRepository interface
+type OAuthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository creates a new OAuth client repository
+func NewOAuthClientRepository(db *gorm.DB) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		db: db,
+	}
+}
+
+// FindByID finds an OAuth client by its client ID
+func (r *OAuthClientRepository) FindByID(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	var c entity.OAuthClient
+	result := conn(ctx, r.db).First(&c, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("oauth client not found")
+		}
+		return nil, result.Error
+	}
+	return &c, nil
+}
+
+// FindAll finds all OAuth clients, newest first
+func (r *OAuthClientRepository) FindAll(ctx context.Context) ([]*entity.OAuthClient, error) {
+	var clients []*entity.OAuthClient
+	result := conn(ctx, r.db).Order("created_at DESC").Find(&clients)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return clients, nil
+}
+
+// Create creates a new OAuth client
+func (r *OAuthClientRepository) Create(ctx context.Context, client *entity.OAuthClient) error {
+	return conn(ctx, r.db).Create(client).Error
+}
+
+// Save creates an OAuth client or replaces the one with the same ID
+func (r *OAuthClientRepository) Save(ctx context.Context, client *entity.OAuthClient) error {
+	return conn(ctx, r.db).Save(client).Error
+}
+
+// Delete deletes an OAuth client
+func (r *OAuthClientRepository) Delete(ctx context.Context, id string) error {
+	return conn(ctx, r.db).Delete(&entity.OAuthClient{}, "id = ?", id).Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository implements the domain.repository.OutboxRepository interface
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// Create records a new outbox message
+func (r *OutboxRepository) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	return conn(ctx, r.db).Create(message).Error
+}
+
+// ClaimDue leases up to limit undelivered messages that are due, oldest first, skipping rows other relays are claiming.
+// A message is only due once every earlier message for the same user has been delivered or dead-lettered.
+// Claiming moves the next attempt to the end of the lease and commits at once, so no lock is held while messages
+// are delivered; a message whose relay stops before updating it is retried when the lease runs out.
+func (r *OutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.OutboxMessage, error) {
+	var messages []*entity.OutboxMessage
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND next_attempt_at <= ?", now).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_messages earlier
+				WHERE earlier.user_id = outbox_messages.user_id
+				AND earlier.delivered_at IS NULL
+				AND earlier.created_at < outbox_messages.created_at)`).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&messages)
+		if result.Error != nil || len(messages) == 0 {
+			return result.Error
+		}
+
+		ids := make([]string, len(messages))
+		for i, message := range messages {
+			message.NextAttemptAt = now.Add(lease)
+			ids[i] = message.ID
+		}
+		return tx.Model(&entity.OutboxMessage{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Update saves a message's delivery state
+func (r *OutboxRepository) Update(ctx context.Context, message *entity.OutboxMessage) error {
+	return conn(ctx, r.db).Save(message).Error
+}
+
+// MoveToDeadLetter atomically moves a message from the outbox to the dead-letter table
+func (r *OutboxRepository) MoveToDeadLetter(ctx context.Context, message *entity.OutboxMessage) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entity.NewDeadLetterMessage(message)).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.OutboxMessage{}, "id = ?", message.ID).Error
+	})
+}
+
+// DeleteDelivered deletes messages delivered before the given time
+func (r *OutboxRepository) DeleteDelivered(ctx context.Context, before time.Time) error {
+	return conn(ctx, r.db).Delete(&entity.OutboxMessage{}, "delivered_at < ?", before).Error
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// PasswordResetTokenRepository implements the domain.repository.PasswordResetTokenRepository interface
+type PasswordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenRepository creates a new password reset token repository
+func NewPasswordResetTokenRepository(db *gorm.DB) *PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{
+		db: db,
+	}
+}
+
+// FindByTokenHash finds a password reset token by its hash
+func (r *PasswordResetTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	var t entity.PasswordResetToken
+	result := conn(ctx, r.db).First(&t, "token_hash = ?", tokenHash)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("password reset token not found")
+		}
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+// Create creates a new password reset token
+func (r *PasswordResetTokenRepository) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+// MarkUsed persists the token's used timestamp, failing if it was already used
+func (r *PasswordResetTokenRepository) MarkUsed(ctx context.Context, token *entity.PasswordResetToken) error {
+	result := conn(ctx, r.db).Model(&entity.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", token.UsedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("password reset token already used")
+	}
+	return nil
+}
+
+// DeleteByUserID deletes all password reset tokens for a user
+func (r *PasswordResetTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Delete(&entity.PasswordResetToken{}, "user_id = ?", userID).Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenRepository implements the domain.repository.PersonalAccessTokenRepository interface
+type PersonalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPersonalAccessTokenRepository creates a new personal access token repository
+func NewPersonalAccessTokenRepository(db *gorm.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		db: db,
+	}
+}
+
+// FindByID finds a personal access token by ID
+func (r *PersonalAccessTokenRepository) FindByID(ctx context.Context, id string) (*entity.PersonalAccessToken, error) {
+	var t entity.PersonalAccessToken
+	result := conn(ctx, r.db).First(&t, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("personal access token not found")
+		}
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+// FindByTokenHash finds a personal access token by its hash
+func (r *PersonalAccessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error) {
+	var t entity.PersonalAccessToken
+	result := conn(ctx, r.db).First(&t, "token_hash = ?", tokenHash)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("personal access token not found")
+		}
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+// FindByUserID finds a user's personal access tokens, newest first
+func (r *PersonalAccessTokenRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.PersonalAccessToken, error) {
+	var tokens []*entity.PersonalAccessToken
+	result := conn(ctx, r.db).Order("created_at DESC").Find(&tokens, "user_id = ?", userID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+// Create creates a new personal access token
+func (r *PersonalAccessTokenRepository) Create(ctx context.Context, token *entity.PersonalAccessToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+// UpdateLastUsed persists the token's last used timestamp
+func (r *PersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, token *entity.PersonalAccessToken) error {
+	return conn(ctx, r.db).Model(&entity.PersonalAccessToken{}).
+		Where("id = ?", token.ID).
+		Update("last_used_at", token.LastUsedAt).Error
+}
+
+// Delete deletes a personal access token
+func (r *PersonalAccessTokenRepository) Delete(ctx context.Context, id string) error {
+	return conn(ctx, r.db).Delete(&entity.PersonalAccessToken{}, "id = ?", id).Error
+}
+
+// DeleteByUserID deletes all personal access tokens for a user
+func (r *PersonalAccessTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Delete(&entity.PersonalAccessToken{}, "user_id = ?", userID).Error
+}
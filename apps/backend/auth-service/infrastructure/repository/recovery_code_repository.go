@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository implements the domain.repository.RecoveryCodeRepository interface
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository creates a new recovery code repository
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db: db,
+	}
+}
+
+// FindByUserID finds all recovery codes for a user
+func (r *RecoveryCodeRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.RecoveryCode, error) {
+	var codes []*entity.RecoveryCode
+	result := conn(ctx, r.db).Find(&codes, "user_id = ?", userID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return codes, nil
+}
+
+// ReplaceForUser atomically replaces all recovery codes for a user
+func (r *RecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID string, codes []*entity.RecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// MarkUsed persists the code's used timestamp, failing if it was already used
+func (r *RecoveryCodeRepository) MarkUsed(ctx context.Context, code *entity.RecoveryCode) error {
+	result := conn(ctx, r.db).Model(&entity.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", code.UsedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("recovery code already used")
+	}
+	return nil
+}
+
+// DeleteByUserID deletes all recovery codes for a user
+func (r *RecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Delete(&entity.RecoveryCode{}, "user_id = ?", userID).Error
+}
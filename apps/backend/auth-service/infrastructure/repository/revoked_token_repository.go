@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository implements the domain.repository.RevokedTokenRepository interface
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository creates a new revoked token repository
+func NewRevokedTokenRepository(db *gorm.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		db: db,
+	}
+}
+
+// Create adds a token to the denylist; revoking a token twice is not an error
+func (r *RevokedTokenRepository) Create(ctx context.Context, token *entity.RevokedToken) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsRevoked checks if a token ID is on the denylist
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	result := conn(ctx, r.db).Model(&entity.RevokedToken{}).Where("jti = ?", jti).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// DeleteExpired deletes denylist entries for tokens that have expired
+func (r *RevokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return conn(ctx, r.db).Delete(&entity.RevokedToken{}, "expires_at < ?", now).Error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepository implements the domain.repository.SigningKeyRepository interface
+type SigningKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewSigningKeyRepository creates a new signing key repository
+func NewSigningKeyRepository(db *gorm.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		db: db,
+	}
+}
+
+// FindAll finds all signing keys, newest first
+func (r *SigningKeyRepository) FindAll(ctx context.Context) ([]*entity.SigningKey, error) {
+	var keys []*entity.SigningKey
+	result := conn(ctx, r.db).Order("created_at DESC").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+// Create creates a new signing key
+func (r *SigningKeyRepository) Create(ctx context.Context, key *entity.SigningKey) error {
+	return conn(ctx, r.db).Create(key).Error
+}
+
+// Update updates a signing key
+func (r *SigningKeyRepository) Update(ctx context.Context, key *entity.SigningKey) error {
+	return conn(ctx, r.db).Save(key).Error
+}
+
+// DeleteExpired deletes all signing keys that can no longer verify tokens
+func (r *SigningKeyRepository) DeleteExpired(ctx context.Context) error {
+	return conn(ctx, r.db).Delete(&entity.SigningKey{}, "expires_at < ?", time.Now()).Error
+}
@@ -20,10 +20,10 @@ func NewTokenRepository(db *gorm.DB) *TokenRepository {
 	}
 }
 
-// FindByToken finds a token by token string
-func (r *TokenRepository) FindByToken(ctx context.Context, token string) (*entity.Token, error) {
+// FindByTokenHash finds a token by its hash
+func (r *TokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Token, error) {
 	var t entity.Token
-	result := r.db.WithContext(ctx).First(&t, "token = ?", token)
+	result := conn(ctx, r.db).First(&t, "token_hash = ?", tokenHash)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("token not found")
@@ -36,7 +36,7 @@ func (r *TokenRepository) FindByToken(ctx context.Context, token string) (*entit
 // FindByUserID finds tokens by user ID
 func (r *TokenRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Token, error) {
 	var tokens []*entity.Token
-	result := r.db.WithContext(ctx).Find(&tokens, "user_id = ?", userID)
+	result := conn(ctx, r.db).Find(&tokens, "user_id = ?", userID)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -45,20 +45,39 @@ func (r *TokenRepository) FindByUserID(ctx context.Context, userID string) ([]*e
 
 // Create creates a new token
 func (r *TokenRepository) Create(ctx context.Context, token *entity.Token) error {
-	return r.db.WithContext(ctx).Create(token).Error
+	return conn(ctx, r.db).Create(token).Error
+}
+
+// MarkRotated persists the token's rotation timestamp, failing if it was already rotated
+func (r *TokenRepository) MarkRotated(ctx context.Context, token *entity.Token) error {
+	result := conn(ctx, r.db).Model(&entity.Token{}).
+		Where("id = ? AND rotated_at IS NULL", token.ID).
+		Update("rotated_at", token.RotatedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("token already rotated")
+	}
+	return nil
 }
 
 // Delete deletes a token
 func (r *TokenRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&entity.Token{}, "id = ?", id).Error
+	return conn(ctx, r.db).Delete(&entity.Token{}, "id = ?", id).Error
+}
+
+// DeleteByFamilyID deletes all tokens in a token family
+func (r *TokenRepository) DeleteByFamilyID(ctx context.Context, familyID string) error {
+	return conn(ctx, r.db).Delete(&entity.Token{}, "family_id = ?", familyID).Error
 }
 
 // DeleteByUserID deletes all tokens for a user
 func (r *TokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
-	return r.db.WithContext(ctx).Delete(&entity.Token{}, "user_id = ?", userID).Error
+	return conn(ctx, r.db).Delete(&entity.Token{}, "user_id = ?", userID).Error
 }
 
 // DeleteExpired deletes all expired tokens
 func (r *TokenRepository) DeleteExpired(ctx context.Context) error {
-	return r.db.WithContext(ctx).Delete(&entity.Token{}, "expires_at < ?", time.Now()).Error
+	return conn(ctx, r.db).Delete(&entity.Token{}, "expires_at < ?", time.Now()).Error
 }
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key the current transaction is stored under
+type txKey struct{}
+
+// Transactor implements the domain.repository.Transactor interface
+type Transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn in a transaction. Within the caller's transaction, fn runs under a savepoint
+// so its failure only rolls back its own changes.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction stored in ctx, or db when there is none
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
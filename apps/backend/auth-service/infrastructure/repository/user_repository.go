@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 	"gorm.io/gorm"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
 )
 
 // UserRepository implements the domain.repository.UserRepository interface
@@ -22,10 +24,10 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 // FindByID finds a user by ID
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).First(&user, "id = ?", id)
+	result := conn(ctx, r.db).First(&user, "id = ?", id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, result.Error
 	}
@@ -35,10 +37,10 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*entity.User,
 // FindByEmail finds a user by email
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).First(&user, "email = ?", email)
+	result := conn(ctx, r.db).First(&user, "email = ?", email)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, result.Error
 	}
@@ -48,27 +50,65 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity
 // FindByUsername finds a user by username
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).First(&user, "username = ?", username)
+	result := conn(ctx, r.db).First(&user, "username = ?", username)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
+// likeEscaper escapes the LIKE wildcards in a search query, and the escape character itself, so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Search finds users whose email or username contains the query, newest first, and counts all matches
+func (r *UserRepository) Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error) {
+	db := conn(ctx, r.db).Model(&entity.User{})
+	if query != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(query)) + "%"
+		db = db.Where(`LOWER(email) LIKE ? ESCAPE '\' OR LOWER(username) LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*entity.User
+	result := db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&users)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return users, total, nil
+}
+
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return conn(ctx, r.db).Create(user).Error
 }
 
 // Update updates a user
 func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return conn(ctx, r.db).Save(user).Error
+}
+
+// MarkMFAStepUsed persists the user's last used TOTP step, failing if that or a later step was already used
+func (r *UserRepository) MarkMFAStepUsed(ctx context.Context, user *entity.User) error {
+	result := conn(ctx, r.db).Model(&entity.User{}).
+		Where("id = ? AND mfa_last_used_step < ?", user.ID, user.MFALastUsedStep).
+		Update("mfa_last_used_step", user.MFALastUsedStep)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("two-factor authentication code already used")
+	}
+	return nil
 }
 
 // Delete deletes a user
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&entity.User{}, "id = ?", id).Error
+	return conn(ctx, r.db).Delete(&entity.User{}, "id = ?", id).Error
 }
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// AccountDeletionRequest represents a request to delete the current user's account
+type AccountDeletionRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// AccountDeletionResponse represents the progress of an account deletion
+type AccountDeletionResponse struct {
+	ID           string     `json:"id"`
+	Status       string     `json:"status"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// AdminUserResponse represents a user as seen by an admin
+type AdminUserResponse struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role"`
+	Verified   bool       `json:"verified"`
+	MFAEnabled bool       `json:"mfa_enabled"`
+	Disabled   bool       `json:"disabled"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// AdminUserListResponse represents a page of users and the total number of matches
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Total int64               `json:"total"`
+}
+
+// ChangeRoleRequest represents the request for changing a user's role
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user author admin"`
+}
+
+// ImpersonationResponse represents a short-lived access token that acts as another user
+type ImpersonationResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // in seconds
+	UserID      string `json:"user_id"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// AuditEventResponse represents an entry of the security audit log
+type AuditEventResponse struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	UserID     string            `json:"user_id,omitempty"`
+	ActorID    string            `json:"actor_id,omitempty"`
+	IPAddress  string            `json:"ip_address,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Outcome    string            `json:"outcome"`
+	Reason     string            `json:"reason,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// AuditEventListResponse represents a page of audit events and the total number of matches
+type AuditEventListResponse struct {
+	Events []AuditEventResponse `json:"events"`
+	Total  int64                `json:"total"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // RegisterRequest represents the request for user registration
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -23,6 +25,56 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// ForgotPasswordRequest represents the request for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the request for resetting a password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ChangePasswordRequest represents the request for changing the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// ChangeEmailRequest represents the request for changing the current user's email address
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// EmailChangeTokenRequest represents the request for confirming or reverting an email change
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" query:"token" validate:"required"`
+}
+
+// PasswordViolationResponse represents one password policy rule a password breaks
+type PasswordViolationResponse struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyErrorResponse represents a rejected password with every rule it breaks
+type PasswordPolicyErrorResponse struct {
+	Error      string                      `json:"error"`
+	Violations []PasswordViolationResponse `json:"violations"`
+}
+
+// VerifyEmailRequest represents the request for email verification
+type VerifyEmailRequest struct {
+	Token string `json:"token" query:"token" validate:"required"`
+}
+
+// ResendVerificationRequest represents the request for a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 // TokenResponse represents the response with authentication tokens
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -36,3 +88,55 @@ type UserResponse struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 }
+
+// SessionResponse represents an active login session
+type SessionResponse struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Current     bool      `json:"current"`
+}
+
+// CookieSessionResponse represents the response when tokens are issued as cookies
+type CookieSessionResponse struct {
+	ExpiresIn int    `json:"expires_in"` // in seconds
+	CSRFToken string `json:"csrf_token"`
+}
+
+// CreatePersonalAccessTokenRequest represents the request for a new personal access token
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// PersonalAccessTokenResponse represents a personal access token without its secret
+type PersonalAccessTokenResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatedPersonalAccessTokenResponse represents a new personal access token with its secret, shown only once
+type CreatedPersonalAccessTokenResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"`
+}
+
+// MagicLinkRequest represents the request for emailing a sign-in link
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ConsumeMagicLinkRequest represents the request for signing in with a sign-in link
+type ConsumeMagicLinkRequest struct {
+	Token string `json:"token" validate:"required"`
+}
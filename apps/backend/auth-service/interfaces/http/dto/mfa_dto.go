@@ -0,0 +1,53 @@
+package dto
+
+// LoginResponse represents the response for user login.
+// Either the tokens are set, or an MFA token that completes the login.
+type LoginResponse struct {
+	*TokenResponse
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+	MFAExpiresIn          int    `json:"mfa_expires_in,omitempty"` // in seconds
+}
+
+// MFAEnrollRequest represents the request for starting MFA enrollment.
+// MFAToken is used instead of an access token when enrollment is required to log in.
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// MFAConfirmRequest represents the request for confirming MFA enrollment
+type MFAConfirmRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFACodeRequest represents a request that must be confirmed with a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAVerifyRequest represents the request for completing login with a TOTP or recovery code
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFAEnrollResponse represents the response with a new TOTP secret
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCodePNG  string `json:"qr_code_png"` // base64-encoded
+}
+
+// MFAConfirmResponse represents the response after MFA enrollment is confirmed.
+// Tokens are set when enrollment completed a login.
+type MFAConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	*TokenResponse
+}
+
+// RecoveryCodesResponse represents the response with new recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
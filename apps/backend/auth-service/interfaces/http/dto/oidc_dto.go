@@ -0,0 +1,118 @@
+package dto
+
+import "time"
+
+// AuthorizeRequest represents an OAuth 2.0 authorization request
+type AuthorizeRequest struct {
+	ResponseType        string `query:"response_type" form:"response_type"`
+	ClientID            string `query:"client_id" form:"client_id"`
+	RedirectURI         string `query:"redirect_uri" form:"redirect_uri"`
+	Scope               string `query:"scope" form:"scope"`
+	State               string `query:"state" form:"state"`
+	Nonce               string `query:"nonce" form:"nonce"`
+	Prompt              string `query:"prompt" form:"prompt"`
+	CodeChallenge       string `query:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method" form:"code_challenge_method"`
+}
+
+// OAuthTokenRequest represents an OAuth 2.0 token request
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	Audience     string `form:"audience"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OAuthTokenResponse represents an OAuth 2.0 token response
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // in seconds
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// UserInfoResponse represents the OpenID Connect userinfo response
+type UserInfoResponse struct {
+	Sub               string `json:"sub"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+// OpenIDConfiguration represents the OpenID Connect discovery document
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// RegisterOAuthClientRequest represents the request for registering an OAuth client
+type RegisterOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Audiences    []string `json:"audiences"`
+	Scopes       []string `json:"scopes"`
+	Public       bool     `json:"public"`
+}
+
+// OAuthClientResponse represents a registered OAuth client
+type OAuthClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"` // only returned at registration
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Audiences    []string  `json:"audiences,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+	Public       bool      `json:"public"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TokenIntrospectionRequest represents an OAuth 2.0 token introspection (RFC 7662) or revocation (RFC 7009) request
+type TokenIntrospectionRequest struct {
+	Token         string `form:"token" json:"token"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// TokenIntrospectionResponse represents an OAuth 2.0 token introspection response (RFC 7662).
+// Inactive tokens only have the active member.
+type TokenIntrospectionResponse struct {
+	Active    bool        `json:"active"`
+	TokenType string      `json:"token_type,omitempty"`
+	Sub       string      `json:"sub,omitempty"`
+	Exp       int64       `json:"exp,omitempty"`
+	Iat       int64       `json:"iat,omitempty"`
+	Iss       string      `json:"iss,omitempty"`
+	JTI       string      `json:"jti,omitempty"`
+	Role      string      `json:"role,omitempty"`
+	Verified  bool        `json:"verified,omitempty"`
+	SessionID string      `json:"sid,omitempty"`
+	ClientID  string      `json:"client_id,omitempty"`
+	Scope     string      `json:"scope,omitempty"`
+	Act       *ActorClaim `json:"act,omitempty"`
+}
+
+// ActorClaim names the party acting on behalf of a token's subject (RFC 8693)
+type ActorClaim struct {
+	Sub string `json:"sub"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// SocialCallbackRequest represents the redirect back from an external identity provider
+type SocialCallbackRequest struct {
+	Code             string `json:"code" query:"code" form:"code"`
+	State            string `json:"state" query:"state" form:"state"`
+	Error            string `json:"error" query:"error" form:"error"`
+	ErrorDescription string `json:"error_description" query:"error_description" form:"error_description"`
+}
+
+// SocialLinkStartResponse represents the response with the provider URL that starts linking an identity
+type SocialLinkStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SocialLoginResponse represents the result of an external identity provider callback.
+// It is a login response, a linked identity, or a request to confirm linking to an existing account.
+type SocialLoginResponse struct {
+	*LoginResponse
+	LinkedProvider string `json:"linked_provider,omitempty"`
+	LinkRequired   bool   `json:"link_required,omitempty"`
+	LinkToken      string `json:"link_token,omitempty"`
+	Email          string `json:"email,omitempty"`
+}
+
+// ConfirmLinkRequest represents the request for linking an external identity to an existing account
+type ConfirmLinkRequest struct {
+	LinkToken string `json:"link_token" validate:"required"`
+	Password  string `json:"password" validate:"required"`
+	Code      string `json:"code"` // TOTP or recovery code, for accounts with two-factor authentication
+}
+
+// LinkedIdentityResponse represents an external identity linked to the user
+type LinkedIdentityResponse struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+// AccountDeletionHandler handles account deletion HTTP requests
+type AccountDeletionHandler struct {
+	deletionUseCase *usecases.AccountDeletionUseCase
+}
+
+// NewAccountDeletionHandler creates a new account deletion handler
+func NewAccountDeletionHandler(deletionUseCase *usecases.AccountDeletionUseCase) *AccountDeletionHandler {
+	return &AccountDeletionHandler{
+		deletionUseCase: deletionUseCase,
+	}
+}
+
+// Schedule handles the current user asking to delete their account
+func (h *AccountDeletionHandler) Schedule(c echo.Context) error {
+	var req dto.AccountDeletionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	deletion, err := h.deletionUseCase.ScheduleDeletion(c.Request().Context(), userID, req.Password)
+	if err != nil {
+		if errors.Is(err, usecases.ErrAccountDeletionScheduled) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, accountDeletionResponse(deletion))
+}
+
+// Get handles retrieving the current user's account deletion
+func (h *AccountDeletionHandler) Get(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	deletion, err := h.deletionUseCase.GetDeletion(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Account deletion not found"})
+	}
+
+	return c.JSON(http.StatusOK, accountDeletionResponse(deletion))
+}
+
+// Cancel handles the current user cancelling their account deletion
+func (h *AccountDeletionHandler) Cancel(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	deletion, err := h.deletionUseCase.CancelDeletion(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrAccountDeletionNotCancellable) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Account deletion not found"})
+	}
+
+	return c.JSON(http.StatusOK, accountDeletionResponse(deletion))
+}
+
+// accountDeletionResponse converts an account deletion to its response
+func accountDeletionResponse(deletion *entity.AccountDeletion) dto.AccountDeletionResponse {
+	return dto.AccountDeletionResponse{
+		ID:           deletion.ID,
+		Status:       string(deletion.Status),
+		ScheduledFor: deletion.ScheduledFor,
+		CompletedAt:  deletion.CompletedAt,
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	adminUseCase *usecases.AdminUseCase
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminUseCase *usecases.AdminUseCase) *AdminHandler {
+	return &AdminHandler{
+		adminUseCase: adminUseCase,
+	}
+}
+
+// ListUsers handles listing and searching users with pagination
+func (h *AdminHandler) ListUsers(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	users, total, err := h.adminUseCase.SearchUsers(c.Request().Context(), c.QueryParam("q"), limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve users"})
+	}
+
+	// Convert to response
+	response := dto.AdminUserListResponse{
+		Users: make([]dto.AdminUserResponse, len(users)),
+		Total: total,
+	}
+	for i, user := range users {
+		response.Users[i] = adminUserResponse(user)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetUser handles retrieving a single user
+func (h *AdminHandler) GetUser(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	user, err := h.adminUseCase.GetUser(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, adminUserResponse(user))
+}
+
+// ChangeRole handles changing a user's role
+func (h *AdminHandler) ChangeRole(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	user, err := h.adminUseCase.ChangeRole(c.Request().Context(), actorID, id, valueobject.UserRole(req.Role))
+	if err != nil {
+		return adminErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, adminUserResponse(user))
+}
+
+// ForcePasswordReset handles forcing a user to choose a new password
+func (h *AdminHandler) ForcePasswordReset(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	if err := h.adminUseCase.ForcePasswordReset(c.Request().Context(), actorID, id); err != nil {
+		return adminErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password reset required"})
+}
+
+// DisableUser handles disabling a user's account
+func (h *AdminHandler) DisableUser(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	if err := h.adminUseCase.DisableUser(c.Request().Context(), actorID, id); err != nil {
+		return adminErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "User disabled"})
+}
+
+// EnableUser handles enabling a disabled account
+func (h *AdminHandler) EnableUser(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	if err := h.adminUseCase.EnableUser(c.Request().Context(), actorID, id); err != nil {
+		return adminErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "User enabled"})
+}
+
+// DeleteUser handles deleting a user's account
+func (h *AdminHandler) DeleteUser(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	if err := h.adminUseCase.DeleteUser(c.Request().Context(), actorID, id); err != nil {
+		return adminErrorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Impersonate handles issuing a token that acts as a user on behalf of the admin
+func (h *AdminHandler) Impersonate(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	response, err := h.adminUseCase.Impersonate(c.Request().Context(), actorID, id)
+	if err != nil {
+		return adminErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// UnlockUser handles lifting a login lockout on a user's account
+func (h *AdminHandler) UnlockUser(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get admin ID from token
+	actorID := c.Get("user_id").(string)
+
+	if err := h.adminUseCase.UnlockUser(c.Request().Context(), actorID, id); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "User unlocked"})
+}
+
+// adminErrorResponse maps an admin use case error to a response
+func adminErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecases.ErrSelfAdministration), errors.Is(err, usecases.ErrImpersonationNotAllowed):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
+
+// adminUserResponse converts a user to its admin response
+func adminUserResponse(user *entity.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:         user.ID,
+		Email:      user.Email,
+		Username:   user.Username,
+		Role:       string(user.Role),
+		Verified:   user.Verified,
+		MFAEnabled: user.MFAEnabled,
+		Disabled:   user.Disabled,
+		DisabledAt: user.DisabledAt,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+}
@@ -1,21 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+
 	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
 )
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
 	authUseCase *usecases.AuthUseCase
+	cookies     *SessionCookies
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authUseCase *usecases.AuthUseCase) *AuthHandler {
+func NewAuthHandler(authUseCase *usecases.AuthUseCase, cookies *SessionCookies) *AuthHandler {
 	return &AuthHandler{
 		authUseCase: authUseCase,
+		cookies:     cookies,
 	}
 }
 
@@ -28,7 +36,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 
 	user, err := h.authUseCase.Register(c.Request().Context(), req.Email, req.Username, req.Password)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return passwordErrorResponse(c, err)
 	}
 
 	return c.JSON(http.StatusCreated, dto.UserResponse{
@@ -45,27 +53,51 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	tokens, err := h.authUseCase.Login(c.Request().Context(), req.Email, req.Password)
+	response, err := h.authUseCase.Login(c.Request().Context(), req.Email, req.Password, clientInfo(c))
+	var lockedErr *usecases.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
+	if errors.Is(err, usecases.ErrAccountDisabled) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, tokens)
+	// A second factor is still required
+	if response.TokenResponse == nil {
+		return c.JSON(http.StatusOK, response)
+	}
+
+	return h.cookies.Respond(c, response.TokenResponse)
 }
 
-// RefreshToken handles token refresh
+// RefreshToken handles token refresh, reading the refresh token from the cookie in cookie session mode
 func (h *AuthHandler) RefreshToken(c echo.Context) error {
 	var req dto.RefreshTokenRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	tokens, err := h.authUseCase.RefreshToken(c.Request().Context(), req.RefreshToken)
+	if req.RefreshToken == "" {
+		refreshToken, err := h.cookies.RefreshToken(c)
+		if err != nil {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		req.RefreshToken = refreshToken
+	}
+
+	tokens, err := h.authUseCase.RefreshToken(c.Request().Context(), req.RefreshToken, clientInfo(c))
+	if errors.Is(err, usecases.ErrAccountDisabled) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, tokens)
+	return h.cookies.Respond(c, tokens)
 }
 
 // Logout handles user logout
@@ -75,10 +107,202 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
+	if req.RefreshToken == "" {
+		refreshToken, err := h.cookies.RefreshToken(c)
+		if err != nil {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		req.RefreshToken = refreshToken
+	}
+
 	err := h.authUseCase.Logout(c.Request().Context(), req.RefreshToken)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+
 	return c.JSON(http.StatusOK, map[string]string{"message": "Successfully logged out"})
 }
+
+// ForgotPassword handles password reset link requests
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req dto.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	if err := h.authUseCase.ForgotPassword(c.Request().Context(), req.Email); err != nil {
+		c.Logger().Errorf("failed to issue password reset: %v", err)
+	}
+
+	// Always respond the same way so account existence is not disclosed
+	return c.JSON(http.StatusOK, map[string]string{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// ResetPassword handles password reset
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req dto.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	err := h.authUseCase.ResetPassword(c.Request().Context(), req.Token, req.Password)
+	if err != nil {
+		return passwordErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password has been reset"})
+}
+
+// ChangePassword handles changing the current user's password
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	var req dto.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get user and session ID from token
+	userID := c.Get("user_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+
+	err := h.authUseCase.ChangePassword(c.Request().Context(), userID, sessionID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return passwordErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password has been changed"})
+}
+
+// VerifyEmail handles email verification via link (GET) or API (POST)
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req dto.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	err := h.authUseCase.VerifyEmail(c.Request().Context(), req.Token)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email verified successfully"})
+}
+
+// ResendVerification handles requests for a new verification email
+func (h *AuthHandler) ResendVerification(c echo.Context) error {
+	var req dto.ResendVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	err := h.authUseCase.ResendVerificationEmail(c.Request().Context(), req.Email)
+	if errors.Is(err, usecases.ErrVerificationRateLimited) {
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		c.Logger().Errorf("failed to resend verification email: %v", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "If the email is registered and unverified, a verification link has been sent"})
+}
+
+// ListSessions handles listing the current user's active sessions
+func (h *AuthHandler) ListSessions(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+	currentSessionID, _ := c.Get("session_id").(string)
+
+	sessions, err := h.authUseCase.ListSessions(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Convert to response
+	response := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = dto.SessionResponse{
+			ID:          session.FamilyID,
+			DeviceLabel: string(session.DeviceLabel),
+			UserAgent:   session.UserAgent,
+			IPAddress:   session.IPAddress,
+			CreatedAt:   session.SessionCreatedAt,
+			LastUsedAt:  session.LastUsedAt,
+			ExpiresAt:   session.ExpiresAt,
+			Current:     session.FamilyID == currentSessionID,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession handles revoking one of the current user's sessions
+func (h *AuthHandler) RevokeSession(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	if err := h.authUseCase.RevokeSession(c.Request().Context(), userID, id); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Session revoked"})
+}
+
+// LogoutAll handles logging the current user out of every session
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	if err := h.authUseCase.LogoutAll(c.Request().Context(), userID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Successfully logged out of all sessions"})
+}
+
+// JWKS handles publishing the public keys that verify access tokens
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	keys, err := h.authUseCase.PublicKeys(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, jwks.KeySet{Keys: keys})
+}
+
+// clientInfo extracts the client's IP address and user agent from the request
+func clientInfo(c echo.Context) valueobject.ClientInfo {
+	return valueobject.ClientInfo{
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
+
+// passwordErrorResponse responds to a failed password change, listing every broken rule when the password policy rejected it
+func passwordErrorResponse(c echo.Context, err error) error {
+	var policyErr *usecases.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	violations := make([]dto.PasswordViolationResponse, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		violations[i] = dto.PasswordViolationResponse{Rule: v.Rule, Message: v.Message}
+	}
+	return c.JSON(http.StatusUnprocessableEntity, dto.PasswordPolicyErrorResponse{
+		Error:      policyErr.Error(),
+		Violations: violations,
+	})
+}
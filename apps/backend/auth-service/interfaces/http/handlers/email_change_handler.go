@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+// EmailChangeHandler handles email change HTTP requests
+type EmailChangeHandler struct {
+	emailChangeUseCase *usecases.EmailChangeUseCase
+}
+
+// NewEmailChangeHandler creates a new email change handler
+func NewEmailChangeHandler(emailChangeUseCase *usecases.EmailChangeUseCase) *EmailChangeHandler {
+	return &EmailChangeHandler{
+		emailChangeUseCase: emailChangeUseCase,
+	}
+}
+
+// Request handles the current user asking to change their email address
+func (h *EmailChangeHandler) Request(c echo.Context) error {
+	var req dto.ChangeEmailRequest
+	if err := c.Bind(&req); err != nil || req.NewEmail == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	err := h.emailChangeUseCase.RequestChange(c.Request().Context(), userID, req.NewEmail, req.CurrentPassword)
+	if err != nil {
+		if errors.Is(err, usecases.ErrEmailTaken) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "A confirmation link has been sent to the new address"})
+}
+
+// Confirm handles confirming an email change from the link sent to the new address
+func (h *EmailChangeHandler) Confirm(c echo.Context) error {
+	var req dto.EmailChangeTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	err := h.emailChangeUseCase.ConfirmChange(c.Request().Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, usecases.ErrEmailTaken) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email address has been changed"})
+}
+
+// Revert handles cancelling or undoing an email change from the link sent to the old address
+func (h *EmailChangeHandler) Revert(c echo.Context) error {
+	var req dto.EmailChangeTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	err := h.emailChangeUseCase.RevertChange(c.Request().Context(), req.Token)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email change has been reverted"})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+const (
+	// magicLinkNonceCookie binds a sign-in link to the browser that requested it
+	magicLinkNonceCookie = "magic_link_nonce"
+
+	// magicLinkNonceCookiePath limits the nonce cookie to the magic link endpoints
+	magicLinkNonceCookiePath = "/api/v1/auth/magic-link"
+)
+
+// MagicLinkHandler handles passwordless login HTTP requests
+type MagicLinkHandler struct {
+	magicLinkUseCase *usecases.MagicLinkUseCase
+	cookies          *SessionCookies
+	magicLinkConfig  config.MagicLinkConfig
+}
+
+// NewMagicLinkHandler creates a new magic link handler
+func NewMagicLinkHandler(magicLinkUseCase *usecases.MagicLinkUseCase, cookies *SessionCookies, magicLinkConfig config.MagicLinkConfig) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkUseCase: magicLinkUseCase,
+		cookies:          cookies,
+		magicLinkConfig:  magicLinkConfig,
+	}
+}
+
+// Request handles sign-in link requests
+func (h *MagicLinkHandler) Request(c echo.Context) error {
+	var req dto.MagicLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	nonce, err := h.magicLinkUseCase.Request(c.Request().Context(), req.Email)
+	if err != nil {
+		c.Logger().Errorf("failed to issue magic link: %v", err)
+	} else {
+		h.setNonce(c, nonce)
+	}
+
+	// Always respond the same way so account existence is not disclosed
+	return c.JSON(http.StatusOK, map[string]string{"message": "If the email is registered, a sign-in link has been sent"})
+}
+
+// Consume handles signing in with a sign-in link
+func (h *MagicLinkHandler) Consume(c echo.Context) error {
+	var req dto.ConsumeMagicLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	var nonce string
+	if cookie, err := c.Cookie(magicLinkNonceCookie); err == nil {
+		nonce = cookie.Value
+	}
+
+	response, err := h.magicLinkUseCase.Consume(c.Request().Context(), req.Token, nonce, clientInfo(c))
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+	h.setNonce(c, "")
+
+	// A second factor is still required
+	if response.TokenResponse == nil {
+		return c.JSON(http.StatusOK, response)
+	}
+
+	return h.cookies.Respond(c, response.TokenResponse)
+}
+
+// setNonce sets the nonce cookie, or expires it when the nonce is empty.
+// The link is usually opened from an email client, so SameSite is always Lax.
+func (h *MagicLinkHandler) setNonce(c echo.Context, nonce string) {
+	maxAge := int(h.magicLinkConfig.TokenTTL.Seconds())
+	if nonce == "" {
+		maxAge = -1
+	}
+	cookie := h.cookies.cookie(magicLinkNonceCookie, nonce, magicLinkNonceCookiePath, maxAge, true)
+	cookie.SameSite = http.SameSiteLaxMode
+	c.SetCookie(cookie)
+}
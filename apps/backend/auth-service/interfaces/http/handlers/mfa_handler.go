@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+// MFAHandler handles two-factor authentication HTTP requests
+type MFAHandler struct {
+	authUseCase *usecases.AuthUseCase
+	mfaUseCase  *usecases.MFAUseCase
+	cookies     *SessionCookies
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(authUseCase *usecases.AuthUseCase, mfaUseCase *usecases.MFAUseCase, cookies *SessionCookies) *MFAHandler {
+	return &MFAHandler{
+		authUseCase: authUseCase,
+		mfaUseCase:  mfaUseCase,
+		cookies:     cookies,
+	}
+}
+
+// Enroll handles starting MFA enrollment, either signed in or with an enrollment MFA token
+func (h *MFAHandler) Enroll(c echo.Context) error {
+	var req dto.MFAEnrollRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	var response *dto.MFAEnrollResponse
+	var err error
+	if userID, ok := c.Get("user_id").(string); ok {
+		response, err = h.mfaUseCase.Enroll(c.Request().Context(), userID)
+	} else if req.MFAToken != "" {
+		response, err = h.authUseCase.BeginMFAEnrollment(c.Request().Context(), req.MFAToken)
+	} else {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header or MFA token is required"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Confirm handles confirming MFA enrollment, which completes the login when an MFA token is used
+func (h *MFAHandler) Confirm(c echo.Context) error {
+	var req dto.MFAConfirmRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	if userID, ok := c.Get("user_id").(string); ok {
+		recoveryCodes, err := h.mfaUseCase.Confirm(c.Request().Context(), userID, req.Code)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, dto.MFAConfirmResponse{RecoveryCodes: recoveryCodes})
+	}
+
+	if req.MFAToken == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header or MFA token is required"})
+	}
+
+	response, err := h.authUseCase.ConfirmMFAEnrollment(c.Request().Context(), req.MFAToken, req.Code, clientInfo(c))
+	if errors.Is(err, usecases.ErrAccountDisabled) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Keep the tokens out of the body in cookie session mode
+	if h.cookies.Enabled() {
+		if _, err := h.cookies.Set(c, response.TokenResponse); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		response.TokenResponse = nil
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Disable handles turning off MFA
+func (h *MFAHandler) Disable(c echo.Context) error {
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	if err := h.mfaUseCase.Disable(c.Request().Context(), userID, req.Code); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles replacing the current user's recovery codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	recoveryCodes, err := h.mfaUseCase.RegenerateRecoveryCodes(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// Verify handles completing a login with a TOTP or recovery code
+func (h *MFAHandler) Verify(c echo.Context) error {
+	var req dto.MFAVerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	tokens, err := h.authUseCase.VerifyMFA(c.Request().Context(), req.MFAToken, req.Code, clientInfo(c))
+	var lockedErr *usecases.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
+	if errors.Is(err, usecases.ErrAccountDisabled) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	return h.cookies.Respond(c, tokens)
+}
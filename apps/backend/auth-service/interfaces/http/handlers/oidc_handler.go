@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+// OIDCHandler handles OpenID Connect provider HTTP requests
+type OIDCHandler struct {
+	oidcUseCase *usecases.OIDCUseCase
+	oidcConfig  config.OIDCConfig
+}
+
+// NewOIDCHandler creates a new OIDC handler
+func NewOIDCHandler(oidcUseCase *usecases.OIDCUseCase, oidcConfig config.OIDCConfig) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUseCase: oidcUseCase,
+		oidcConfig:  oidcConfig,
+	}
+}
+
+// Discovery handles publishing the OpenID Connect discovery document
+func (h *OIDCHandler) Discovery(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=3600")
+	return c.JSON(http.StatusOK, h.oidcUseCase.Discovery())
+}
+
+// Authorize handles authorization requests, sending signed-out users to the login page first
+func (h *OIDCHandler) Authorize(c echo.Context) error {
+	var req dto.AuthorizeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+	}
+
+	// Never redirect to an unverified URI
+	if err := h.oidcUseCase.ValidateClientRedirect(c.Request().Context(), req); err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	// Get user ID from token
+	userID, ok := c.Get("user_id").(string)
+	if !ok {
+		if req.Prompt == "none" {
+			return c.Redirect(http.StatusFound, h.oidcUseCase.AuthorizationErrorRedirect(req, &usecases.OAuthError{
+				Code:        "login_required",
+				Description: "the user is not signed in",
+			}))
+		}
+
+		returnTo := strings.TrimSuffix(h.oidcConfig.IssuerURL, "/") + c.Request().URL.RequestURI()
+		return c.Redirect(http.StatusFound, h.oidcConfig.LoginURL+"?return_to="+url.QueryEscape(returnTo))
+	}
+
+	redirectURL, err := h.oidcUseCase.Authorize(c.Request().Context(), userID, req)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "server_error"})
+	}
+
+	return c.Redirect(http.StatusFound, redirectURL)
+}
+
+// Token handles token requests, accepting client credentials by HTTP Basic auth or in the form
+func (h *OIDCHandler) Token(c echo.Context) error {
+	var req dto.OAuthTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+	}
+
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	response, err := h.oidcUseCase.Token(c.Request().Context(), req, clientInfo(c))
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// UserInfo handles returning the claims of the user the access token belongs to
+func (h *OIDCHandler) UserInfo(c echo.Context) error {
+	// Get user ID and granted scope from token
+	userID := c.Get("user_id").(string)
+	scope := ""
+	if principal, ok := c.Get(authz.PrincipalKey).(*authz.Principal); ok {
+		scope = strings.Join(principal.Scopes, " ")
+	}
+
+	response, err := h.oidcUseCase.UserInfo(c.Request().Context(), userID, scope)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RegisterClient handles registering an OAuth client
+func (h *OIDCHandler) RegisterClient(c echo.Context) error {
+	var req dto.RegisterOAuthClientRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	client, secret, err := h.oidcUseCase.RegisterClient(c.Request().Context(), req.Name, req.RedirectURIs, req.Audiences, req.Scopes, req.Public)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, dto.OAuthClientResponse{
+		ClientID:     client.ID,
+		ClientSecret: secret,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIList(),
+		Audiences:    client.AudienceList(),
+		Scopes:       client.ScopeList(),
+		Public:       client.IsPublic(),
+		CreatedAt:    client.CreatedAt,
+	})
+}
+
+// ListClients handles listing registered OAuth clients
+func (h *OIDCHandler) ListClients(c echo.Context) error {
+	clients, err := h.oidcUseCase.ListClients(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Convert to response
+	response := make([]dto.OAuthClientResponse, len(clients))
+	for i, client := range clients {
+		response[i] = dto.OAuthClientResponse{
+			ClientID:     client.ID,
+			Name:         client.Name,
+			RedirectURIs: client.RedirectURIList(),
+			Audiences:    client.AudienceList(),
+			Scopes:       client.ScopeList(),
+			Public:       client.IsPublic(),
+			CreatedAt:    client.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// DeleteClient handles deleting a registered OAuth client
+func (h *OIDCHandler) DeleteClient(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	if err := h.oidcUseCase.DeleteClient(c.Request().Context(), id); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "OAuth client deleted"})
+}
+
+// oauthErrorResponse writes an OAuth 2.0 error response
+func oauthErrorResponse(c echo.Context, err error) error {
+	var oauthErr *usecases.OAuthError
+	if !errors.As(err, &oauthErr) {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "server_error"})
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+	return c.JSON(status, map[string]string{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
+)
+
+// PersonalAccessTokenHandler handles personal access token HTTP requests
+type PersonalAccessTokenHandler struct {
+	tokenUseCase *usecases.PersonalAccessTokenUseCase
+}
+
+// NewPersonalAccessTokenHandler creates a new personal access token handler
+func NewPersonalAccessTokenHandler(tokenUseCase *usecases.PersonalAccessTokenUseCase) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenUseCase: tokenUseCase,
+	}
+}
+
+// Create handles issuing a personal access token for the current user
+func (h *PersonalAccessTokenHandler) Create(c echo.Context) error {
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	token, rawToken, err := h.tokenUseCase.Create(c.Request().Context(), userID, req.Name, req.Scopes, ttl)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, dto.CreatedPersonalAccessTokenResponse{
+		PersonalAccessTokenResponse: personalAccessTokenResponse(token),
+		Token:                       rawToken,
+	})
+}
+
+// List handles listing the current user's personal access tokens
+func (h *PersonalAccessTokenHandler) List(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	tokens, err := h.tokenUseCase.List(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Convert to response
+	response := make([]dto.PersonalAccessTokenResponse, len(tokens))
+	for i, token := range tokens {
+		response[i] = personalAccessTokenResponse(token)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Revoke handles revoking one of the current user's personal access tokens
+func (h *PersonalAccessTokenHandler) Revoke(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
+	}
+
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	if err := h.tokenUseCase.Revoke(c.Request().Context(), userID, id); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Personal access token revoked"})
+}
+
+// Verify handles resolving a personal access token for other services
+func (h *PersonalAccessTokenHandler) Verify(c echo.Context) error {
+	var req pat.VerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	principal, err := h.tokenUseCase.Verify(c.Request().Context(), req.Token)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, principal)
+}
+
+// personalAccessTokenResponse converts a personal access token to its response
+func personalAccessTokenResponse(token *entity.PersonalAccessToken) dto.PersonalAccessTokenResponse {
+	return dto.PersonalAccessTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.ScopeList(),
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
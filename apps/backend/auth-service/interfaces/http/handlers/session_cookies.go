@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+)
+
+// refreshTokenCookiePath limits the refresh token cookie to the auth endpoints
+const refreshTokenCookiePath = "/api/v1/auth"
+
+// SessionCookies issues and reads the HttpOnly token cookies used in cookie session mode
+type SessionCookies struct {
+	jwtConfig    config.JWTConfig
+	cookieConfig config.SessionCookieConfig
+}
+
+// NewSessionCookies creates a new session cookie helper
+func NewSessionCookies(jwtConfig config.JWTConfig, cookieConfig config.SessionCookieConfig) *SessionCookies {
+	return &SessionCookies{
+		jwtConfig:    jwtConfig,
+		cookieConfig: cookieConfig,
+	}
+}
+
+// Enabled checks if cookie session mode is on
+func (s *SessionCookies) Enabled() bool {
+	return s.cookieConfig.Enabled
+}
+
+// AuthConfig returns the cookie names accepted by the auth middleware
+func (s *SessionCookies) AuthConfig() cookieauth.Config {
+	return cookieauth.Config{
+		AccessTokenCookie: s.jwtConfig.AccessTokenCookie,
+		CSRFCookie:        s.cookieConfig.CSRFCookie,
+		CSRFHeader:        s.cookieConfig.CSRFHeader,
+	}
+}
+
+// Respond sends issued tokens, as cookies in cookie session mode and as JSON otherwise
+func (s *SessionCookies) Respond(c echo.Context, tokens *dto.TokenResponse) error {
+	if !s.Enabled() {
+		return c.JSON(http.StatusOK, tokens)
+	}
+
+	csrfToken, err := s.Set(c, tokens)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, dto.CookieSessionResponse{
+		ExpiresIn: tokens.ExpiresIn,
+		CSRFToken: csrfToken,
+	})
+}
+
+// Set sets the access token, refresh token and CSRF cookies and returns the new CSRF token
+func (s *SessionCookies) Set(c echo.Context, tokens *dto.TokenResponse) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	csrfToken := base64.RawURLEncoding.EncodeToString(b)
+
+	refreshMaxAge := int(s.jwtConfig.RefreshTokenTTL.Seconds())
+	c.SetCookie(s.cookie(s.jwtConfig.AccessTokenCookie, tokens.AccessToken, "/", tokens.ExpiresIn, true))
+	c.SetCookie(s.cookie(s.jwtConfig.RefreshTokenCookie, tokens.RefreshToken, refreshTokenCookiePath, refreshMaxAge, true))
+	// The CSRF cookie is readable by scripts so they can echo it in the CSRF header
+	c.SetCookie(s.cookie(s.cookieConfig.CSRFCookie, csrfToken, "/", refreshMaxAge, false))
+	return csrfToken, nil
+}
+
+// Clear expires all session cookies
+func (s *SessionCookies) Clear(c echo.Context) {
+	c.SetCookie(s.cookie(s.jwtConfig.AccessTokenCookie, "", "/", -1, true))
+	c.SetCookie(s.cookie(s.jwtConfig.RefreshTokenCookie, "", refreshTokenCookiePath, -1, true))
+	c.SetCookie(s.cookie(s.cookieConfig.CSRFCookie, "", "/", -1, false))
+}
+
+// RefreshToken returns the refresh token cookie of a request that passes the CSRF check.
+// It returns an empty string when cookie session mode is off or the cookie is missing.
+func (s *SessionCookies) RefreshToken(c echo.Context) (string, error) {
+	if !s.Enabled() {
+		return "", nil
+	}
+
+	cookie, err := c.Cookie(s.jwtConfig.RefreshTokenCookie)
+	if err != nil || cookie.Value == "" {
+		return "", nil
+	}
+
+	if !s.AuthConfig().VerifyCSRF(c.Request()) {
+		return "", cookieauth.ErrInvalidCSRFToken
+	}
+	return cookie.Value, nil
+}
+
+// cookie builds a session cookie with the configured domain and security attributes
+func (s *SessionCookies) cookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   s.cookieConfig.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.cookieConfig.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSiteMode(s.cookieConfig.SameSite),
+	}
+	if maxAge < 0 {
+		cookie.Expires = time.Unix(0, 0)
+	}
+	return cookie
+}
+
+// sameSiteMode converts a configured SameSite value to its cookie mode
+func sameSiteMode(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
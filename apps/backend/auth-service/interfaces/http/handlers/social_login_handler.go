@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+const (
+	// socialStateCookie holds the signed state of a flow at an external identity provider
+	socialStateCookie = "oidc_state"
+
+	// socialStateCookiePath limits the state cookie to the social login endpoints
+	socialStateCookiePath = "/api/v1/auth/oidc"
+)
+
+// SocialLoginHandler handles login with external identity providers
+type SocialLoginHandler struct {
+	socialUseCase *usecases.SocialLoginUseCase
+	cookies       *SessionCookies
+	socialConfig  config.SocialLoginConfig
+}
+
+// NewSocialLoginHandler creates a new social login handler
+func NewSocialLoginHandler(socialUseCase *usecases.SocialLoginUseCase, cookies *SessionCookies, socialConfig config.SocialLoginConfig) *SocialLoginHandler {
+	return &SocialLoginHandler{
+		socialUseCase: socialUseCase,
+		cookies:       cookies,
+		socialConfig:  socialConfig,
+	}
+}
+
+// Start handles redirecting to an external identity provider to log in
+func (h *SocialLoginHandler) Start(c echo.Context) error {
+	authURL, stateToken, err := h.socialUseCase.Start(c.Request().Context(), c.Param("provider"), "")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	h.setState(c, stateToken)
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// Link handles starting to link an external identity provider to the current user
+func (h *SocialLoginHandler) Link(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	authURL, stateToken, err := h.socialUseCase.Start(c.Request().Context(), c.Param("provider"), userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	h.setState(c, stateToken)
+	return c.JSON(http.StatusOK, dto.SocialLinkStartResponse{AuthorizationURL: authURL})
+}
+
+// Callback handles the redirect back from an external identity provider
+func (h *SocialLoginHandler) Callback(c echo.Context) error {
+	var req dto.SocialCallbackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// The state is single use
+	stateCookie, err := c.Cookie(socialStateCookie)
+	if err != nil || stateCookie.Value == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing login state"})
+	}
+	h.setState(c, "")
+
+	if req.Error != "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": req.Error, "error_description": req.ErrorDescription})
+	}
+
+	response, err := h.socialUseCase.Callback(c.Request().Context(), c.Param("provider"), req.Code, req.State, stateCookie.Value, clientInfo(c))
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	// Keep the tokens out of the body in cookie session mode
+	if response.LoginResponse != nil && response.TokenResponse != nil && h.cookies.Enabled() {
+		return h.cookies.Respond(c, response.TokenResponse)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ConfirmLink handles linking an external identity to an existing account with its password
+func (h *SocialLoginHandler) ConfirmLink(c echo.Context) error {
+	var req dto.ConfirmLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	response, err := h.socialUseCase.ConfirmLink(c.Request().Context(), req.LinkToken, req.Password, req.Code, clientInfo(c))
+	var lockedErr *usecases.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
+	if errors.Is(err, usecases.ErrLinkMFAEnrollmentRequired) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	return h.cookies.Respond(c, response.TokenResponse)
+}
+
+// ListIdentities handles listing the current user's linked identities
+func (h *SocialLoginHandler) ListIdentities(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	identities, err := h.socialUseCase.ListIdentities(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	response := make([]dto.LinkedIdentityResponse, len(identities))
+	for i, identity := range identities {
+		response[i] = dto.LinkedIdentityResponse{
+			ID:        identity.ID,
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Unlink handles removing one of the current user's linked identities
+func (h *SocialLoginHandler) Unlink(c echo.Context) error {
+	// Get user ID from token
+	userID := c.Get("user_id").(string)
+
+	if err := h.socialUseCase.Unlink(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Identity unlinked"})
+}
+
+// setState sets the state cookie, or expires it when the state is empty.
+// It is sent on the provider's top-level redirect back, so SameSite is always Lax.
+func (h *SocialLoginHandler) setState(c echo.Context, stateToken string) {
+	maxAge := int(h.socialConfig.StateTTL.Seconds())
+	if stateToken == "" {
+		maxAge = -1
+	}
+	cookie := h.cookies.cookie(socialStateCookie, stateToken, socialStateCookiePath, maxAge, true)
+	cookie.SameSite = http.SameSiteLaxMode
+	c.SetCookie(cookie)
+}
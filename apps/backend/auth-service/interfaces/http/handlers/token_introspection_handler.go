@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+)
+
+// TokenIntrospectionHandler handles token introspection and revocation HTTP requests
+type TokenIntrospectionHandler struct {
+	introspectionUseCase *usecases.TokenIntrospectionUseCase
+}
+
+// NewTokenIntrospectionHandler creates a new token introspection handler
+func NewTokenIntrospectionHandler(introspectionUseCase *usecases.TokenIntrospectionUseCase) *TokenIntrospectionHandler {
+	return &TokenIntrospectionHandler{
+		introspectionUseCase: introspectionUseCase,
+	}
+}
+
+// Introspect handles token introspection requests from confidential OAuth clients (RFC 7662)
+func (h *TokenIntrospectionHandler) Introspect(c echo.Context) error {
+	req, err := bindTokenIntrospectionRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+	}
+
+	if err := h.introspectionUseCase.AuthenticateClient(c.Request().Context(), req.ClientID, req.ClientSecret, true); err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, h.introspectionUseCase.Introspect(c.Request().Context(), req.Token, req.TokenTypeHint))
+}
+
+// Revoke handles token revocation requests from OAuth clients (RFC 7009)
+func (h *TokenIntrospectionHandler) Revoke(c echo.Context) error {
+	req, err := bindTokenIntrospectionRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+	}
+
+	if err := h.introspectionUseCase.AuthenticateClient(c.Request().Context(), req.ClientID, req.ClientSecret, false); err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	if err := h.introspectionUseCase.Revoke(c.Request().Context(), req.Token, req.TokenTypeHint); err != nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "temporarily_unavailable"})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// AdminRevoke handles an administrator revoking any access or refresh token
+func (h *TokenIntrospectionHandler) AdminRevoke(c echo.Context) error {
+	var req dto.TokenIntrospectionRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	if err := h.introspectionUseCase.Revoke(c.Request().Context(), req.Token, req.TokenTypeHint); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Token revoked"})
+}
+
+// bindTokenIntrospectionRequest binds a form-encoded request, taking client credentials from HTTP Basic authentication
+func bindTokenIntrospectionRequest(c echo.Context) (dto.TokenIntrospectionRequest, error) {
+	var req dto.TokenIntrospectionRequest
+	if err := c.Bind(&req); err != nil {
+		return req, err
+	}
+	if req.Token == "" {
+		return req, echo.ErrBadRequest
+	}
+
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+	return req, nil
+}
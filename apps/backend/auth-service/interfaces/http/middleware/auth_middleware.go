@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+)
+
+// RevocationChecker reports access tokens revoked before they expire
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) bool
+}
+
+// AuthMiddleware handles authentication
+type AuthMiddleware struct {
+	keys        jwks.KeyProvider
+	revocations RevocationChecker
+	cookies     cookieauth.Config
+	audience    string
+}
+
+// NewAuthMiddleware creates a new auth middleware that also accepts the access token cookie
+// and refuses revoked access tokens. Service tokens must be issued for the audience.
+func NewAuthMiddleware(keys jwks.KeyProvider, revocations RevocationChecker, cookies cookieauth.Config, audience string) *AuthMiddleware {
+	return &AuthMiddleware{
+		keys:        keys,
+		revocations: revocations,
+		cookies:     cookies,
+		audience:    audience,
+	}
+}
+
+// OptionalAuthenticate authenticates a request when it has an Authorization header or access token cookie,
+// and passes it through unauthenticated otherwise
+func (m *AuthMiddleware) OptionalAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	authenticated := m.Authenticate(next)
+	return func(c echo.Context) error {
+		if _, err := m.cookies.AccessToken(c.Request()); errors.Is(err, cookieauth.ErrMissingToken) {
+			return next(c)
+		}
+		return authenticated(c)
+	}
+}
+
+// Authenticate authenticates a request
+func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Get token from header or cookie
+		tokenString, err := m.cookies.AccessToken(c.Request())
+		switch {
+		case errors.Is(err, cookieauth.ErrMissingToken):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header is required"})
+		case errors.Is(err, cookieauth.ErrInvalidCSRFToken):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Invalid CSRF token"})
+		case err != nil:
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid authorization format"})
+		}
+
+		// Parse token
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			switch token.Method.(type) {
+			case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+			default:
+				return nil, errors.New("unexpected signing method")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return m.keys.Key(c.Request().Context(), kid)
+		})
+		if err != nil || !token.Valid {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+
+		// Extract claims
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token claims"})
+		}
+
+		// Refuse revoked tokens
+		if jti, _ := claims["jti"].(string); m.revocations.IsRevoked(c.Request().Context(), jti) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+
+		// Tell end users from internal services
+		principal, err := authz.NewPrincipal(claims, m.audience, c.Request())
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		c.Set(authz.PrincipalKey, principal)
+
+		// Users call the auth service themselves; no service acts for them here
+		if principal.ActsForUser() {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": authz.ErrOnBehalfOfNotAllowed.Error()})
+		}
+
+		// Set user ID in context
+		userID := principal.UserID()
+		if userID == "" {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid user ID in token"})
+		}
+		c.Set("user_id", userID)
+
+		// Set user role and session ID in context
+		if role, ok := claims["role"].(string); ok {
+			c.Set("user_role", role)
+		}
+		if sessionID, ok := claims["sid"].(string); ok {
+			c.Set("session_id", sessionID)
+		}
+
+		// Tag requests made by an admin acting as the user
+		if actor := authz.Actor(claims); actor != "" {
+			c.Set("impersonator_id", actor)
+			authz.LogImpersonatedRequest(c.Request(), userID, actor)
+		}
+
+		return next(c)
+	}
+}
+
+// RejectImpersonation refuses requests made with an impersonation token, so an admin acting as a user
+// cannot change the user's credentials or obtain tokens that outlive the impersonation
+func (m *AuthMiddleware) RejectImpersonation(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := c.Get("impersonator_id").(string); ok {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Not allowed while impersonating a user"})
+		}
+
+		return next(c)
+	}
+}
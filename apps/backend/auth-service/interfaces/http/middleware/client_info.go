@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+)
+
+// ClientInfo stores the IP address and user agent of the client in the request context,
+// so that security events emitted while serving the request record them
+func ClientInfo(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		client := valueobject.ClientInfo{
+			IPAddress: c.RealIP(),
+			UserAgent: req.UserAgent(),
+		}
+		c.SetRequest(req.WithContext(valueobject.ContextWithClientInfo(req.Context(), client)))
+		return next(c)
+	}
+}
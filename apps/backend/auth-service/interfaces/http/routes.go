@@ -2,14 +2,52 @@ package http
 
 import (
 	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/handlers"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/middleware"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
 )
 
 // RegisterRoutes registers all API routes
-func RegisterRoutes(e *echo.Echo, authUseCase *usecases.AuthUseCase) {
+func RegisterRoutes(e *echo.Echo, cfg *config.Config, authUseCase *usecases.AuthUseCase, mfaUseCase *usecases.MFAUseCase, tokenUseCase *usecases.PersonalAccessTokenUseCase, oidcUseCase *usecases.OIDCUseCase, socialUseCase *usecases.SocialLoginUseCase, magicLinkUseCase *usecases.MagicLinkUseCase, adminUseCase *usecases.AdminUseCase, emailChangeUseCase *usecases.EmailChangeUseCase, auditUseCase *usecases.AuditUseCase, accountDeletionUseCase *usecases.AccountDeletionUseCase, exportUseCase *usecases.DataExportUseCase, introspectionUseCase *usecases.TokenIntrospectionUseCase, keys jwks.KeyProvider) {
 	// Create handlers
-	authHandler := handlers.NewAuthHandler(authUseCase)
+	cookies := handlers.NewSessionCookies(cfg.JWT, cfg.Cookies)
+	authHandler := handlers.NewAuthHandler(authUseCase, cookies)
+	mfaHandler := handlers.NewMFAHandler(authUseCase, mfaUseCase, cookies)
+	adminHandler := handlers.NewAdminHandler(adminUseCase)
+	auditHandler := handlers.NewAuditHandler(auditUseCase)
+	emailChangeHandler := handlers.NewEmailChangeHandler(emailChangeUseCase)
+	accountDeletionHandler := handlers.NewAccountDeletionHandler(accountDeletionUseCase)
+	exportHandler := handlers.NewDataExportHandler(exportUseCase)
+	tokenHandler := handlers.NewPersonalAccessTokenHandler(tokenUseCase)
+	oidcHandler := handlers.NewOIDCHandler(oidcUseCase, cfg.OIDC)
+	socialHandler := handlers.NewSocialLoginHandler(socialUseCase, cookies, cfg.SocialLogin)
+	magicLinkHandler := handlers.NewMagicLinkHandler(magicLinkUseCase, cookies, cfg.MagicLink)
+	introspectionHandler := handlers.NewTokenIntrospectionHandler(introspectionUseCase)
+
+	// Create middleware
+	authMiddleware := middleware.NewAuthMiddleware(keys, introspectionUseCase, cookies.AuthConfig(), cfg.OIDC.ServiceAudience)
+	userInfoMiddleware := middleware.NewAuthMiddleware(keys, introspectionUseCase, cookies.AuthConfig(), cfg.JWT.ClientAudience)
+	authzMiddleware := authz.NewMiddleware(authz.NewChecker(authz.DefaultMatrix), "user_role")
+
+	// Record the requesting client on security events
+	e.Use(middleware.ClientInfo)
+
+	// Public signing keys
+	e.GET("/.well-known/jwks.json", authHandler.JWKS)
+
+	// OpenID Connect provider routes
+	e.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
+	oauth := e.Group("/oauth")
+	oauth.GET("/authorize", oidcHandler.Authorize, authMiddleware.OptionalAuthenticate, authMiddleware.RejectImpersonation)
+	oauth.POST("/token", oidcHandler.Token)
+	oauth.GET("/userinfo", oidcHandler.UserInfo, userInfoMiddleware.Authenticate)
+	oauth.POST("/userinfo", oidcHandler.UserInfo, userInfoMiddleware.Authenticate)
+	oauth.POST("/introspect", introspectionHandler.Introspect)
+	oauth.POST("/revoke", introspectionHandler.Revoke)
 
 	// API v1 group
 	v1 := e.Group("/api/v1")
@@ -20,4 +58,79 @@ func RegisterRoutes(e *echo.Echo, authUseCase *usecases.AuthUseCase) {
 	auth.POST("/login", authHandler.Login)
 	auth.POST("/refresh", authHandler.RefreshToken)
 	auth.POST("/logout", authHandler.Logout)
+	auth.POST("/password/forgot", authHandler.ForgotPassword)
+	auth.POST("/password/reset", authHandler.ResetPassword)
+	auth.POST("/password/change", authHandler.ChangePassword, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	auth.GET("/verify-email", authHandler.VerifyEmail)
+	auth.POST("/verify-email", authHandler.VerifyEmail)
+	auth.POST("/email/change", emailChangeHandler.Request, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	auth.POST("/email/confirm", emailChangeHandler.Confirm)
+	auth.POST("/email/revert", emailChangeHandler.Revert)
+	auth.POST("/verify-email/resend", authHandler.ResendVerification,
+		echomiddleware.RateLimiter(echomiddleware.NewRateLimiterMemoryStore(1)))
+
+	// Magic link routes
+	auth.POST("/magic-link", magicLinkHandler.Request,
+		echomiddleware.RateLimiter(echomiddleware.NewRateLimiterMemoryStore(1)))
+	auth.POST("/magic-link/consume", magicLinkHandler.Consume)
+
+	// Session routes
+	auth.GET("/sessions", authHandler.ListSessions, authMiddleware.Authenticate)
+	auth.DELETE("/sessions/:id", authHandler.RevokeSession, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	auth.POST("/logout-all", authHandler.LogoutAll, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+
+	// Account deletion routes
+	auth.DELETE("/account", accountDeletionHandler.Schedule, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	auth.GET("/account/deletion", accountDeletionHandler.Get, authMiddleware.Authenticate)
+	auth.DELETE("/account/deletion", accountDeletionHandler.Cancel, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+
+	// MFA routes
+	mfa := auth.Group("/mfa")
+	mfa.POST("/verify", mfaHandler.Verify)
+	mfa.POST("/enroll", mfaHandler.Enroll, authMiddleware.OptionalAuthenticate, authMiddleware.RejectImpersonation)
+	mfa.POST("/confirm", mfaHandler.Confirm, authMiddleware.OptionalAuthenticate, authMiddleware.RejectImpersonation)
+	mfa.POST("/disable", mfaHandler.Disable, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	mfa.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+
+	// Social login routes
+	social := auth.Group("/oidc")
+	social.GET("/:provider/start", socialHandler.Start)
+	social.GET("/:provider/callback", socialHandler.Callback)
+	social.POST("/:provider/callback", socialHandler.Callback)
+	social.POST("/:provider/link", socialHandler.Link, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	auth.POST("/identities/confirm", socialHandler.ConfirmLink)
+	auth.GET("/identities", socialHandler.ListIdentities, authMiddleware.Authenticate)
+	auth.DELETE("/identities/:id", socialHandler.Unlink, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+
+	// Personal access token routes
+	tokens := auth.Group("/tokens")
+	tokens.POST("/verify", tokenHandler.Verify)
+	tokens.POST("", tokenHandler.Create, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	tokens.GET("", tokenHandler.List, authMiddleware.Authenticate)
+	tokens.DELETE("/:id", tokenHandler.Revoke, authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+
+	// Admin routes
+	admin := auth.Group("/admin", authMiddleware.Authenticate, authMiddleware.RejectImpersonation)
+	manageUsers := authzMiddleware.RequirePermission(authz.UserManage)
+	admin.GET("/users", adminHandler.ListUsers, manageUsers)
+	admin.GET("/users/:id", adminHandler.GetUser, manageUsers)
+	admin.PUT("/users/:id/role", adminHandler.ChangeRole, authzMiddleware.RequirePermission(authz.UserRoleAssign))
+	admin.POST("/users/:id/password-reset", adminHandler.ForcePasswordReset, manageUsers)
+	admin.POST("/users/:id/disable", adminHandler.DisableUser, manageUsers)
+	admin.POST("/users/:id/enable", adminHandler.EnableUser, manageUsers)
+	admin.DELETE("/users/:id", adminHandler.DeleteUser, manageUsers)
+	admin.POST("/users/:id/unlock", adminHandler.UnlockUser, manageUsers)
+	admin.POST("/users/:id/impersonate", adminHandler.Impersonate, authzMiddleware.RequirePermission(authz.UserImpersonate))
+	readAudit := authzMiddleware.RequirePermission(authz.AuditRead)
+	admin.GET("/audit-events", auditHandler.ListEvents, readAudit)
+	admin.GET("/audit-events/export", auditHandler.ExportEvents, readAudit)
+	admin.POST("/tokens/revoke", introspectionHandler.AdminRevoke, authzMiddleware.RequirePermission(authz.TokenRevokeAny))
+	manageClients := authzMiddleware.RequirePermission(authz.OAuthClientManage)
+	admin.POST("/oauth/clients", oidcHandler.RegisterClient, manageClients)
+	admin.GET("/oauth/clients", oidcHandler.ListClients, manageClients)
+	admin.DELETE("/oauth/clients/:id", oidcHandler.DeleteClient, manageClients)
+
+	// Internal routes for personal data exports, called by the API gateway
+	exports := v1.Group("/internal/exports", authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserExport))
+	exports.GET("/:userID", exportHandler.Export)
 }
@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	domainrepository "github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/database"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/erasure"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/events"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/identity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/keys"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/mailer"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/outbox"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/password"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/servicetoken"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
 )
@@ -29,20 +41,144 @@ func main() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	tokenRepo := repository.NewTokenRepository(db)
+	resetTokenRepo := repository.NewPasswordResetTokenRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	personalAccessTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	oauthClientRepo := repository.NewOAuthClientRepository(db)
+	authorizationCodeRepo := repository.NewAuthorizationCodeRepository(db)
+	linkedIdentityRepo := repository.NewLinkedIdentityRepository(db)
+	magicLinkRepo := repository.NewMagicLinkTokenRepository(db)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(db)
+	auditEventRepo := repository.NewAuditEventRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	accountDeletionRepo := repository.NewAccountDeletionRepository(db)
+	emailChangeRepo := repository.NewEmailChangeRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Initialize login lockout store
+	var loginAttemptRepo domainrepository.LoginAttemptRepository
+	switch cfg.Lockout.Store {
+	case "memory":
+		loginAttemptRepo = repository.NewMemoryLoginAttemptRepository(cfg.Lockout.Window)
+	case "postgres":
+		loginAttemptRepo = repository.NewLoginAttemptRepository(db, cfg.Lockout.Window)
+	default:
+		log.Fatalf("Unsupported lockout store %q", cfg.Lockout.Store)
+	}
+
+	// Prune stale login attempts, expired denylist entries and delivered outbox messages
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := loginAttemptRepo.DeleteStale(context.Background(), time.Now()); err != nil {
+				log.Printf("failed to prune login attempts: %v", err)
+			}
+			if err := revokedTokenRepo.DeleteExpired(context.Background(), time.Now()); err != nil {
+				log.Printf("failed to prune revoked tokens: %v", err)
+			}
+			if err := outboxRepo.DeleteDelivered(context.Background(), time.Now().Add(-cfg.Outbox.Retention)); err != nil {
+				log.Printf("failed to prune outbox messages: %v", err)
+			}
+		}
+	}()
+
+	// Initialize signing keys
+	keyManager := keys.NewKeyManager(signingKeyRepo, cfg.JWT)
+	if err := keyManager.Init(context.Background()); err != nil {
+		log.Fatalf("Failed to initialize signing keys: %v", err)
+	}
+	go keyManager.Run(context.Background())
+
+	// Initialize tokens for calls to other services
+	serviceTokens := servicetoken.NewIssuer(cfg.OIDC.ServiceAudience, cfg.JWT.Issuer, keyManager)
+
+	// Relay account events to the user service
+	deliverer := outbox.NewHTTPDeliverer(cfg.Outbox.ConsumerURL, cfg.Outbox.ConsumerAudience, serviceTokens)
+	go outbox.NewRelay(outboxRepo, deliverer, cfg.Outbox).Run(context.Background())
+
+	// Initialize mailer
+	smtpMailer := mailer.NewSMTPMailer(cfg.SMTP)
+
+	// Initialize security event publisher, which logs events and records them in the audit log
+	eventPublisher := events.NewMultiPublisher(events.NewLogPublisher(), events.NewAuditPublisher(auditEventRepo))
+
+	// Initialize external identity providers
+	identityProviders := make(map[string]service.IdentityProvider, len(cfg.SocialLogin.Providers))
+	for name, providerConfig := range cfg.SocialLogin.Providers {
+		identityProviders[name] = identity.NewOIDCProvider(name, providerConfig)
+	}
+
+	// Initialize password hasher
+	passwordHasher, err := password.NewHasher(cfg.PasswordHash)
+	if err != nil {
+		log.Fatalf("Failed to initialize password hasher: %v", err)
+	}
+
+	// Initialize password policy, with the breached password filter when one is configured
+	var breachedPasswords service.BreachedPasswordChecker
+	if cfg.Password.BreachedPasswordsFile != "" {
+		filter, err := password.LoadBloomFilter(cfg.Password.BreachedPasswordsFile)
+		if err != nil {
+			log.Fatalf("Failed to load breached password filter: %v", err)
+		}
+		breachedPasswords = filter
+	}
+	passwordPolicy := password.NewPolicy(cfg.Password, breachedPasswords)
 
 	// Initialize use cases
-	authUseCase := usecases.NewAuthUseCase(userRepo, tokenRepo, cfg.JWT)
+	mfaUseCase := usecases.NewMFAUseCase(userRepo, recoveryCodeRepo, eventPublisher, cfg.MFA)
+	tokenUseCase := usecases.NewPersonalAccessTokenUseCase(personalAccessTokenRepo, userRepo, eventPublisher)
+	authUseCase := usecases.NewAuthUseCase(userRepo, tokenRepo, resetTokenRepo, loginAttemptRepo, outboxRepo, transactor, smtpMailer, eventPublisher, keyManager, passwordHasher, passwordPolicy, mfaUseCase, cfg.JWT, cfg.PasswordReset, cfg.Verification, cfg.Lockout)
+	oidcUseCase := usecases.NewOIDCUseCase(oauthClientRepo, authorizationCodeRepo, userRepo, authUseCase, keyManager, cfg.JWT, cfg.OIDC)
+	if gateway := cfg.OIDC.GatewayClient; gateway.ID != "" {
+		if err := oidcUseCase.EnsureServiceClient(context.Background(), gateway.ID, "API gateway", gateway.Secret, gateway.Audiences); err != nil {
+			log.Fatalf("Failed to register gateway client: %v", err)
+		}
+	}
+	socialUseCase := usecases.NewSocialLoginUseCase(identityProviders, linkedIdentityRepo, userRepo, authUseCase, cfg.SocialLogin)
+	magicLinkUseCase := usecases.NewMagicLinkUseCase(magicLinkRepo, userRepo, smtpMailer, authUseCase, cfg.MagicLink)
+	adminUseCase := usecases.NewAdminUseCase(userRepo, tokenRepo, personalAccessTokenRepo, linkedIdentityRepo, recoveryCodeRepo, resetTokenRepo, magicLinkRepo, emailChangeRepo, authUseCase, eventPublisher, cfg.Impersonation)
+	emailChangeUseCase := usecases.NewEmailChangeUseCase(emailChangeRepo, userRepo, tokenRepo, personalAccessTokenRepo, smtpMailer, authUseCase, cfg.EmailChange)
+	auditUseCase := usecases.NewAuditUseCase(auditEventRepo)
+	erasureParticipants := []service.ErasureParticipant{
+		erasure.NewHTTPParticipant("blog_service", cfg.Deletion.BlogServiceURL, cfg.Deletion.BlogServiceAudience, serviceTokens),
+		erasure.NewHTTPParticipant("user_service", cfg.Deletion.UserServiceURL, cfg.Deletion.UserServiceAudience, serviceTokens),
+	}
+	exportUseCase := usecases.NewDataExportUseCase(userRepo, linkedIdentityRepo, personalAccessTokenRepo, auditEventRepo, authUseCase)
+	accountDeletionUseCase := usecases.NewAccountDeletionUseCase(accountDeletionRepo, userRepo, tokenRepo, personalAccessTokenRepo, authUseCase, adminUseCase, erasureParticipants, cfg.Deletion)
+	introspectionUseCase := usecases.NewTokenIntrospectionUseCase(revokedTokenRepo, tokenRepo, authUseCase, oidcUseCase, keyManager, cfg.JWT)
+
+	// Advance account deletions whose grace period has ended
+	go func() {
+		for range time.Tick(cfg.Deletion.PollInterval) {
+			if _, err := accountDeletionUseCase.ProcessDue(context.Background()); err != nil {
+				log.Printf("failed to process account deletions: %v", err)
+			}
+		}
+	}()
 
 	// Create Echo instance
 	e := echo.New()
 
+	// Take client IPs, which login lockouts and rate limits are keyed by, from X-Forwarded-For
+	// only when the request came through a trusted proxy, so clients cannot pick their own
+	if len(cfg.ClientIP.TrustedProxies) == 0 {
+		e.IPExtractor = echo.ExtractIPDirect()
+	} else {
+		trust := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, ipRange := range cfg.ClientIP.TrustedProxies {
+			trust = append(trust, echo.TrustIPRange(ipRange))
+		}
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(trust...)
+	}
+
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
 	// Initialize API routes
-	http.RegisterRoutes(e, authUseCase)
+	http.RegisterRoutes(e, cfg, authUseCase, mfaUseCase, tokenUseCase, oidcUseCase, socialUseCase, magicLinkUseCase, adminUseCase, emailChangeUseCase, auditUseCase, accountDeletionUseCase, exportUseCase, introspectionUseCase, keyManager)
 
 	// Start server
 	port := os.Getenv("PORT")
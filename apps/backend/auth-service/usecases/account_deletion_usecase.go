@@ -0,0 +1,361 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+// accountDeletionBatchSize is how many deletions advance per poll
+const accountDeletionBatchSize = 10
+
+// ErrAccountDeletionScheduled is returned when a user asks to delete an account that is already being deleted
+var ErrAccountDeletionScheduled = errors.New("account deletion is already scheduled")
+
+// ErrAccountDeletionNotCancellable is returned when a deletion is cancelled after its grace period
+var ErrAccountDeletionNotCancellable = errors.New("account deletion can no longer be cancelled")
+
+// deletionStep is one step of the account deletion saga.
+// Steps with a compensation can be undone; the first step without one is the point of no return,
+// and it and the steps after it are retried until they succeed.
+type deletionStep struct {
+	name       string
+	execute    func(ctx context.Context, deletion *entity.AccountDeletion) error
+	compensate func(ctx context.Context, deletion *entity.AccountDeletion) error
+}
+
+// AccountDeletionUseCase implements users deleting their own accounts.
+// After a grace period in which the user can cancel, a saga locks the account, erases the user's data
+// from every participating service, deletes or anonymizes the account, then makes the erasures permanent.
+type AccountDeletionUseCase struct {
+	deletionRepo repository.AccountDeletionRepository
+	userRepo     repository.UserRepository
+	tokenRepo    repository.TokenRepository
+	patRepo      repository.PersonalAccessTokenRepository
+	authUseCase  *AuthUseCase
+	adminUseCase *AdminUseCase
+	config       config.AccountDeletionConfig
+	steps        []deletionStep
+}
+
+// NewAccountDeletionUseCase creates a new account deletion use case
+func NewAccountDeletionUseCase(
+	deletionRepo repository.AccountDeletionRepository,
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	patRepo repository.PersonalAccessTokenRepository,
+	authUseCase *AuthUseCase,
+	adminUseCase *AdminUseCase,
+	participants []service.ErasureParticipant,
+	config config.AccountDeletionConfig,
+) *AccountDeletionUseCase {
+	uc := &AccountDeletionUseCase{
+		deletionRepo: deletionRepo,
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		patRepo:      patRepo,
+		authUseCase:  authUseCase,
+		adminUseCase: adminUseCase,
+		config:       config,
+	}
+
+	// Lock the account, then erase the user's data everywhere while it can still be undone
+	uc.steps = append(uc.steps, deletionStep{name: "lock_account", execute: uc.lockAccount, compensate: uc.unlockAccount})
+	for _, participant := range participants {
+		participant := participant
+		uc.steps = append(uc.steps, deletionStep{
+			name: "erase_" + participant.Name(),
+			execute: func(ctx context.Context, deletion *entity.AccountDeletion) error {
+				return participant.Erase(ctx, deletion.ID, deletion.UserID)
+			},
+			compensate: func(ctx context.Context, deletion *entity.AccountDeletion) error {
+				return participant.Revert(ctx, deletion.ID)
+			},
+		})
+	}
+
+	// Erase the account, after which the erasures are made permanent
+	uc.steps = append(uc.steps, deletionStep{name: "erase_account", execute: uc.eraseAccount})
+	for _, participant := range participants {
+		participant := participant
+		uc.steps = append(uc.steps, deletionStep{
+			name: "finalize_" + participant.Name(),
+			execute: func(ctx context.Context, deletion *entity.AccountDeletion) error {
+				return participant.Finalize(ctx, deletion.ID)
+			},
+		})
+	}
+
+	return uc
+}
+
+// ScheduleDeletion schedules the deletion of a user's account after checking their password.
+// The deletion starts when the grace period ends.
+func (uc *AccountDeletionUseCase) ScheduleDeletion(ctx context.Context, userID, password string) (*entity.AccountDeletion, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify password
+	if !user.VerifyPassword(password, uc.authUseCase.hasher) {
+		return nil, errors.New("password is incorrect")
+	}
+
+	// Refuse a second deletion
+	if latest, err := uc.deletionRepo.FindLatestByUserID(ctx, userID); err == nil && latest.IsActive() {
+		return nil, ErrAccountDeletionScheduled
+	}
+
+	deletion, err := entity.NewAccountDeletion(uuid.New().String(), user.ID, uc.config.GracePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save deletion to database
+	if err := uc.deletionRepo.Create(ctx, deletion); err != nil {
+		return nil, err
+	}
+
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventAccountDeletionScheduled,
+		UserID:  user.ID,
+		Details: map[string]string{"deletion_id": deletion.ID, "scheduled_for": deletion.ScheduledFor.UTC().Format(time.RFC3339)},
+	})
+
+	// Let the user know how to change their mind
+	err = uc.authUseCase.mailer.Send(ctx, service.Email{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and everything you have published will be deleted on %s.\n\nIf you change your mind, sign in and cancel the deletion before then.\n",
+			user.Username, deletion.ScheduledFor.UTC().Format("January 2, 2006 15:04 MST")),
+	})
+	if err != nil {
+		log.Printf("failed to send account deletion notice to user %s: %v", user.ID, err)
+	}
+
+	return deletion, nil
+}
+
+// CancelDeletion cancels a user's account deletion during its grace period
+func (uc *AccountDeletionUseCase) CancelDeletion(ctx context.Context, userID string) (*entity.AccountDeletion, error) {
+	// Find deletion
+	deletion, err := uc.deletionRepo.FindLatestByUserID(ctx, userID)
+	if err != nil || !deletion.IsActive() {
+		return nil, errors.New("account deletion not found")
+	}
+
+	if err := deletion.Cancel(); err != nil {
+		return nil, ErrAccountDeletionNotCancellable
+	}
+
+	// Save deletion to database, unless a worker claimed it in the meantime
+	if err := uc.deletionRepo.MarkCancelled(ctx, deletion); err != nil {
+		return nil, ErrAccountDeletionNotCancellable
+	}
+
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventAccountDeletionCancelled,
+		UserID:  userID,
+		Details: map[string]string{"deletion_id": deletion.ID},
+	})
+	return deletion, nil
+}
+
+// GetDeletion returns a user's most recent account deletion
+func (uc *AccountDeletionUseCase) GetDeletion(ctx context.Context, userID string) (*entity.AccountDeletion, error) {
+	return uc.deletionRepo.FindLatestByUserID(ctx, userID)
+}
+
+// ProcessDue advances every deletion whose next step is due by one step and returns how many advanced.
+// Deletions are leased rather than locked, so no transaction is open while participants are called. Progress
+// is saved after the step only if no other worker has moved the deletion on, so a crash or an expired lease
+// repeats at most the step that was running; steps are idempotent so repeating them is safe.
+func (uc *AccountDeletionUseCase) ProcessDue(ctx context.Context) (int, error) {
+	deletions, err := uc.deletionRepo.ClaimDue(ctx, time.Now(), uc.config.Lease, accountDeletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	advanced := 0
+	for _, deletion := range deletions {
+		if uc.advance(ctx, deletion) {
+			advanced++
+		}
+	}
+	return advanced, nil
+}
+
+// advance runs or compensates the deletion's current step and records the outcome
+func (uc *AccountDeletionUseCase) advance(ctx context.Context, deletion *entity.AccountDeletion) bool {
+	step := uc.steps[deletion.Step]
+	claimedStep, claimedStatus := deletion.Step, deletion.Status
+	compensating := claimedStatus == entity.AccountDeletionCompensating
+
+	run := step.execute
+	if compensating {
+		run = step.compensate
+	}
+	err := run(ctx, deletion)
+
+	if err == nil {
+		deletion.StepSucceeded(len(uc.steps))
+	} else {
+		log.Printf("account deletion %s step %s failed (compensating: %t): %v", deletion.ID, step.name, compensating, err)
+		deletion.StepFailed(fmt.Errorf("%s: %w", step.name, err), uc.backoff(deletion.Attempts+1))
+
+		// Roll back when a step that can be undone keeps failing; compensations and later steps retry until they succeed
+		if !compensating && step.compensate != nil && deletion.Attempts >= uc.config.MaxAttempts {
+			deletion.Compensate()
+		}
+	}
+
+	// Save deletion to database
+	if err := uc.deletionRepo.UpdateProgress(ctx, deletion, claimedStep, claimedStatus); err != nil {
+		log.Printf("failed to save progress of account deletion %s: %v", deletion.ID, err)
+		return false
+	}
+
+	if err != nil {
+		return false
+	}
+	uc.publishOutcome(ctx, deletion)
+	return true
+}
+
+// publishOutcome publishes a security event once a deletion has completed or rolled back
+func (uc *AccountDeletionUseCase) publishOutcome(ctx context.Context, deletion *entity.AccountDeletion) {
+	event := service.SecurityEvent{
+		UserID:  deletion.UserID,
+		Details: map[string]string{"deletion_id": deletion.ID},
+	}
+	switch deletion.Status {
+	case entity.AccountDeletionCompleted:
+		event.Type = service.SecurityEventAccountDeleted
+	case entity.AccountDeletionRolledBack:
+		event.Type = service.SecurityEventAccountDeletionRolledBack
+		event.Outcome = service.SecurityEventFailure
+		event.Reason = deletion.LastError
+	default:
+		return
+	}
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, event)
+}
+
+// lockAccount disables the account and revokes its refresh tokens and personal access tokens, in one transaction
+func (uc *AccountDeletionUseCase) lockAccount(ctx context.Context, deletion *entity.AccountDeletion) error {
+	return uc.authUseCase.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return uc.lockUser(ctx, deletion)
+	})
+}
+
+// lockUser disables the account and revokes its refresh tokens and personal access tokens
+func (uc *AccountDeletionUseCase) lockUser(ctx context.Context, deletion *entity.AccountDeletion) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, deletion.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !user.Disabled {
+		user.Disable()
+
+		// Save user to database
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+	} else if deletion.Step == 0 && deletion.Attempts == 0 {
+		deletion.WasDisabled = true
+	}
+
+	// Revoke all refresh tokens and personal access tokens
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	return uc.patRepo.DeleteByUserID(ctx, user.ID)
+}
+
+// unlockAccount enables the account again, unless it was disabled before the deletion
+func (uc *AccountDeletionUseCase) unlockAccount(ctx context.Context, deletion *entity.AccountDeletion) error {
+	if deletion.WasDisabled {
+		return nil
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, deletion.UserID)
+	if err != nil {
+		return err
+	}
+
+	user.Enable()
+
+	// Save user to database
+	return uc.userRepo.Update(ctx, user)
+}
+
+// eraseAccount deletes or anonymizes the user, as configured, and records the UserDeleted event, in one transaction
+func (uc *AccountDeletionUseCase) eraseAccount(ctx context.Context, deletion *entity.AccountDeletion) error {
+	return uc.authUseCase.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return uc.eraseUser(ctx, deletion)
+	})
+}
+
+// eraseUser deletes or anonymizes the user, as configured, and records the UserDeleted event
+func (uc *AccountDeletionUseCase) eraseUser(ctx context.Context, deletion *entity.AccountDeletion) error {
+	// Find user; an administrator may have deleted it in the meantime
+	user, err := uc.userRepo.FindByID(ctx, deletion.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if uc.config.Mode != "anonymize" {
+		return uc.adminUseCase.deleteAccount(ctx, user)
+	}
+
+	if err := uc.adminUseCase.purgeCredentials(ctx, user.ID); err != nil {
+		return err
+	}
+
+	randomPassword, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	if err := user.Anonymize(randomPassword, uc.authUseCase.hasher); err != nil {
+		return err
+	}
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Tell other services to delete the user's data
+	return uc.authUseCase.recordAccountEvent(ctx, accountevents.UserDeletedType, user.ID, accountevents.UserDeleted{
+		UserID: user.ID,
+	})
+}
+
+// backoff returns the delay before an attempt: the base delay doubling with each attempt up to the maximum
+func (uc *AccountDeletionUseCase) backoff(attempt int) time.Duration {
+	delay := uc.config.RetryBaseDelay
+	for i := 1; i < attempt && delay < uc.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > uc.config.RetryMaxDelay {
+		delay = uc.config.RetryMaxDelay
+	}
+	return delay
+}
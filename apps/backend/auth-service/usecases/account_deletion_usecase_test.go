@@ -0,0 +1,256 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/repository"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+type deletionTest struct {
+	t           *testing.T
+	deletion    *AccountDeletionUseCase
+	deletions   *fakeAccountDeletionRepository
+	users       *fakeUserRepository
+	outbox      *fakeOutboxRepository
+	events      *fakeEventPublisher
+	participant *fakeErasureParticipant
+}
+
+func newDeletionTest(t *testing.T, gracePeriod time.Duration) *deletionTest {
+	t.Helper()
+
+	user, err := entity.NewUser("user-1", "alice@example.com", "alice", testPassword, fakeHasher{})
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+
+	test := &deletionTest{
+		t:           t,
+		deletions:   newFakeAccountDeletionRepository(),
+		users:       newFakeUserRepository(user),
+		outbox:      &fakeOutboxRepository{},
+		events:      &fakeEventPublisher{},
+		participant: &fakeErasureParticipant{},
+	}
+	tokens := newFakeTokenRepository()
+	pats := newFakePersonalAccessTokenRepository()
+	auth := NewAuthUseCase(
+		test.users,
+		tokens,
+		newFakePasswordResetTokenRepository(),
+		repository.NewMemoryLoginAttemptRepository(24*time.Hour),
+		test.outbox,
+		fakeTransactor{},
+		&fakeMailer{},
+		test.events,
+		fakeSigner{},
+		fakeHasher{},
+		nil,
+		nil,
+		config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 24 * time.Hour, Issuer: "test"},
+		config.PasswordResetConfig{},
+		config.EmailVerificationConfig{},
+		config.LockoutConfig{},
+	)
+	admin := NewAdminUseCase(
+		test.users,
+		tokens,
+		pats,
+		newFakeLinkedIdentityRepository(),
+		newFakeRecoveryCodeRepository(),
+		newFakePasswordResetTokenRepository(),
+		newFakeMagicLinkTokenRepository(),
+		newFakeEmailChangeRepository(),
+		auth,
+		test.events,
+		config.ImpersonationConfig{},
+	)
+	test.deletion = NewAccountDeletionUseCase(
+		test.deletions,
+		test.users,
+		tokens,
+		pats,
+		auth,
+		admin,
+		[]service.ErasureParticipant{test.participant},
+		config.AccountDeletionConfig{
+			GracePeriod:    gracePeriod,
+			Mode:           "delete",
+			MaxAttempts:    2,
+			RetryBaseDelay: time.Nanosecond,
+			RetryMaxDelay:  time.Nanosecond,
+			Lease:          time.Minute,
+		},
+	)
+	return test
+}
+
+// schedule schedules the deletion of the test user's account
+func (test *deletionTest) schedule() *entity.AccountDeletion {
+	test.t.Helper()
+
+	deletion, err := test.deletion.ScheduleDeletion(context.Background(), "user-1", testPassword)
+	if err != nil {
+		test.t.Fatalf("ScheduleDeletion: %v", err)
+	}
+	return deletion
+}
+
+// run processes due deletions until the deletion has finished and returns it
+func (test *deletionTest) run() *entity.AccountDeletion {
+	test.t.Helper()
+
+	for i := 0; i < 20; i++ {
+		if _, err := test.deletion.ProcessDue(context.Background()); err != nil {
+			test.t.Fatalf("ProcessDue: %v", err)
+		}
+		deletion, err := test.deletion.GetDeletion(context.Background(), "user-1")
+		if err != nil {
+			test.t.Fatalf("GetDeletion: %v", err)
+		}
+		if !deletion.IsActive() {
+			return deletion
+		}
+		time.Sleep(time.Millisecond)
+	}
+	test.t.Fatal("account deletion did not finish")
+	return nil
+}
+
+// hasEvent checks if a security event of the type was published
+func (test *deletionTest) hasEvent(eventType service.SecurityEventType) bool {
+	for _, event := range test.events.events {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAccountDeletionErasesAccountEverywhere(t *testing.T) {
+	test := newDeletionTest(t, 0)
+	test.schedule()
+
+	deletion := test.run()
+	if deletion.Status != entity.AccountDeletionCompleted || deletion.CompletedAt == nil {
+		t.Fatalf("got status %q, want completed", deletion.Status)
+	}
+
+	// The participant erased the user's data and then made the erasure permanent
+	if len(test.participant.erased) != 1 || len(test.participant.finalized) != 1 || len(test.participant.reverted) != 0 {
+		t.Errorf("participant erased %v, finalized %v and reverted %v", test.participant.erased, test.participant.finalized, test.participant.reverted)
+	}
+
+	// The account is deleted and other services are told
+	if _, err := test.users.FindByID(context.Background(), "user-1"); err == nil {
+		t.Error("the user still exists")
+	}
+	if len(test.outbox.messages) != 1 || test.outbox.messages[0].Type != string(accountevents.UserDeletedType) {
+		t.Errorf("got outbox messages %v, want one UserDeleted", test.outbox.messages)
+	}
+	if !test.hasEvent(service.SecurityEventAccountDeleted) {
+		t.Error("deleting the account published no account_deleted event")
+	}
+}
+
+func TestAccountDeletionRollsBackWhenErasureKeepsFailing(t *testing.T) {
+	test := newDeletionTest(t, 0)
+	test.participant.err = errors.New("blog service unavailable")
+	test.schedule()
+
+	deletion := test.run()
+	if deletion.Status != entity.AccountDeletionRolledBack {
+		t.Fatalf("got status %q, want rolled_back", deletion.Status)
+	}
+
+	// The failed erasure is reverted too, as it may have taken effect, and nothing is finalized
+	if len(test.participant.reverted) != 1 || len(test.participant.finalized) != 0 {
+		t.Errorf("participant reverted %v and finalized %v", test.participant.reverted, test.participant.finalized)
+	}
+
+	// The account is unlocked and kept
+	user, err := test.users.FindByID(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if user.Disabled {
+		t.Error("the rolled back account is still disabled")
+	}
+	if len(test.outbox.messages) != 0 {
+		t.Errorf("got outbox messages %v, want none", test.outbox.messages)
+	}
+	if !test.hasEvent(service.SecurityEventAccountDeletionRolledBack) {
+		t.Error("rolling back published no account_deletion_rolled_back event")
+	}
+}
+
+func TestAccountDeletionRollbackKeepsDisabledAccountDisabled(t *testing.T) {
+	test := newDeletionTest(t, 0)
+	test.participant.err = errors.New("blog service unavailable")
+
+	user, err := test.users.FindByID(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	user.Disable()
+	if err := test.users.Update(context.Background(), user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	test.schedule()
+
+	if deletion := test.run(); deletion.Status != entity.AccountDeletionRolledBack {
+		t.Fatalf("got status %q, want rolled_back", deletion.Status)
+	}
+	user, err = test.users.FindByID(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !user.Disabled {
+		t.Error("rolling back enabled an account that was disabled before the deletion")
+	}
+}
+
+func TestAccountDeletionCancelledDuringGracePeriod(t *testing.T) {
+	test := newDeletionTest(t, time.Hour)
+	test.schedule()
+
+	deletion, err := test.deletion.CancelDeletion(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("CancelDeletion: %v", err)
+	}
+	if deletion.Status != entity.AccountDeletionCancelled {
+		t.Fatalf("got status %q, want cancelled", deletion.Status)
+	}
+	if !test.hasEvent(service.SecurityEventAccountDeletionCancelled) {
+		t.Error("cancelling published no account_deletion_cancelled event")
+	}
+
+	// A cancelled deletion is never claimed
+	if advanced, err := test.deletion.ProcessDue(context.Background()); err != nil || advanced != 0 {
+		t.Fatalf("ProcessDue advanced %d deletions: %v", advanced, err)
+	}
+}
+
+func TestAccountDeletionNotCancellableOnceClaimed(t *testing.T) {
+	test := newDeletionTest(t, 0)
+	test.schedule()
+
+	// The first step has run, ending the grace period
+	if _, err := test.deletion.ProcessDue(context.Background()); err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+
+	if _, err := test.deletion.CancelDeletion(context.Background(), "user-1"); !errors.Is(err, ErrAccountDeletionNotCancellable) {
+		t.Fatalf("got %v, want ErrAccountDeletionNotCancellable", err)
+	}
+	if deletion := test.run(); deletion.Status != entity.AccountDeletionCompleted {
+		t.Fatalf("got status %q, want completed", deletion.Status)
+	}
+}
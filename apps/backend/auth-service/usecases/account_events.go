@@ -0,0 +1,34 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+// recordAccountEvent adds an account event to the outbox, to be relayed to other services.
+// Call it within the transaction that makes the change the event describes.
+func (uc *AuthUseCase) recordAccountEvent(ctx context.Context, eventType accountevents.Type, userID string, payload interface{}) error {
+	message, err := entity.NewOutboxMessage(uuid.New().String(), string(eventType), userID, payload)
+	if err != nil {
+		return err
+	}
+	return uc.outboxRepo.Create(ctx, message)
+}
+
+// createUser saves a new user and records the UserRegistered event in one transaction
+func (uc *AuthUseCase) createUser(ctx context.Context, user *entity.User) error {
+	return uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+
+		return uc.recordAccountEvent(ctx, accountevents.UserRegisteredType, user.ID, accountevents.UserRegistered{
+			UserID:   user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+		})
+	})
+}
@@ -0,0 +1,344 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+// maxUserSearchLimit caps the page size of a user search
+const maxUserSearchLimit = 100
+
+// ErrSelfAdministration is returned when an admin tries to demote, disable or delete their own account
+var ErrSelfAdministration = errors.New("admins cannot change the role of, disable or delete their own account")
+
+// ErrImpersonationNotAllowed is returned when the target of an impersonation cannot be impersonated
+var ErrImpersonationNotAllowed = errors.New("this user cannot be impersonated")
+
+// AdminUseCase implements administrative user management use cases.
+// Every change is published as a security event that records the acting admin.
+type AdminUseCase struct {
+	userRepo         repository.UserRepository
+	tokenRepo        repository.TokenRepository
+	patRepo          repository.PersonalAccessTokenRepository
+	identityRepo     repository.LinkedIdentityRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+	resetTokenRepo   repository.PasswordResetTokenRepository
+	magicLinkRepo    repository.MagicLinkTokenRepository
+	emailChangeRepo  repository.EmailChangeRepository
+	authUseCase      *AuthUseCase
+	eventPublisher   service.SecurityEventPublisher
+	impersonation    config.ImpersonationConfig
+}
+
+// NewAdminUseCase creates a new admin use case
+func NewAdminUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	patRepo repository.PersonalAccessTokenRepository,
+	identityRepo repository.LinkedIdentityRepository,
+	recoveryCodeRepo repository.RecoveryCodeRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	magicLinkRepo repository.MagicLinkTokenRepository,
+	emailChangeRepo repository.EmailChangeRepository,
+	authUseCase *AuthUseCase,
+	eventPublisher service.SecurityEventPublisher,
+	impersonation config.ImpersonationConfig,
+) *AdminUseCase {
+	return &AdminUseCase{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		patRepo:          patRepo,
+		identityRepo:     identityRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		resetTokenRepo:   resetTokenRepo,
+		magicLinkRepo:    magicLinkRepo,
+		emailChangeRepo:  emailChangeRepo,
+		authUseCase:      authUseCase,
+		eventPublisher:   eventPublisher,
+		impersonation:    impersonation,
+	}
+}
+
+// SearchUsers finds users whose email or username contains the query and returns the total number of matches
+func (uc *AdminUseCase) SearchUsers(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxUserSearchLimit {
+		limit = maxUserSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return uc.userRepo.Search(ctx, query, limit, offset)
+}
+
+// GetUser returns a single user
+func (uc *AdminUseCase) GetUser(ctx context.Context, userID string) (*entity.User, error) {
+	return uc.userRepo.FindByID(ctx, userID)
+}
+
+// ChangeRole changes a user's role. Admins must enroll in MFA at their next login.
+func (uc *AdminUseCase) ChangeRole(ctx context.Context, actorID, userID string, role valueobject.UserRole) (*entity.User, error) {
+	if actorID == userID {
+		return nil, ErrSelfAdministration
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.Role
+	if err := user.ChangeRole(role); err != nil {
+		return nil, err
+	}
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminRoleChanged, actorID, user.ID, map[string]string{
+		"previous_role": string(previous),
+		"role":          string(user.Role),
+	})
+	return user, nil
+}
+
+// ForcePasswordReset replaces a user's password with a random one, revokes their sessions and emails a reset link
+func (uc *AdminUseCase) ForcePasswordReset(ctx context.Context, actorID, userID string) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Lock out the current password
+	randomPassword, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	if err := user.ChangePassword(randomPassword, uc.authUseCase.hasher); err != nil {
+		return err
+	}
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Revoke all refresh tokens
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminPasswordResetForced, actorID, user.ID, nil)
+
+	// Send reset link
+	return uc.authUseCase.sendPasswordReset(ctx, user)
+}
+
+// DisableUser prevents a user from logging in and revokes their sessions and personal access tokens.
+// Access tokens already issued stay valid until they expire.
+func (uc *AdminUseCase) DisableUser(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return ErrSelfAdministration
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Disabled {
+		return nil
+	}
+	user.Disable()
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Revoke all refresh tokens and personal access tokens
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := uc.patRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminUserDisabled, actorID, user.ID, nil)
+	return nil
+}
+
+// EnableUser lets a disabled user log in again
+func (uc *AdminUseCase) EnableUser(ctx context.Context, actorID, userID string) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.Disabled {
+		return nil
+	}
+	user.Enable()
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminUserEnabled, actorID, user.ID, nil)
+	return nil
+}
+
+// DeleteUser deletes a user's account along with their credentials and sessions
+func (uc *AdminUseCase) DeleteUser(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return ErrSelfAdministration
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.deleteAccount(ctx, user); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminUserDeleted, actorID, user.ID, map[string]string{
+		"email":    user.Email,
+		"username": user.Username,
+	})
+	return nil
+}
+
+// deleteAccount deletes a user along with their credentials and records the UserDeleted event, in one transaction
+func (uc *AdminUseCase) deleteAccount(ctx context.Context, user *entity.User) error {
+	return uc.authUseCase.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.purgeCredentials(ctx, user.ID); err != nil {
+			return err
+		}
+
+		// Delete user from database
+		if err := uc.userRepo.Delete(ctx, user.ID); err != nil {
+			return err
+		}
+
+		// Tell other services to delete the user's data
+		return uc.authUseCase.recordAccountEvent(ctx, accountevents.UserDeletedType, user.ID, accountevents.UserDeleted{
+			UserID: user.ID,
+		})
+	})
+}
+
+// purgeCredentials deletes everything that lets an account sign in
+func (uc *AdminUseCase) purgeCredentials(ctx context.Context, userID string) error {
+	cleanups := []func(context.Context, string) error{
+		uc.tokenRepo.DeleteByUserID,
+		uc.patRepo.DeleteByUserID,
+		uc.identityRepo.DeleteByUserID,
+		uc.recoveryCodeRepo.DeleteByUserID,
+		uc.resetTokenRepo.DeleteByUserID,
+		uc.magicLinkRepo.DeleteByUserID,
+		uc.emailChangeRepo.DeleteByUserID,
+	}
+	for _, cleanup := range cleanups {
+		if err := cleanup(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnlockUser lifts the login lockout on a user's account
+func (uc *AdminUseCase) UnlockUser(ctx context.Context, actorID, userID string) error {
+	if err := uc.authUseCase.UnlockUser(ctx, userID); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminUserUnlocked, actorID, userID, nil)
+	return nil
+}
+
+// Impersonate issues a short-lived access token that acts as a user on behalf of an admin.
+// The token's act claim names the admin, and it comes without a refresh token.
+func (uc *AdminUseCase) Impersonate(ctx context.Context, actorID, userID string) (*dto.ImpersonationResponse, error) {
+	if actorID == userID {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Admins are never impersonated so impersonation cannot borrow another admin's identity
+	if user.Disabled || user.Role == valueobject.RoleAdmin {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	// Create claims
+	now := time.Now()
+	expiresAt := now.Add(uc.impersonation.TokenTTL)
+	jti := uuid.New().String()
+	claims := map[string]interface{}{
+		"sub":       user.ID,
+		"aud":       uc.authUseCase.jwtConfig.Audiences,
+		"exp":       expiresAt.Unix(),
+		"iat":       now.Unix(),
+		"iss":       uc.authUseCase.jwtConfig.Issuer,
+		"role":      user.Role,
+		"verified":  user.Verified,
+		"jti":       jti,
+		"act":       map[string]interface{}{"sub": actorID},
+		"token_use": authz.TokenUseAccess,
+	}
+
+	// Sign token
+	accessToken, err := uc.authUseCase.signer.Sign(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, service.SecurityEventAdminImpersonationStarted, actorID, user.ID, map[string]string{
+		"jti":        jti,
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+	})
+
+	return &dto.ImpersonationResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(uc.impersonation.TokenTTL.Seconds()),
+		UserID:      user.ID,
+	}, nil
+}
+
+// audit publishes a security event recording an admin action
+func (uc *AdminUseCase) audit(ctx context.Context, eventType service.SecurityEventType, actorID, userID string, details map[string]string) {
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    eventType,
+		UserID:  userID,
+		ActorID: actorID,
+		Details: details,
+	})
+}
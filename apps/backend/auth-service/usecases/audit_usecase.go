@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+)
+
+// maxAuditEventLimit caps the page size of an audit log query
+const maxAuditEventLimit = 500
+
+// AuditUseCase implements querying and exporting the security audit log
+type AuditUseCase struct {
+	auditRepo repository.AuditEventRepository
+}
+
+// NewAuditUseCase creates a new audit use case
+func NewAuditUseCase(auditRepo repository.AuditEventRepository) *AuditUseCase {
+	return &AuditUseCase{
+		auditRepo: auditRepo,
+	}
+}
+
+// FindEvents finds a page of audit events matching the filter, newest first, and returns the total number of matches
+func (uc *AuditUseCase) FindEvents(ctx context.Context, filter repository.AuditEventFilter, limit, offset int) ([]*entity.AuditEvent, int64, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > maxAuditEventLimit {
+		limit = maxAuditEventLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return uc.auditRepo.Find(ctx, filter, limit, offset)
+}
+
+// ExportEvents calls fn for every audit event matching the filter, oldest first
+func (uc *AuditUseCase) ExportEvents(ctx context.Context, filter repository.AuditEventFilter, fn func(*entity.AuditEvent) error) error {
+	return uc.auditRepo.Each(ctx, filter, fn)
+}
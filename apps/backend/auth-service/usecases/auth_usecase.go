@@ -2,29 +2,104 @@ package usecases
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
 	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
 )
 
 // AuthUseCase implements authentication use cases
 type AuthUseCase struct {
-	userRepo  repository.UserRepository
-	tokenRepo repository.TokenRepository
-	jwtConfig config.JWTConfig
+	userRepo            repository.UserRepository
+	tokenRepo           repository.TokenRepository
+	resetTokenRepo      repository.PasswordResetTokenRepository
+	loginAttemptRepo    repository.LoginAttemptRepository
+	outboxRepo          repository.OutboxRepository
+	transactor          repository.Transactor
+	mailer              service.Mailer
+	eventPublisher      service.SecurityEventPublisher
+	signer              service.TokenSigner
+	hasher              service.PasswordHasher
+	passwordPolicy      service.PasswordPolicy
+	mfa                 *MFAUseCase
+	jwtConfig           config.JWTConfig
+	passwordResetConfig config.PasswordResetConfig
+	verificationConfig  config.EmailVerificationConfig
+	lockoutConfig       config.LockoutConfig
+
+	// dummyPasswordHash is verified against when the email is unknown
+	dummyPasswordHash string
 }
 
+// ErrVerificationRateLimited is returned when a verification email is requested too often
+var ErrVerificationRateLimited = errors.New("verification email was sent recently, please try again later")
+
+// ErrMFAAttemptsExhausted is returned when an MFA challenge was answered wrongly too often
+var ErrMFAAttemptsExhausted = errors.New("too many invalid two-factor authentication codes, please log in again")
+
+// ErrAccountDisabled is returned when a disabled user tries to log in or refresh tokens
+var ErrAccountDisabled = errors.New("account is disabled")
+
 // NewAuthUseCase creates a new auth use case
-func NewAuthUseCase(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, jwtConfig config.JWTConfig) *AuthUseCase {
+func NewAuthUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	outboxRepo repository.OutboxRepository,
+	transactor repository.Transactor,
+	mailer service.Mailer,
+	eventPublisher service.SecurityEventPublisher,
+	signer service.TokenSigner,
+	hasher service.PasswordHasher,
+	passwordPolicy service.PasswordPolicy,
+	mfa *MFAUseCase,
+	jwtConfig config.JWTConfig,
+	passwordResetConfig config.PasswordResetConfig,
+	verificationConfig config.EmailVerificationConfig,
+	lockoutConfig config.LockoutConfig,
+) *AuthUseCase {
+	dummyPasswordHash, err := hasher.Hash(dummyPassword)
+	if err != nil {
+		log.Printf("failed to hash dummy password: %v", err)
+	}
+
 	return &AuthUseCase{
-		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
-		jwtConfig: jwtConfig,
+		userRepo:            userRepo,
+		tokenRepo:           tokenRepo,
+		resetTokenRepo:      resetTokenRepo,
+		loginAttemptRepo:    loginAttemptRepo,
+		outboxRepo:          outboxRepo,
+		transactor:          transactor,
+		mailer:              mailer,
+		eventPublisher:      eventPublisher,
+		signer:              signer,
+		hasher:              hasher,
+		passwordPolicy:      passwordPolicy,
+		dummyPasswordHash:   dummyPasswordHash,
+		mfa:                 mfa,
+		jwtConfig:           jwtConfig,
+		passwordResetConfig: passwordResetConfig,
+		verificationConfig:  verificationConfig,
+		lockoutConfig:       lockoutConfig,
 	}
 }
 
@@ -33,57 +108,276 @@ func (uc *AuthUseCase) Register(ctx context.Context, email, username, password s
 	// Check if email already exists
 	_, err := uc.userRepo.FindByEmail(ctx, email)
 	if err == nil {
+		uc.registrationFailed(ctx, email, "email_exists")
 		return nil, errors.New("email already exists")
 	}
 
 	// Check if username already exists
 	_, err = uc.userRepo.FindByUsername(ctx, username)
 	if err == nil {
+		uc.registrationFailed(ctx, email, "username_exists")
 		return nil, errors.New("username already exists")
 	}
 
+	// Check password policy
+	if err := uc.checkPasswordPolicy(password, email, username); err != nil {
+		uc.registrationFailed(ctx, email, "password_policy")
+		return nil, err
+	}
+
 	// Create new user
 	id := uuid.New().String()
-	user, err := entity.NewUser(id, email, username, password)
+	user, err := entity.NewUser(id, email, username, password, uc.hasher)
 	if err != nil {
 		return nil, err
 	}
 
-	// Save user to database
-	if err := uc.userRepo.Create(ctx, user); err != nil {
+	// Save user to database along with the event that provisions their profile
+	if err := uc.createUser(ctx, user); err != nil {
 		return nil, err
 	}
 
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventRegister,
+		UserID:  user.ID,
+		Details: map[string]string{"email": user.Email, "method": "password"},
+	})
+
+	// Send verification link; the user can request a new one if this fails
+	if err := uc.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
-// Login authenticates a user and returns tokens
-func (uc *AuthUseCase) Login(ctx context.Context, email, password string) (*dto.TokenResponse, error) {
-	// Find user by email
+// Login authenticates a user and returns tokens.
+// Users with MFA, or admins who must enroll, get an MFA token instead.
+func (uc *AuthUseCase) Login(ctx context.Context, email, password string, client valueobject.ClientInfo) (*dto.LoginResponse, error) {
+	now := time.Now()
+	accountKey, ipKey := lockoutKeys(email, client)
+
+	// Refuse while the account or client IP is locked out
+	if err := uc.checkLockout(ctx, now, accountKey, ipKey); err != nil {
+		uc.loginFailed(ctx, "", email, "locked_out", client)
+		return nil, err
+	}
+
+	// Find user by email, checking a dummy hash for unknown emails so timing does not reveal them
 	user, err := uc.userRepo.FindByEmail(ctx, email)
 	if err != nil {
+		_, _ = uc.hasher.Verify(uc.dummyPasswordHash, password)
+		uc.loginFailed(ctx, "", email, "unknown_email", client)
+		uc.recordLoginFailure(ctx, now, nil, email, client)
 		return nil, errors.New("invalid email or password")
 	}
 
 	// Verify password
-	if !user.VerifyPassword(password) {
+	if !user.VerifyPassword(password, uc.hasher) {
+		uc.loginFailed(ctx, user.ID, email, "invalid_password", client)
+		uc.recordLoginFailure(ctx, now, user, email, client)
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Upgrade hashes made with an outdated algorithm or parameters; the old hash keeps working on failure
+	if user.PasswordNeedsRehash(uc.hasher) {
+		if err := user.ChangePassword(password, uc.hasher); err != nil {
+			log.Printf("failed to rehash password for user %s: %v", user.ID, err)
+		} else if err := uc.userRepo.Update(ctx, user); err != nil {
+			log.Printf("failed to save rehashed password for user %s: %v", user.ID, err)
+		}
+	}
+
+	// Forget earlier failures for the account
+	if err := uc.loginAttemptRepo.Reset(ctx, accountKey); err != nil {
+		log.Printf("failed to reset login failures for %s: %v", accountKey, err)
+	}
+
+	return uc.completeLogin(ctx, user, "password", client)
+}
+
+// completeLogin finishes a login for a user whose first factor, named by method, has been checked.
+// Users with MFA, or admins who must enroll, get an MFA token instead of tokens.
+func (uc *AuthUseCase) completeLogin(ctx context.Context, user *entity.User, method string, client valueobject.ClientInfo) (*dto.LoginResponse, error) {
+	// Refuse disabled accounts
+	if user.Disabled {
+		uc.loginFailed(ctx, user.ID, user.Email, "account_disabled", client)
+		return nil, ErrAccountDisabled
+	}
+
+	// Refuse unverified accounts when required
+	if uc.verificationConfig.RequireVerified && !user.Verified {
+		uc.loginFailed(ctx, user.ID, user.Email, "email_not_verified", client)
+		return nil, errors.New("email address is not verified")
+	}
+
+	// Require a second factor
+	if user.MFAEnabled || user.RequiresMFAEnrollment() {
+		purpose := mfaChallengeLogin
+		if !user.MFAEnabled {
+			purpose = mfaChallengeEnrollment
+		}
+
+		mfaToken, err := uc.mfa.IssueChallenge(user, purpose)
+		if err != nil {
+			return nil, err
+		}
+
+		return &dto.LoginResponse{
+			MFARequired:           user.MFAEnabled,
+			MFAEnrollmentRequired: !user.MFAEnabled,
+			MFAToken:              mfaToken,
+			MFAExpiresIn:          int(uc.mfa.mfaConfig.ChallengeTTL.Seconds()),
+		}, nil
+	}
+
 	// Generate tokens
-	return uc.generateTokens(ctx, user)
+	tokens, err := uc.generateTokens(ctx, user, nil, client)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.loginSucceeded(ctx, user, method, client)
+	return &dto.LoginResponse{TokenResponse: tokens}, nil
 }
 
-// RefreshToken refreshes an access token using a refresh token
-func (uc *AuthUseCase) RefreshToken(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
+// VerifyMFA completes a login with a TOTP or recovery code.
+// A challenge is rejected after too many wrong codes, and wrong codes count towards the login lockout.
+func (uc *AuthUseCase) VerifyMFA(ctx context.Context, mfaToken, code string, client valueobject.ClientInfo) (*dto.TokenResponse, error) {
+	// Find user the challenge was issued to
+	user, challengeID, err := uc.mfa.parseChallenge(ctx, mfaToken, mfaChallengeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse accounts disabled since the first factor was checked
+	if user.Disabled {
+		uc.loginFailed(ctx, user.ID, user.Email, "account_disabled", client)
+		return nil, ErrAccountDisabled
+	}
+
+	now := uc.mfa.now()
+	challengeKey, userKey := mfaAttemptKeys(challengeID, user.ID)
+	accountKey, ipKey := lockoutKeys(user.Email, client)
+
+	// Refuse challenges that used up their attempts
+	attempt, err := uc.loginAttemptRepo.FindByKey(ctx, challengeKey)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Failures >= uc.mfa.mfaConfig.MaxAttempts {
+		uc.loginFailed(ctx, user.ID, user.Email, "mfa_attempts_exhausted", client)
+		return nil, ErrMFAAttemptsExhausted
+	}
+
+	// Refuse while the user's second factor, the account or the client IP is locked out
+	if err := uc.checkLockout(ctx, now, userKey, accountKey, ipKey); err != nil {
+		uc.loginFailed(ctx, user.ID, user.Email, "locked_out", client)
+		return nil, err
+	}
+
+	// Verify second factor
+	if err := uc.mfa.VerifyCode(ctx, user, code); err != nil {
+		uc.loginFailed(ctx, user.ID, user.Email, "invalid_mfa_code", client)
+		uc.recordMFAFailure(ctx, now, user, challengeKey, userKey, client)
+		return nil, err
+	}
+
+	// Forget earlier wrong codes of the user
+	for _, key := range []string{userKey, accountKey} {
+		if err := uc.loginAttemptRepo.Reset(ctx, key); err != nil {
+			log.Printf("failed to reset login failures for %s: %v", key, err)
+		}
+	}
+
+	// Generate tokens
+	tokens, err := uc.generateTokens(ctx, user, nil, client)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.loginSucceeded(ctx, user, "mfa", client)
+	return tokens, nil
+}
+
+// BeginMFAEnrollment starts the MFA enrollment that is required to complete a login
+func (uc *AuthUseCase) BeginMFAEnrollment(ctx context.Context, mfaToken string) (*dto.MFAEnrollResponse, error) {
+	// Find user the challenge was issued to
+	user, err := uc.mfa.ParseChallenge(ctx, mfaToken, mfaChallengeEnrollment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse accounts disabled since the first factor was checked
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	return uc.mfa.Enroll(ctx, user.ID)
+}
+
+// ConfirmMFAEnrollment confirms the MFA enrollment that is required to complete a login and returns tokens
+func (uc *AuthUseCase) ConfirmMFAEnrollment(ctx context.Context, mfaToken, code string, client valueobject.ClientInfo) (*dto.MFAConfirmResponse, error) {
+	// Find user the challenge was issued to
+	user, err := uc.mfa.ParseChallenge(ctx, mfaToken, mfaChallengeEnrollment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse accounts disabled since the first factor was checked
+	if user.Disabled {
+		uc.loginFailed(ctx, user.ID, user.Email, "account_disabled", client)
+		return nil, ErrAccountDisabled
+	}
+
+	// Activate MFA
+	recoveryCodes, err := uc.mfa.Confirm(ctx, user.ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate tokens
+	tokens, err := uc.generateTokens(ctx, user, nil, client)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.loginSucceeded(ctx, user, "mfa_enrollment", client)
+	return &dto.MFAConfirmResponse{RecoveryCodes: recoveryCodes, TokenResponse: tokens}, nil
+}
+
+// RefreshToken rotates a refresh token, issuing new access and refresh tokens.
+// Presenting a token that was already rotated revokes its whole token family.
+func (uc *AuthUseCase) RefreshToken(ctx context.Context, refreshToken string, client valueobject.ClientInfo) (*dto.TokenResponse, error) {
+	return uc.refreshTokenForClient(ctx, refreshToken, "", client)
+}
+
+// refreshTokenForClient rotates a refresh token issued to an OAuth client, or to the service's own apps
+// when the client ID is empty. Tokens issued to anyone else are refused.
+func (uc *AuthUseCase) refreshTokenForClient(ctx context.Context, refreshToken, oauthClientID string, client valueobject.ClientInfo) (*dto.TokenResponse, error) {
 	// Find token in database
-	token, err := uc.tokenRepo.FindByToken(ctx, refreshToken)
+	token, err := uc.tokenRepo.FindByTokenHash(ctx, hashToken(refreshToken))
 	if err != nil {
+		uc.refreshFailed(ctx, nil, "unknown_token", client)
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// The token only refreshes for the client it was issued to
+	if token.OAuthClientID != oauthClientID {
+		uc.refreshFailed(ctx, token, "wrong_client", client)
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Detect reuse of a rotated token
+	if token.IsRotated() {
+		uc.refreshFailed(ctx, token, "token_reuse", client)
+		uc.revokeTokenFamily(ctx, token)
 		return nil, errors.New("invalid refresh token")
 	}
 
 	// Check if token is expired
-	if token.ExpiresAt.Before(time.Now()) {
+	if token.IsExpired() {
+		uc.refreshFailed(ctx, token, "token_expired", client)
 		// Delete expired token
 		_ = uc.tokenRepo.Delete(ctx, token.ID)
 		return nil, errors.New("refresh token expired")
@@ -92,44 +386,451 @@ func (uc *AuthUseCase) RefreshToken(ctx context.Context, refreshToken string) (*
 	// Find user
 	user, err := uc.userRepo.FindByID(ctx, token.UserID)
 	if err != nil {
+		uc.refreshFailed(ctx, token, "user_not_found", client)
 		return nil, errors.New("user not found")
 	}
 
-	// Delete old token
-	if err := uc.tokenRepo.Delete(ctx, token.ID); err != nil {
+	// Refuse disabled accounts
+	if user.Disabled {
+		uc.refreshFailed(ctx, token, "account_disabled", client)
+		return nil, ErrAccountDisabled
+	}
+
+	// Mark old token as rotated; losing a concurrent rotation also counts as reuse
+	if err := token.MarkRotated(); err != nil {
+		return nil, err
+	}
+	if err := uc.tokenRepo.MarkRotated(ctx, token); err != nil {
+		uc.refreshFailed(ctx, token, "token_reuse", client)
+		uc.revokeTokenFamily(ctx, token)
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Generate new tokens in the same family
+	tokens, err := uc.generateTokens(ctx, user, token, client)
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate new tokens
-	return uc.generateTokens(ctx, user)
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:      service.SecurityEventTokenRefreshed,
+		UserID:    user.ID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details:   map[string]string{"family_id": token.FamilyID},
+	})
+	return tokens, nil
 }
 
-// Logout invalidates a refresh token
+// Logout invalidates a refresh token and every token rotated from the same login
 func (uc *AuthUseCase) Logout(ctx context.Context, refreshToken string) error {
 	// Find token in database
-	token, err := uc.tokenRepo.FindByToken(ctx, refreshToken)
+	token, err := uc.tokenRepo.FindByTokenHash(ctx, hashToken(refreshToken))
 	if err != nil {
 		return errors.New("invalid refresh token")
 	}
 
-	// Delete token
-	return uc.tokenRepo.Delete(ctx, token.ID)
+	// Delete token family
+	if err := uc.tokenRepo.DeleteByFamilyID(ctx, token.FamilyID); err != nil {
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventLogout,
+		UserID:  token.UserID,
+		Details: map[string]string{"family_id": token.FamilyID},
+	})
+	return nil
 }
 
-// generateTokens generates access and refresh tokens
-func (uc *AuthUseCase) generateTokens(ctx context.Context, user *entity.User) (*dto.TokenResponse, error) {
-	// Generate access token
-	accessToken, err := uc.generateAccessToken(user)
+// PublicKeys returns the public keys that verify access tokens, in JWK format
+func (uc *AuthUseCase) PublicKeys(ctx context.Context) ([]jwks.JSONWebKey, error) {
+	return uc.signer.PublicKeys(ctx)
+}
+
+// ListSessions returns the active sessions of a user, one per token family
+func (uc *AuthUseCase) ListSessions(ctx context.Context, userID string) ([]*entity.Token, error) {
+	tokens, err := uc.tokenRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Only the latest token of each family is active
+	sessions := make([]*entity.Token, 0, len(tokens))
+	for _, token := range tokens {
+		if token.IsActive() {
+			sessions = append(sessions, token)
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions
+func (uc *AuthUseCase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	tokens, err := uc.tokenRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the session belongs to the user
+	for _, token := range tokens {
+		if token.FamilyID != sessionID {
+			continue
+		}
+		if err := uc.tokenRepo.DeleteByFamilyID(ctx, sessionID); err != nil {
+			return err
+		}
+
+		publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+			Type:    service.SecurityEventSessionRevoked,
+			UserID:  userID,
+			Details: map[string]string{"family_id": sessionID},
+		})
+		return nil
+	}
+
+	return errors.New("session not found")
+}
+
+// LogoutAll revokes every session of the user
+func (uc *AuthUseCase) LogoutAll(ctx context.Context, userID string) error {
+	if err := uc.tokenRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:   service.SecurityEventLogoutAll,
+		UserID: userID,
+	})
+	return nil
+}
+
+// ChangePassword changes the password of a signed-in user after checking the current one,
+// and revokes every other session
+func (uc *AuthUseCase) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Verify current password
+	if !user.VerifyPassword(currentPassword, uc.hasher) {
+		uc.passwordChangeFailed(ctx, user.ID, "invalid_current_password")
+		return errors.New("current password is incorrect")
+	}
+
+	// Check password policy
+	if err := uc.checkPasswordPolicy(newPassword, user.Email, user.Username); err != nil {
+		uc.passwordChangeFailed(ctx, user.ID, "password_policy")
+		return err
+	}
+
+	// Change password
+	if err := user.ChangePassword(newPassword, uc.hasher); err != nil {
+		return err
+	}
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Revoke other sessions
+	tokens, err := uc.tokenRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	revoked := make(map[string]bool)
+	for _, token := range tokens {
+		if token.FamilyID == sessionID || revoked[token.FamilyID] {
+			continue
+		}
+		if err := uc.tokenRepo.DeleteByFamilyID(ctx, token.FamilyID); err != nil {
+			return err
+		}
+		revoked[token.FamilyID] = true
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventPasswordChanged,
+		UserID:  user.ID,
+		Details: map[string]string{"revoked_sessions": strconv.Itoa(len(revoked))},
+	})
+	return nil
+}
+
+// ForgotPassword issues a single-use password reset token and emails the reset link.
+// It does not reveal whether the email belongs to an account: the link is issued and sent
+// in the background, so known and unknown emails are answered equally fast.
+func (uc *AuthUseCase) ForgotPassword(ctx context.Context, email string) error {
+	// Find user by email
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	runInBackground("send password reset link to user "+user.ID, func(ctx context.Context) error {
+		return uc.sendPasswordReset(ctx, user)
+	})
+	return nil
+}
+
+// sendPasswordReset replaces the user's reset tokens with a new one and emails the reset link
+func (uc *AuthUseCase) sendPasswordReset(ctx context.Context, user *entity.User) error {
+	// Invalidate previously issued reset tokens
+	if err := uc.resetTokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// Generate reset token
+	rawToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(uc.passwordResetConfig.TokenTTL)
+
+	resetToken, err := entity.NewPasswordResetToken(uuid.New().String(), user.ID, hashToken(rawToken), expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// Save token to database
+	if err := uc.resetTokenRepo.Create(ctx, resetToken); err != nil {
+		return err
+	}
+
+	// Send reset link
+	link := uc.passwordResetConfig.URL + "?token=" + url.QueryEscape(rawToken)
+	return uc.mailer.Send(ctx, service.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			user.Username, int(uc.passwordResetConfig.TokenTTL.Minutes()), link),
+	})
+}
+
+// ResetPassword sets a new password using a password reset token and revokes all refresh tokens
+func (uc *AuthUseCase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Find reset token in database
+	resetToken, err := uc.resetTokenRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	if resetToken.IsUsed() || resetToken.IsExpired() {
+		return errors.New("invalid or expired reset token")
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, resetToken.UserID)
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	// Check password policy
+	if err := uc.checkPasswordPolicy(newPassword, user.Email, user.Username); err != nil {
+		return err
+	}
+
+	// Change password
+	if err := user.ChangePassword(newPassword, uc.hasher); err != nil {
+		return err
+	}
+
+	// Consume reset token before applying the change
+	if err := resetToken.MarkUsed(); err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+	if err := uc.resetTokenRepo.MarkUsed(ctx, resetToken); err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Revoke all refresh tokens
+	if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:   service.SecurityEventPasswordReset,
+		UserID: user.ID,
+	})
+	return nil
+}
+
+// VerifyEmail marks a user's email as verified using a signed verification token
+func (uc *AuthUseCase) VerifyEmail(ctx context.Context, verificationToken string) error {
+	// Parse and validate token
+	token, err := jwt.Parse(verificationToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(uc.verificationConfig.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		return errors.New("invalid or expired verification token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != "email_verification" {
+		return errors.New("invalid or expired verification token")
+	}
+
+	userID, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	// The link is only valid for the address it was sent to
+	if user.Email != email {
+		return errors.New("invalid or expired verification token")
+	}
+
+	if user.Verified {
+		return nil
+	}
+
+	user.VerifyEmail()
+	return uc.userRepo.Update(ctx, user)
+}
+
+// ResendVerificationEmail sends a new verification link to an unverified user.
+// It does not reveal whether the email belongs to an account: every email address is throttled
+// the same way, and the link is sent in the background.
+func (uc *AuthUseCase) ResendVerificationEmail(ctx context.Context, email string) error {
+	now := time.Now()
+	key := "resend:" + strings.ToLower(strings.TrimSpace(email))
+
+	// Throttle requests for the address, whether or not it belongs to an account
+	attempt, err := uc.loginAttemptRepo.FindByKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attempt.IsLocked(now) {
+		return ErrVerificationRateLimited
+	}
+	resendInterval := func(int) time.Duration { return uc.verificationConfig.ResendInterval }
+	if _, err := uc.loginAttemptRepo.RecordFailure(ctx, key, now, resendInterval); err != nil {
+		return err
+	}
+
+	// Find user by email
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil || user.Verified {
+		return nil
+	}
+
+	runInBackground("resend verification email to user "+user.ID, func(ctx context.Context) error {
+		return uc.sendVerificationEmail(ctx, user)
+	})
+	return nil
+}
+
+// sendVerificationEmail emails a signed verification link to the user
+func (uc *AuthUseCase) sendVerificationEmail(ctx context.Context, user *entity.User) error {
+	// Create signed verification token
+	claims := jwt.MapClaims{
+		"sub":     user.ID,
+		"email":   user.Email,
+		"purpose": "email_verification",
+		"exp":     time.Now().Add(uc.verificationConfig.TokenTTL).Unix(),
+		"iat":     time.Now().Unix(),
+		"iss":     uc.jwtConfig.Issuer,
+	}
+	verificationToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(uc.verificationConfig.Secret))
+	if err != nil {
+		return err
+	}
+
+	// Send verification link
+	link := uc.verificationConfig.URL + "?token=" + url.QueryEscape(verificationToken)
+	return uc.mailer.Send(ctx, service.Email{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below.\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			user.Username, link),
+	})
+}
+
+// revokeTokenFamily deletes every token in the family of a reused token and emits a security event
+func (uc *AuthUseCase) revokeTokenFamily(ctx context.Context, token *entity.Token) {
+	if err := uc.tokenRepo.DeleteByFamilyID(ctx, token.FamilyID); err != nil {
+		log.Printf("failed to revoke token family %s: %v", token.FamilyID, err)
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventRefreshTokenReuse,
+		UserID:  token.UserID,
+		Outcome: service.SecurityEventFailure,
+		Reason:  "token_reuse",
+		Details: map[string]string{
+			"token_id":  token.ID,
+			"family_id": token.FamilyID,
+		},
+	})
+}
+
+// generateTokens generates access and refresh tokens.
+// A nil parent starts a new token family; otherwise the refresh token joins the parent's family and OAuth client.
+func (uc *AuthUseCase) generateTokens(ctx context.Context, user *entity.User, parent *entity.Token, client valueobject.ClientInfo) (*dto.TokenResponse, error) {
+	oauthClientID, oauthScope := "", ""
+	if parent != nil {
+		oauthClientID, oauthScope = parent.OAuthClientID, parent.OAuthScope
+	}
+	return uc.generateTokensForClient(ctx, user, parent, oauthClientID, oauthScope, client)
+}
+
+// generateTokensForClient generates tokens like generateTokens. When the client ID is not empty, the refresh token
+// is bound to that OAuth client and the access token is limited to the scope granted to it.
+func (uc *AuthUseCase) generateTokensForClient(ctx context.Context, user *entity.User, parent *entity.Token, oauthClientID, oauthScope string, client valueobject.ClientInfo) (*dto.TokenResponse, error) {
+	// Never issue tokens to disabled accounts
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
 	// Generate refresh token
-	refreshToken := uuid.New().String()
+	refreshToken, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
 	expiresAt := time.Now().Add(uc.jwtConfig.RefreshTokenTTL)
 
+	// Resolve token family
+	id := uuid.New().String()
+	familyID, parentID := id, ""
+	if parent != nil {
+		familyID, parentID = parent.FamilyID, parent.ID
+	}
+
 	// Create token entity
-	token, err := entity.NewToken(uuid.New().String(), user.ID, refreshToken, expiresAt)
+	token, err := entity.NewToken(id, user.ID, hashToken(refreshToken), familyID, parentID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	token.SetClient(client)
+	token.OAuthClientID = oauthClientID
+	token.OAuthScope = oauthScope
+	if parent != nil {
+		token.SessionCreatedAt = parent.SessionCreatedAt
+	}
+
+	// Generate access token bound to the session
+	var accessToken string
+	if oauthClientID != "" {
+		accessToken, err = uc.generateClientAccessToken(ctx, user, familyID, oauthClientID, oauthScope)
+	} else {
+		accessToken, err = uc.generateAccessToken(ctx, user, familyID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -146,20 +847,59 @@ func (uc *AuthUseCase) generateTokens(ctx context.Context, user *entity.User) (*
 	}, nil
 }
 
-// generateAccessToken generates a JWT access token
-func (uc *AuthUseCase) generateAccessToken(user *entity.User) (string, error) {
+// generateAccessToken generates a JWT access token for the service's own apps, signed with the active signing key.
+// It is accepted by every first-party service and acts with the user's role.
+func (uc *AuthUseCase) generateAccessToken(ctx context.Context, user *entity.User, sessionID string) (string, error) {
 	// Create claims
-	claims := jwt.MapClaims{
-		"sub":  user.ID,
-		"exp":  time.Now().Add(uc.jwtConfig.AccessTokenTTL).Unix(),
-		"iat":  time.Now().Unix(),
-		"iss":  uc.jwtConfig.Issuer,
-		"role": user.Role,
+	claims := map[string]interface{}{
+		"sub":       user.ID,
+		"aud":       uc.jwtConfig.Audiences,
+		"exp":       time.Now().Add(uc.jwtConfig.AccessTokenTTL).Unix(),
+		"iat":       time.Now().Unix(),
+		"iss":       uc.jwtConfig.Issuer,
+		"role":      user.Role,
+		"verified":  user.Verified,
+		"sid":       sessionID,
+		"jti":       uuid.New().String(),
+		"token_use": authz.TokenUseAccess,
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Sign token
+	return uc.signer.Sign(ctx, claims)
+}
+
+// generateClientAccessToken generates a JWT access token for an OAuth client. It carries no role and
+// is only accepted by the userinfo endpoint, which returns the claims of the granted scope.
+func (uc *AuthUseCase) generateClientAccessToken(ctx context.Context, user *entity.User, sessionID, oauthClientID, scope string) (string, error) {
+	// Create claims
+	claims := map[string]interface{}{
+		"sub":       user.ID,
+		"aud":       uc.jwtConfig.ClientAudience,
+		"client_id": oauthClientID,
+		"scope":     scope,
+		"exp":       time.Now().Add(uc.jwtConfig.AccessTokenTTL).Unix(),
+		"iat":       time.Now().Unix(),
+		"iss":       uc.jwtConfig.Issuer,
+		"sid":       sessionID,
+		"jti":       uuid.New().String(),
+		"token_use": authz.TokenUseAccess,
+	}
 
 	// Sign token
-	return token.SignedString([]byte(uc.jwtConfig.Secret))
+	return uc.signer.Sign(ctx, claims)
+}
+
+// generateSecureToken generates a random URL-safe token
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
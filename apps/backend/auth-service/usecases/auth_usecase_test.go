@@ -0,0 +1,244 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/repository"
+)
+
+type authTest struct {
+	t      *testing.T
+	auth   *AuthUseCase
+	users  *fakeUserRepository
+	tokens *fakeTokenRepository
+	events *fakeEventPublisher
+	client valueobject.ClientInfo
+}
+
+func newAuthTest(t *testing.T) *authTest {
+	t.Helper()
+
+	user, err := entity.NewUser("user-1", "alice@example.com", "alice", testPassword, fakeHasher{})
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+
+	test := &authTest{
+		t:      t,
+		users:  newFakeUserRepository(user),
+		tokens: newFakeTokenRepository(),
+		events: &fakeEventPublisher{},
+		client: valueobject.ClientInfo{IPAddress: "192.0.2.1", UserAgent: "test"},
+	}
+	mfa := NewMFAUseCase(test.users, newFakeRecoveryCodeRepository(), test.events, config.MFAConfig{})
+	test.auth = NewAuthUseCase(
+		test.users,
+		test.tokens,
+		nil,
+		repository.NewMemoryLoginAttemptRepository(24*time.Hour),
+		nil,
+		nil,
+		nil,
+		test.events,
+		fakeSigner{},
+		fakeHasher{},
+		nil,
+		mfa,
+		config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 24 * time.Hour, Issuer: "test"},
+		config.PasswordResetConfig{},
+		config.EmailVerificationConfig{},
+		config.LockoutConfig{
+			AccountThreshold: 3,
+			IPThreshold:      100,
+			BaseDelay:        30 * time.Second,
+			MaxDelay:         2 * time.Minute,
+			Window:           24 * time.Hour,
+		},
+	)
+	return test
+}
+
+// login logs in with the password and returns the refresh token
+func (test *authTest) login() string {
+	test.t.Helper()
+
+	response, err := test.auth.Login(context.Background(), "alice@example.com", testPassword, test.client)
+	if err != nil {
+		test.t.Fatalf("Login: %v", err)
+	}
+	if response.TokenResponse == nil || response.RefreshToken == "" {
+		test.t.Fatalf("Login returned no refresh token: %+v", response)
+	}
+	return response.RefreshToken
+}
+
+// stored returns the stored refresh token for a raw token
+func (test *authTest) stored(refreshToken string) *entity.Token {
+	test.t.Helper()
+
+	token, err := test.tokens.FindByTokenHash(context.Background(), hashToken(refreshToken))
+	if err != nil {
+		test.t.Fatalf("refresh token not stored: %v", err)
+	}
+	return token
+}
+
+// hasEvent checks if a security event of the type and reason was published
+func (test *authTest) hasEvent(eventType service.SecurityEventType, reason string) bool {
+	for _, event := range test.events.events {
+		if event.Type == eventType && event.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRefreshTokenRotatesWithinFamily(t *testing.T) {
+	test := newAuthTest(t)
+	first := test.login()
+	parent := test.stored(first)
+
+	tokens, err := test.auth.RefreshToken(context.Background(), first, test.client)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if tokens.RefreshToken == "" || tokens.RefreshToken == first {
+		t.Fatalf("RefreshToken did not issue a new refresh token: %+v", tokens)
+	}
+
+	// Only the hash of the new token is stored, in the same family as its parent
+	child := test.stored(tokens.RefreshToken)
+	if child.TokenHash == tokens.RefreshToken {
+		t.Error("refresh token is stored in the clear")
+	}
+	if child.FamilyID != parent.FamilyID || child.ParentID != parent.ID {
+		t.Errorf("got family %q and parent %q, want family %q and parent %q", child.FamilyID, child.ParentID, parent.FamilyID, parent.ID)
+	}
+	if !test.stored(first).IsRotated() {
+		t.Error("the presented refresh token was not marked rotated")
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	test := newAuthTest(t)
+	first := test.login()
+
+	tokens, err := test.auth.RefreshToken(context.Background(), first, test.client)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	// Replaying the rotated token is refused and revokes the family
+	if _, err := test.auth.RefreshToken(context.Background(), first, test.client); err == nil {
+		t.Fatal("RefreshToken accepted a rotated token")
+	}
+	if !test.hasEvent(service.SecurityEventRefreshTokenReuse, "token_reuse") {
+		t.Error("reuse of a rotated token published no refresh_token_reuse event")
+	}
+
+	// The token rotated to the legitimate client no longer works either
+	if _, err := test.auth.RefreshToken(context.Background(), tokens.RefreshToken, test.client); err == nil {
+		t.Fatal("RefreshToken accepted a token from a revoked family")
+	}
+}
+
+func TestRefreshTokenOnlyRefreshesForItsClient(t *testing.T) {
+	test := newAuthTest(t)
+	first := test.login()
+
+	// A first-party token cannot be refreshed by an OAuth client
+	if _, err := test.auth.refreshTokenForClient(context.Background(), first, "client-1", test.client); err == nil {
+		t.Fatal("refreshTokenForClient accepted a first-party token for an OAuth client")
+	}
+	if !test.hasEvent(service.SecurityEventTokenRefreshed, "wrong_client") {
+		t.Error("refreshing for the wrong client published no wrong_client event")
+	}
+
+	// The refusal does not use the token up
+	if _, err := test.auth.RefreshToken(context.Background(), first, test.client); err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+}
+
+func TestRefreshTokenRefusesExpiredToken(t *testing.T) {
+	test := newAuthTest(t)
+	first := test.login()
+
+	token := test.stored(first)
+	test.tokens.tokens[token.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := test.auth.RefreshToken(context.Background(), first, test.client); err == nil {
+		t.Fatal("RefreshToken accepted an expired token")
+	}
+	if !test.hasEvent(service.SecurityEventTokenRefreshed, "token_expired") {
+		t.Error("refreshing an expired token published no token_expired event")
+	}
+}
+
+func TestLoginLocksOutAfterRepeatedFailures(t *testing.T) {
+	test := newAuthTest(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := test.auth.Login(ctx, "alice@example.com", "wrong password", test.client); err == nil {
+			t.Fatal("Login accepted a wrong password")
+		}
+	}
+	if !test.hasEvent(service.SecurityEventLoginLockout, "too_many_failures") {
+		t.Error("locking the account published no login_lockout event")
+	}
+
+	// Even the right password is refused while the account is locked
+	_, err := test.auth.Login(ctx, "alice@example.com", testPassword, test.client)
+	var lockedErr *LoginLockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("got %v, want a LoginLockedError", err)
+	}
+	if lockedErr.RetryAfter <= 0 || lockedErr.RetryAfter > 30*time.Second {
+		t.Errorf("got retry after %s, want at most the base delay of 30s", lockedErr.RetryAfter)
+	}
+
+	// An admin can lift the lock
+	if err := test.auth.UnlockUser(ctx, "user-1"); err != nil {
+		t.Fatalf("UnlockUser: %v", err)
+	}
+	test.login()
+}
+
+func TestLoginLockoutBacksOffExponentially(t *testing.T) {
+	test := newAuthTest(t)
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	user, err := test.users.FindByID(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	accountKey, _ := lockoutKeys(user.Email, test.client)
+
+	// No lock below the threshold, then the base delay doubling with each failure up to the maximum
+	want := []time.Duration{0, 0, 30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}
+	for i, delay := range want {
+		test.auth.recordLoginFailure(ctx, now, user, user.Email, test.client)
+
+		var got time.Duration
+		var lockedErr *LoginLockedError
+		if err := test.auth.checkLockout(ctx, now, accountKey); errors.As(err, &lockedErr) {
+			got = lockedErr.RetryAfter
+		}
+		if got != delay {
+			t.Errorf("failure %d: got lock of %s, want %s", i+1, got, delay)
+		}
+	}
+
+	// The lock runs out on its own
+	if err := test.auth.checkLockout(ctx, now.Add(2*time.Minute), accountKey); err != nil {
+		t.Errorf("checkLockout after the lock ran out: %v", err)
+	}
+}
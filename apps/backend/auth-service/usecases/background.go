@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// backgroundTimeout bounds work that outlives the request that started it
+const backgroundTimeout = 30 * time.Second
+
+// runInBackground runs work such as sending an email after the request has been answered,
+// so the response time does not depend on it. Failures are logged with the description.
+func runInBackground(description string, work func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+
+		if err := work(ctx); err != nil {
+			log.Printf("failed to %s: %v", description, err)
+		}
+	}()
+}
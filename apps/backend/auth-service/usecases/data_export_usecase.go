@@ -0,0 +1,202 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/packages/go/common/dataexport"
+)
+
+// exportedAccount is a user's account in their data export, without the password hash or MFA secrets
+type exportedAccount struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role"`
+	Verified   bool       `json:"verified"`
+	MFAEnabled bool       `json:"mfa_enabled"`
+	Disabled   bool       `json:"disabled"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// exportedSession is one of a user's active sessions in their data export
+type exportedSession struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// exportedIdentity is a social login identity linked to a user in their data export
+type exportedIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// exportedAccessToken is one of a user's personal access tokens in their data export, without its secret
+type exportedAccessToken struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// exportedSecurityEvent is an entry of a user's security history in their data export
+type exportedSecurityEvent struct {
+	Type       string            `json:"type"`
+	IPAddress  string            `json:"ip_address,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Outcome    string            `json:"outcome"`
+	Reason     string            `json:"reason,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// DataExportUseCase gathers a user's account data for their personal data export
+type DataExportUseCase struct {
+	userRepo           repository.UserRepository
+	linkedIdentityRepo repository.LinkedIdentityRepository
+	patRepo            repository.PersonalAccessTokenRepository
+	auditEventRepo     repository.AuditEventRepository
+	authUseCase        *AuthUseCase
+}
+
+// NewDataExportUseCase creates a new data export use case
+func NewDataExportUseCase(
+	userRepo repository.UserRepository,
+	linkedIdentityRepo repository.LinkedIdentityRepository,
+	patRepo repository.PersonalAccessTokenRepository,
+	auditEventRepo repository.AuditEventRepository,
+	authUseCase *AuthUseCase,
+) *DataExportUseCase {
+	return &DataExportUseCase{
+		userRepo:           userRepo,
+		linkedIdentityRepo: linkedIdentityRepo,
+		patRepo:            patRepo,
+		auditEventRepo:     auditEventRepo,
+		authUseCase:        authUseCase,
+	}
+}
+
+// ExportUserData exports a user's account, sessions, linked identities, personal access tokens
+// and security history as JSON files
+func (uc *DataExportUseCase) ExportUserData(ctx context.Context, userID string) (*dataexport.Bundle, error) {
+	bundle := &dataexport.Bundle{}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = bundle.AddJSON("account.json", exportedAccount{
+		ID:         user.ID,
+		Email:      user.Email,
+		Username:   user.Username,
+		Role:       string(user.Role),
+		Verified:   user.Verified,
+		MFAEnabled: user.MFAEnabled,
+		Disabled:   user.Disabled,
+		DisabledAt: user.DisabledAt,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions
+	tokens, err := uc.authUseCase.ListSessions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]exportedSession, len(tokens))
+	for i, token := range tokens {
+		sessions[i] = exportedSession{
+			ID:          token.FamilyID,
+			DeviceLabel: string(token.DeviceLabel),
+			UserAgent:   token.UserAgent,
+			IPAddress:   token.IPAddress,
+			CreatedAt:   token.SessionCreatedAt,
+			LastUsedAt:  token.LastUsedAt,
+			ExpiresAt:   token.ExpiresAt,
+		}
+	}
+	if err := bundle.AddJSON("sessions.json", sessions); err != nil {
+		return nil, err
+	}
+
+	// Linked identities
+	linked, err := uc.linkedIdentityRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	identities := make([]exportedIdentity, len(linked))
+	for i, identity := range linked {
+		identities[i] = exportedIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		}
+	}
+	if err := bundle.AddJSON("linked_identities.json", identities); err != nil {
+		return nil, err
+	}
+
+	// Personal access tokens
+	pats, err := uc.patRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	accessTokens := make([]exportedAccessToken, len(pats))
+	for i, pat := range pats {
+		accessTokens[i] = exportedAccessToken{
+			ID:          pat.ID,
+			Name:        pat.Name,
+			TokenPrefix: pat.TokenPrefix,
+			Scopes:      strings.Fields(pat.Scopes),
+			ExpiresAt:   pat.ExpiresAt,
+			LastUsedAt:  pat.LastUsedAt,
+			CreatedAt:   pat.CreatedAt,
+		}
+	}
+	if err := bundle.AddJSON("personal_access_tokens.json", accessTokens); err != nil {
+		return nil, err
+	}
+
+	// Security history
+	events := []exportedSecurityEvent{}
+	err = uc.auditEventRepo.Each(ctx, repository.AuditEventFilter{UserID: user.ID}, func(event *entity.AuditEvent) error {
+		events = append(events, exportedSecurityEvent{
+			Type:       event.Type,
+			IPAddress:  event.IPAddress,
+			UserAgent:  event.UserAgent,
+			Outcome:    event.Outcome,
+			Reason:     event.Reason,
+			Details:    event.DetailMap(),
+			OccurredAt: event.OccurredAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.AddJSON("security_events.json", events); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
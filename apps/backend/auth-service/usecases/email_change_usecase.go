@@ -0,0 +1,307 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+// ErrEmailTaken is returned when the requested email address belongs to another account
+var ErrEmailTaken = errors.New("email already exists")
+
+// EmailChangeUseCase implements users changing their email address.
+// The new address must be confirmed with a link sent to it, and the old address receives a link
+// that cancels the change or, once it was applied, changes the address back and locks out whoever made it.
+type EmailChangeUseCase struct {
+	emailChangeRepo repository.EmailChangeRepository
+	userRepo        repository.UserRepository
+	tokenRepo       repository.TokenRepository
+	patRepo         repository.PersonalAccessTokenRepository
+	mailer          service.Mailer
+	authUseCase     *AuthUseCase
+	config          config.EmailChangeConfig
+}
+
+// NewEmailChangeUseCase creates a new email change use case
+func NewEmailChangeUseCase(
+	emailChangeRepo repository.EmailChangeRepository,
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	patRepo repository.PersonalAccessTokenRepository,
+	mailer service.Mailer,
+	authUseCase *AuthUseCase,
+	config config.EmailChangeConfig,
+) *EmailChangeUseCase {
+	return &EmailChangeUseCase{
+		emailChangeRepo: emailChangeRepo,
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		patRepo:         patRepo,
+		mailer:          mailer,
+		authUseCase:     authUseCase,
+		config:          config,
+	}
+}
+
+// RequestChange checks the user's password and emails a confirmation link to the new address
+// and a revert link to the current one. It replaces any change the user has not confirmed yet.
+func (uc *EmailChangeUseCase) RequestChange(ctx context.Context, userID, newEmail, password string) error {
+	// Check email format
+	if address, err := mail.ParseAddress(newEmail); err != nil || address.Address != newEmail {
+		return errors.New("email address is invalid")
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Verify password
+	if !user.VerifyPassword(password, uc.authUseCase.hasher) {
+		uc.changeFailed(ctx, user.ID, service.SecurityEventEmailChangeRequested, "invalid_password")
+		return errors.New("password is incorrect")
+	}
+
+	// Check if email already exists
+	if _, err := uc.userRepo.FindByEmail(ctx, newEmail); err == nil {
+		uc.changeFailed(ctx, user.ID, service.SecurityEventEmailChangeRequested, "email_exists")
+		return ErrEmailTaken
+	}
+
+	// Generate confirmation and revert tokens
+	confirmToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	revertToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	change, err := entity.NewEmailChange(uuid.New().String(), user, newEmail, hashToken(confirmToken), hashToken(revertToken),
+		now.Add(uc.config.TokenTTL), now.Add(uc.config.RevertTTL))
+	if err != nil {
+		return err
+	}
+
+	// Replace unconfirmed changes and save the new one to database
+	err = uc.authUseCase.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.emailChangeRepo.DeletePendingByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		return uc.emailChangeRepo.Create(ctx, change)
+	})
+	if err != nil {
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventEmailChangeRequested,
+		UserID:  user.ID,
+		Details: map[string]string{"new_email": newEmail},
+	})
+
+	// Send confirmation link to the new address
+	confirmLink := uc.config.ConfirmURL + "?token=" + url.QueryEscape(confirmToken)
+	err = uc.mailer.Send(ctx, service.Email{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to make this your account's email address. It expires in %d hours.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.Username, int(uc.config.TokenTTL.Hours()), confirmLink),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Let the current address undo the change
+	revertLink := uc.config.RevertURL + "?token=" + url.QueryEscape(revertToken)
+	err = uc.mailer.Send(ctx, service.Email{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to change your account's email address to %s.\n\nIf this was not you, use the link below to keep this address, sign out everywhere and reset your password. It works for %d days.\n\n%s\n",
+			user.Username, newEmail, int(uc.config.RevertTTL.Hours()/24), revertLink),
+	})
+	if err != nil {
+		log.Printf("failed to send email change notice to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ConfirmChange applies an email change using its confirmation token. The new address is left unverified
+// until the user goes through email verification. The change is relayed to other services.
+func (uc *EmailChangeUseCase) ConfirmChange(ctx context.Context, token string) error {
+	// Find email change in database
+	change, err := uc.emailChangeRepo.FindByConfirmTokenHash(ctx, hashToken(token))
+	if err != nil || !change.CanConfirm() {
+		return errors.New("invalid or expired confirmation token")
+	}
+
+	err = uc.authUseCase.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Find user
+		user, err := uc.userRepo.FindByID(ctx, change.UserID)
+		if err != nil {
+			return errors.New("invalid or expired confirmation token")
+		}
+		if user.Email != change.PreviousEmail {
+			return errors.New("email address has changed since this change was requested")
+		}
+
+		// Check if email was taken in the meantime
+		if _, err := uc.userRepo.FindByEmail(ctx, change.NewEmail); err == nil {
+			return ErrEmailTaken
+		}
+
+		// Consume confirmation token before applying the change
+		if err := change.Confirm(); err != nil {
+			return errors.New("invalid or expired confirmation token")
+		}
+		if err := uc.emailChangeRepo.MarkConfirmed(ctx, change); err != nil {
+			return errors.New("invalid or expired confirmation token")
+		}
+
+		// Change email
+		if err := user.ChangeEmail(change.NewEmail); err != nil {
+			return err
+		}
+
+		// Save user to database along with the event that updates their profile
+		return uc.saveEmail(ctx, user, change.PreviousEmail)
+	})
+	if err != nil {
+		reason := "invalid_token"
+		if errors.Is(err, ErrEmailTaken) {
+			reason = "email_exists"
+		}
+		uc.changeFailed(ctx, change.UserID, service.SecurityEventEmailChanged, reason)
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventEmailChanged,
+		UserID:  change.UserID,
+		Details: map[string]string{"email": change.NewEmail, "previous_email": change.PreviousEmail},
+	})
+	return nil
+}
+
+// RevertChange cancels an email change using its revert token. When the change was already applied,
+// the account may have been taken over: the previous address is restored, every session and personal
+// access token is revoked, and the password is replaced with a random one and a reset link is emailed.
+func (uc *EmailChangeUseCase) RevertChange(ctx context.Context, token string) error {
+	// Find email change in database
+	change, err := uc.emailChangeRepo.FindByRevertTokenHash(ctx, hashToken(token))
+	if err != nil || !change.CanRevert() {
+		return errors.New("invalid or expired revert token")
+	}
+
+	var user *entity.User
+	err = uc.authUseCase.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Consume revert token before undoing the change
+		if err := change.Revert(); err != nil {
+			return errors.New("invalid or expired revert token")
+		}
+		if err := uc.emailChangeRepo.MarkReverted(ctx, change); err != nil {
+			return errors.New("invalid or expired revert token")
+		}
+
+		// Nothing to undo before the change is confirmed
+		if !change.IsConfirmed() {
+			return nil
+		}
+
+		// Find user
+		user, err = uc.userRepo.FindByID(ctx, change.UserID)
+		if err != nil {
+			return errors.New("invalid or expired revert token")
+		}
+
+		// Restore the previous address unless the user moved on to yet another one
+		restored := user.Email == change.NewEmail
+		if restored {
+			if err := user.ChangeEmail(change.PreviousEmail); err != nil {
+				return err
+			}
+			if change.PreviousVerified {
+				user.VerifyEmail()
+			}
+		}
+
+		// Lock out the current password
+		randomPassword, err := generateSecureToken()
+		if err != nil {
+			return err
+		}
+		if err := user.ChangePassword(randomPassword, uc.authUseCase.hasher); err != nil {
+			return err
+		}
+
+		// Save user to database, along with the event that updates their profile if the address was restored
+		if restored {
+			if err := uc.saveEmail(ctx, user, change.NewEmail); err != nil {
+				return err
+			}
+		} else if err := uc.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		// Revoke all refresh tokens and personal access tokens
+		if err := uc.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		return uc.patRepo.DeleteByUserID(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Send reset link
+	if user != nil {
+		if err := uc.authUseCase.sendPasswordReset(ctx, user); err != nil {
+			log.Printf("failed to send password reset link to user %s: %v", user.ID, err)
+		}
+	}
+
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventEmailChangeReverted,
+		UserID:  change.UserID,
+		Details: map[string]string{"email": change.PreviousEmail, "reverted_email": change.NewEmail, "applied": fmt.Sprint(change.IsConfirmed())},
+	})
+	return nil
+}
+
+// saveEmail saves a user whose email changed and records the UserEmailChanged event.
+// Call it within a transaction.
+func (uc *EmailChangeUseCase) saveEmail(ctx context.Context, user *entity.User, previousEmail string) error {
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return uc.authUseCase.recordAccountEvent(ctx, accountevents.UserEmailChangedType, user.ID, accountevents.UserEmailChanged{
+		UserID:        user.ID,
+		Email:         user.Email,
+		PreviousEmail: previousEmail,
+	})
+}
+
+// changeFailed publishes a refused email change
+func (uc *EmailChangeUseCase) changeFailed(ctx context.Context, userID string, eventType service.SecurityEventType, reason string) {
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    eventType,
+		UserID:  userID,
+		Outcome: service.SecurityEventFailure,
+		Reason:  reason,
+	})
+}
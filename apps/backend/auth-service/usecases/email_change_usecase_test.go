@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/repository"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+type emailChangeTest struct {
+	t      *testing.T
+	change *EmailChangeUseCase
+	users  *fakeUserRepository
+	tokens *fakeTokenRepository
+	pats   *fakePersonalAccessTokenRepository
+	outbox *fakeOutboxRepository
+	mailer *fakeMailer
+}
+
+func newEmailChangeTest(t *testing.T) *emailChangeTest {
+	t.Helper()
+
+	user, err := entity.NewUser("user-1", "alice@example.com", "alice", testPassword, fakeHasher{})
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	user.VerifyEmail()
+
+	test := &emailChangeTest{
+		t:      t,
+		users:  newFakeUserRepository(user),
+		tokens: newFakeTokenRepository(),
+		pats:   newFakePersonalAccessTokenRepository(),
+		outbox: &fakeOutboxRepository{},
+		mailer: &fakeMailer{},
+	}
+	auth := NewAuthUseCase(
+		test.users,
+		test.tokens,
+		newFakePasswordResetTokenRepository(),
+		repository.NewMemoryLoginAttemptRepository(24*time.Hour),
+		test.outbox,
+		fakeTransactor{},
+		test.mailer,
+		&fakeEventPublisher{},
+		fakeSigner{},
+		fakeHasher{},
+		nil,
+		nil,
+		config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 24 * time.Hour, Issuer: "test"},
+		config.PasswordResetConfig{URL: "https://blog.example.com/reset-password", TokenTTL: time.Hour},
+		config.EmailVerificationConfig{},
+		config.LockoutConfig{},
+	)
+	test.change = NewEmailChangeUseCase(
+		newFakeEmailChangeRepository(),
+		test.users,
+		test.tokens,
+		test.pats,
+		test.mailer,
+		auth,
+		config.EmailChangeConfig{
+			ConfirmURL: "https://blog.example.com/confirm-email",
+			RevertURL:  "https://blog.example.com/revert-email",
+			TokenTTL:   24 * time.Hour,
+			RevertTTL:  7 * 24 * time.Hour,
+		},
+	)
+	return test
+}
+
+// request asks to change the test user's address and returns the confirmation and revert tokens from the emails sent
+func (test *emailChangeTest) request(newEmail string) (string, string) {
+	test.t.Helper()
+
+	if err := test.change.RequestChange(context.Background(), "user-1", newEmail, testPassword); err != nil {
+		test.t.Fatalf("RequestChange: %v", err)
+	}
+	return test.linkToken(newEmail, "/confirm-email"), test.linkToken("alice@example.com", "/revert-email")
+}
+
+// linkToken returns the token of the last link with the path emailed to an address
+func (test *emailChangeTest) linkToken(to, path string) string {
+	test.t.Helper()
+
+	for i := len(test.mailer.emails) - 1; i >= 0; i-- {
+		email := test.mailer.emails[i]
+		start := strings.Index(email.Body, path+"?token=")
+		if email.To != to || start < 0 {
+			continue
+		}
+		link := strings.Fields(email.Body[start:])[0]
+		parsed, err := url.Parse(link)
+		if err != nil {
+			test.t.Fatalf("emailed link %q: %v", link, err)
+		}
+		return parsed.Query().Get("token")
+	}
+	test.t.Fatalf("no %s link was emailed to %s", path, to)
+	return ""
+}
+
+// user returns the stored test user
+func (test *emailChangeTest) user() *entity.User {
+	test.t.Helper()
+
+	user, err := test.users.FindByID(context.Background(), "user-1")
+	if err != nil {
+		test.t.Fatalf("FindByID: %v", err)
+	}
+	return user
+}
+
+func TestEmailChangeRejectsInvalidAddress(t *testing.T) {
+	test := newEmailChangeTest(t)
+
+	for _, email := range []string{"", "not an address", "Alice <new@example.com>", "new@example.com\r\nBcc: x@example.com"} {
+		if err := test.change.RequestChange(context.Background(), "user-1", email, testPassword); err == nil {
+			t.Errorf("RequestChange accepted %q", email)
+		}
+	}
+	if len(test.mailer.emails) != 0 {
+		t.Errorf("got %d emails, want none", len(test.mailer.emails))
+	}
+}
+
+func TestEmailChangeConfirmAppliesNewAddress(t *testing.T) {
+	test := newEmailChangeTest(t)
+	confirmToken, _ := test.request("new@example.com")
+
+	// Nothing changes until the new address confirms
+	if user := test.user(); user.Email != "alice@example.com" {
+		t.Fatalf("got email %q before confirmation", user.Email)
+	}
+
+	if err := test.change.ConfirmChange(context.Background(), confirmToken); err != nil {
+		t.Fatalf("ConfirmChange: %v", err)
+	}
+	user := test.user()
+	if user.Email != "new@example.com" || user.Verified {
+		t.Errorf("got email %q verified %t, want the new address unverified", user.Email, user.Verified)
+	}
+	if len(test.outbox.messages) != 1 || test.outbox.messages[0].Type != string(accountevents.UserEmailChangedType) {
+		t.Errorf("got outbox messages %v, want one UserEmailChanged", test.outbox.messages)
+	}
+
+	// The confirmation token works once
+	if err := test.change.ConfirmChange(context.Background(), confirmToken); err == nil {
+		t.Error("ConfirmChange accepted a used token")
+	}
+}
+
+func TestEmailChangeRevertBeforeConfirmCancelsChange(t *testing.T) {
+	test := newEmailChangeTest(t)
+	confirmToken, revertToken := test.request("new@example.com")
+
+	if err := test.change.RevertChange(context.Background(), revertToken); err != nil {
+		t.Fatalf("RevertChange: %v", err)
+	}
+	if err := test.change.ConfirmChange(context.Background(), confirmToken); err == nil {
+		t.Error("ConfirmChange accepted a reverted change")
+	}
+	if user := test.user(); user.Email != "alice@example.com" {
+		t.Errorf("got email %q, want the address unchanged", user.Email)
+	}
+}
+
+func TestEmailChangeRevertAfterConfirmLocksOutTakeover(t *testing.T) {
+	test := newEmailChangeTest(t)
+	ctx := context.Background()
+	confirmToken, revertToken := test.request("new@example.com")
+	if err := test.change.ConfirmChange(ctx, confirmToken); err != nil {
+		t.Fatalf("ConfirmChange: %v", err)
+	}
+	if err := test.tokens.Create(ctx, &entity.Token{ID: "token-1", UserID: "user-1", TokenHash: "hash-1"}); err != nil {
+		t.Fatalf("Create token: %v", err)
+	}
+	if err := test.pats.Create(ctx, &entity.PersonalAccessToken{ID: "pat-1", UserID: "user-1", TokenHash: "hash-2"}); err != nil {
+		t.Fatalf("Create personal access token: %v", err)
+	}
+
+	if err := test.change.RevertChange(ctx, revertToken); err != nil {
+		t.Fatalf("RevertChange: %v", err)
+	}
+
+	// The previous address is restored as it was, and the password no longer works
+	user := test.user()
+	if user.Email != "alice@example.com" || !user.Verified {
+		t.Errorf("got email %q verified %t, want the previous address verified", user.Email, user.Verified)
+	}
+	if user.VerifyPassword(testPassword, fakeHasher{}) {
+		t.Error("the password was not replaced")
+	}
+
+	// Every session and personal access token is revoked
+	if tokens, _ := test.tokens.FindByUserID(ctx, "user-1"); len(tokens) != 0 {
+		t.Errorf("got %d refresh tokens, want none", len(tokens))
+	}
+	if pats, _ := test.pats.FindByUserID(ctx, "user-1"); len(pats) != 0 {
+		t.Errorf("got %d personal access tokens, want none", len(pats))
+	}
+
+	// A reset link goes to the restored address
+	test.linkToken("alice@example.com", "/reset-password")
+
+	// The revert token works once
+	if err := test.change.RevertChange(ctx, revertToken); err == nil {
+		t.Error("RevertChange accepted a used token")
+	}
+}
@@ -0,0 +1,34 @@
+package usecases
+
+import (
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/repository"
+)
+
+// TestHasher hashes the passwords of users given to NewTestOIDCUseCase
+var TestHasher service.PasswordHasher = fakeHasher{}
+
+// DecodeTestToken returns the claims of a token issued by a use case made with NewTestOIDCUseCase
+var DecodeTestToken = decodeFakeToken
+
+// NewTestOIDCUseCase returns an OIDC provider for the users, with in-memory repositories
+// and tokens that are encoded rather than signed. First-party access tokens are issued for the auth service,
+// and those of OAuth clients for the userinfo endpoint under the issuer URL.
+func NewTestOIDCUseCase(oidcConfig config.OIDCConfig, users ...*entity.User) *OIDCUseCase {
+	userRepo := newFakeUserRepository(users...)
+	jwtConfig := config.JWTConfig{
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+		Issuer:          oidcConfig.IssuerURL,
+		Audiences:       []string{"auth-service"},
+		ClientAudience:  oidcConfig.IssuerURL + "/oauth/userinfo",
+	}
+	mfa := NewMFAUseCase(userRepo, newFakeRecoveryCodeRepository(), &fakeEventPublisher{}, config.MFAConfig{})
+	auth := NewAuthUseCase(userRepo, newFakeTokenRepository(), nil, repository.NewMemoryLoginAttemptRepository(time.Hour), nil, nil, nil,
+		&fakeEventPublisher{}, fakeSigner{}, fakeHasher{}, nil, mfa, jwtConfig, config.PasswordResetConfig{}, config.EmailVerificationConfig{}, config.LockoutConfig{})
+	return NewOIDCUseCase(newFakeOAuthClientRepository(), newFakeAuthorizationCodeRepository(), userRepo, auth, fakeSigner{}, jwtConfig, oidcConfig)
+}
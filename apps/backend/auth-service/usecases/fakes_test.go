@@ -0,0 +1,880 @@
+package usecases
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+)
+
+// fakeUserRepository keeps users in memory, handing out copies like a database would
+type fakeUserRepository struct {
+	mu    sync.Mutex
+	users map[string]entity.User
+}
+
+func newFakeUserRepository(users ...*entity.User) *fakeUserRepository {
+	r := &fakeUserRepository{users: make(map[string]entity.User)}
+	for _, user := range users {
+		r.users[user.ID] = *user
+	}
+	return r
+}
+
+func (r *fakeUserRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *fakeUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *fakeUserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *fakeUserRepository) Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (r *fakeUserRepository) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *fakeUserRepository) Update(ctx context.Context, user *entity.User) error {
+	return r.Create(ctx, user)
+}
+
+func (r *fakeUserRepository) MarkMFAStepUsed(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[user.ID]
+	if !ok || stored.MFALastUsedStep >= user.MFALastUsedStep {
+		return errors.New("two-factor authentication code already used")
+	}
+	stored.MFALastUsedStep = user.MFALastUsedStep
+	r.users[user.ID] = stored
+	return nil
+}
+
+func (r *fakeUserRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+// fakeRecoveryCodeRepository keeps recovery codes in memory
+type fakeRecoveryCodeRepository struct {
+	mu    sync.Mutex
+	codes map[string][]*entity.RecoveryCode
+}
+
+func newFakeRecoveryCodeRepository() *fakeRecoveryCodeRepository {
+	return &fakeRecoveryCodeRepository{codes: make(map[string][]*entity.RecoveryCode)}
+}
+
+func (r *fakeRecoveryCodeRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.RecoveryCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	codes := make([]*entity.RecoveryCode, len(r.codes[userID]))
+	for i, code := range r.codes[userID] {
+		copied := *code
+		codes[i] = &copied
+	}
+	return codes, nil
+}
+
+func (r *fakeRecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID string, codes []*entity.RecoveryCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes[userID] = codes
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepository) MarkUsed(ctx context.Context, code *entity.RecoveryCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.codes[code.UserID] {
+		if stored.ID == code.ID {
+			if stored.UsedAt != nil {
+				return errors.New("recovery code already used")
+			}
+			stored.UsedAt = code.UsedAt
+			return nil
+		}
+	}
+	return errors.New("recovery code not found")
+}
+
+func (r *fakeRecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.codes, userID)
+	return nil
+}
+
+// fakeTokenRepository keeps refresh tokens in memory
+type fakeTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*entity.Token
+}
+
+func newFakeTokenRepository() *fakeTokenRepository {
+	return &fakeTokenRepository{tokens: make(map[string]*entity.Token)}
+}
+
+func (r *fakeTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("token not found")
+}
+
+func (r *fakeTokenRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []*entity.Token
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			copied := *token
+			tokens = append(tokens, &copied)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeTokenRepository) Create(ctx context.Context, token *entity.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakeTokenRepository) MarkRotated(ctx context.Context, token *entity.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tokens[token.ID]
+	if !ok || stored.RotatedAt != nil {
+		return errors.New("token already rotated")
+	}
+	stored.RotatedAt = token.RotatedAt
+	return nil
+}
+
+func (r *fakeTokenRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, id)
+	return nil
+}
+
+func (r *fakeTokenRepository) DeleteByFamilyID(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.FamilyID == familyID {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepository) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// fakeEventPublisher records published security events
+type fakeEventPublisher struct {
+	mu     sync.Mutex
+	events []service.SecurityEvent
+}
+
+func (p *fakeEventPublisher) Publish(ctx context.Context, event service.SecurityEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// fakeSigner encodes claims without signing them; tests only read them back with decodeFakeToken
+type fakeSigner struct{}
+
+func (fakeSigner) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (fakeSigner) Sign(ctx context.Context, claims map[string]interface{}) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func (fakeSigner) PublicKeys(ctx context.Context) ([]jwks.JSONWebKey, error) {
+	return nil, nil
+}
+
+// decodeFakeToken returns the claims of a token made by fakeSigner
+func decodeFakeToken(token string) (map[string]interface{}, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	err = json.Unmarshal(payload, &claims)
+	return claims, err
+}
+
+// fakeHasher "hashes" passwords by prefixing them, which is enough to tell them apart
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (fakeHasher) Verify(hash, password string) (bool, error) {
+	return hash == "hashed:"+password, nil
+}
+
+func (fakeHasher) NeedsRehash(hash string) bool {
+	return false
+}
+
+// fakeOAuthClientRepository keeps OAuth clients in memory
+type fakeOAuthClientRepository struct {
+	mu      sync.Mutex
+	clients map[string]*entity.OAuthClient
+}
+
+func newFakeOAuthClientRepository() *fakeOAuthClientRepository {
+	return &fakeOAuthClientRepository{clients: make(map[string]*entity.OAuthClient)}
+}
+
+func (r *fakeOAuthClientRepository) FindByID(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, errors.New("OAuth client not found")
+	}
+	return client, nil
+}
+
+func (r *fakeOAuthClientRepository) FindAll(ctx context.Context) ([]*entity.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var clients []*entity.OAuthClient
+	for _, client := range r.clients {
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (r *fakeOAuthClientRepository) Create(ctx context.Context, client *entity.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[client.ID] = client
+	return nil
+}
+
+func (r *fakeOAuthClientRepository) Save(ctx context.Context, client *entity.OAuthClient) error {
+	return r.Create(ctx, client)
+}
+
+func (r *fakeOAuthClientRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, id)
+	return nil
+}
+
+// fakeAuthorizationCodeRepository keeps authorization codes in memory
+type fakeAuthorizationCodeRepository struct {
+	mu    sync.Mutex
+	codes map[string]*entity.AuthorizationCode
+}
+
+func newFakeAuthorizationCodeRepository() *fakeAuthorizationCodeRepository {
+	return &fakeAuthorizationCodeRepository{codes: make(map[string]*entity.AuthorizationCode)}
+}
+
+func (r *fakeAuthorizationCodeRepository) FindByCodeHash(ctx context.Context, codeHash string) (*entity.AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, code := range r.codes {
+		if code.CodeHash == codeHash {
+			copied := *code
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("authorization code not found")
+}
+
+func (r *fakeAuthorizationCodeRepository) Create(ctx context.Context, code *entity.AuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *code
+	r.codes[code.ID] = &copied
+	return nil
+}
+
+func (r *fakeAuthorizationCodeRepository) MarkUsed(ctx context.Context, code *entity.AuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.codes[code.ID]
+	if !ok || stored.UsedAt != nil {
+		return errors.New("authorization code already used")
+	}
+	stored.UsedAt = code.UsedAt
+	return nil
+}
+
+func (r *fakeAuthorizationCodeRepository) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// fakeLinkedIdentityRepository keeps linked identities in memory
+type fakeLinkedIdentityRepository struct {
+	mu         sync.Mutex
+	identities map[string]*entity.LinkedIdentity
+}
+
+func newFakeLinkedIdentityRepository() *fakeLinkedIdentityRepository {
+	return &fakeLinkedIdentityRepository{identities: make(map[string]*entity.LinkedIdentity)}
+}
+
+func (r *fakeLinkedIdentityRepository) FindByID(ctx context.Context, id string) (*entity.LinkedIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity, ok := r.identities[id]
+	if !ok {
+		return nil, errors.New("linked identity not found")
+	}
+	copied := *identity
+	return &copied, nil
+}
+
+func (r *fakeLinkedIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.LinkedIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("linked identity not found")
+}
+
+func (r *fakeLinkedIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.LinkedIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var identities []*entity.LinkedIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			copied := *identity
+			identities = append(identities, &copied)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeLinkedIdentityRepository) Create(ctx context.Context, identity *entity.LinkedIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *identity
+	r.identities[identity.ID] = &copied
+	return nil
+}
+
+func (r *fakeLinkedIdentityRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.identities, id)
+	return nil
+}
+
+func (r *fakeLinkedIdentityRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, identity := range r.identities {
+		if identity.UserID == userID {
+			delete(r.identities, id)
+		}
+	}
+	return nil
+}
+
+// fakeTransactor runs the function without a transaction
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeMailer records sent emails
+type fakeMailer struct {
+	mu     sync.Mutex
+	emails []service.Email
+}
+
+func (m *fakeMailer) Send(ctx context.Context, email service.Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emails = append(m.emails, email)
+	return nil
+}
+
+// fakeOutboxRepository records outbox messages
+type fakeOutboxRepository struct {
+	mu       sync.Mutex
+	messages []*entity.OutboxMessage
+}
+
+func (r *fakeOutboxRepository) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeOutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.OutboxMessage, error) {
+	return nil, nil
+}
+
+func (r *fakeOutboxRepository) Update(ctx context.Context, message *entity.OutboxMessage) error {
+	return nil
+}
+
+func (r *fakeOutboxRepository) MoveToDeadLetter(ctx context.Context, message *entity.OutboxMessage) error {
+	return nil
+}
+
+func (r *fakeOutboxRepository) DeleteDelivered(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+// fakePersonalAccessTokenRepository keeps personal access tokens in memory
+type fakePersonalAccessTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*entity.PersonalAccessToken
+}
+
+func newFakePersonalAccessTokenRepository() *fakePersonalAccessTokenRepository {
+	return &fakePersonalAccessTokenRepository{tokens: make(map[string]*entity.PersonalAccessToken)}
+}
+
+func (r *fakePersonalAccessTokenRepository) FindByID(ctx context.Context, id string) (*entity.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, errors.New("personal access token not found")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakePersonalAccessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("personal access token not found")
+}
+
+func (r *fakePersonalAccessTokenRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []*entity.PersonalAccessToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			copied := *token
+			tokens = append(tokens, &copied)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakePersonalAccessTokenRepository) Create(ctx context.Context, token *entity.PersonalAccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakePersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, token *entity.PersonalAccessToken) error {
+	return nil
+}
+
+func (r *fakePersonalAccessTokenRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, id)
+	return nil
+}
+
+func (r *fakePersonalAccessTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+// fakePasswordResetTokenRepository keeps password reset tokens in memory
+type fakePasswordResetTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*entity.PasswordResetToken
+}
+
+func newFakePasswordResetTokenRepository() *fakePasswordResetTokenRepository {
+	return &fakePasswordResetTokenRepository{tokens: make(map[string]*entity.PasswordResetToken)}
+}
+
+func (r *fakePasswordResetTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("password reset token not found")
+}
+
+func (r *fakePasswordResetTokenRepository) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakePasswordResetTokenRepository) MarkUsed(ctx context.Context, token *entity.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tokens[token.ID]
+	if !ok || stored.UsedAt != nil {
+		return errors.New("password reset token already used")
+	}
+	stored.UsedAt = token.UsedAt
+	return nil
+}
+
+func (r *fakePasswordResetTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+// fakeMagicLinkTokenRepository keeps magic link tokens in memory
+type fakeMagicLinkTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*entity.MagicLinkToken
+}
+
+func newFakeMagicLinkTokenRepository() *fakeMagicLinkTokenRepository {
+	return &fakeMagicLinkTokenRepository{tokens: make(map[string]*entity.MagicLinkToken)}
+}
+
+func (r *fakeMagicLinkTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.MagicLinkToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("magic link token not found")
+}
+
+func (r *fakeMagicLinkTokenRepository) Create(ctx context.Context, token *entity.MagicLinkToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakeMagicLinkTokenRepository) MarkUsed(ctx context.Context, token *entity.MagicLinkToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tokens[token.ID]
+	if !ok || stored.UsedAt != nil {
+		return errors.New("magic link token already used")
+	}
+	stored.UsedAt = token.UsedAt
+	return nil
+}
+
+func (r *fakeMagicLinkTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+// fakeEmailChangeRepository keeps email changes in memory
+type fakeEmailChangeRepository struct {
+	mu      sync.Mutex
+	changes map[string]*entity.EmailChange
+}
+
+func newFakeEmailChangeRepository() *fakeEmailChangeRepository {
+	return &fakeEmailChangeRepository{changes: make(map[string]*entity.EmailChange)}
+}
+
+func (r *fakeEmailChangeRepository) findBy(match func(*entity.EmailChange) bool) (*entity.EmailChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, change := range r.changes {
+		if match(change) {
+			copied := *change
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("email change not found")
+}
+
+func (r *fakeEmailChangeRepository) FindByConfirmTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	return r.findBy(func(change *entity.EmailChange) bool { return change.ConfirmTokenHash == tokenHash })
+}
+
+func (r *fakeEmailChangeRepository) FindByRevertTokenHash(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	return r.findBy(func(change *entity.EmailChange) bool { return change.RevertTokenHash == tokenHash })
+}
+
+func (r *fakeEmailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *change
+	r.changes[change.ID] = &copied
+	return nil
+}
+
+func (r *fakeEmailChangeRepository) MarkConfirmed(ctx context.Context, change *entity.EmailChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.changes[change.ID]
+	if !ok || stored.ConfirmedAt != nil || stored.RevertedAt != nil {
+		return errors.New("email change already confirmed or reverted")
+	}
+	stored.ConfirmedAt = change.ConfirmedAt
+	return nil
+}
+
+func (r *fakeEmailChangeRepository) MarkReverted(ctx context.Context, change *entity.EmailChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.changes[change.ID]
+	if !ok || stored.RevertedAt != nil {
+		return errors.New("email change already reverted")
+	}
+	stored.RevertedAt = change.RevertedAt
+	return nil
+}
+
+func (r *fakeEmailChangeRepository) DeletePendingByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, change := range r.changes {
+		if change.UserID == userID && change.ConfirmedAt == nil && change.RevertedAt == nil {
+			delete(r.changes, id)
+		}
+	}
+	return nil
+}
+
+func (r *fakeEmailChangeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, change := range r.changes {
+		if change.UserID == userID {
+			delete(r.changes, id)
+		}
+	}
+	return nil
+}
+
+// fakeAccountDeletionRepository keeps account deletions in memory
+type fakeAccountDeletionRepository struct {
+	mu        sync.Mutex
+	deletions map[string]*entity.AccountDeletion
+}
+
+func newFakeAccountDeletionRepository() *fakeAccountDeletionRepository {
+	return &fakeAccountDeletionRepository{deletions: make(map[string]*entity.AccountDeletion)}
+}
+
+func (r *fakeAccountDeletionRepository) Create(ctx context.Context, deletion *entity.AccountDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *deletion
+	r.deletions[deletion.ID] = &copied
+	return nil
+}
+
+func (r *fakeAccountDeletionRepository) FindLatestByUserID(ctx context.Context, userID string) (*entity.AccountDeletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *entity.AccountDeletion
+	for _, deletion := range r.deletions {
+		if deletion.UserID == userID && (latest == nil || deletion.CreatedAt.After(latest.CreatedAt)) {
+			latest = deletion
+		}
+	}
+	if latest == nil {
+		return nil, errors.New("account deletion not found")
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+func (r *fakeAccountDeletionRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.AccountDeletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deletions []*entity.AccountDeletion
+	for _, deletion := range r.deletions {
+		if len(deletions) == limit {
+			break
+		}
+		if deletion.IsActive() && !deletion.NextAttemptAt.After(now) {
+			deletion.Lease(now.Add(lease))
+			copied := *deletion
+			deletions = append(deletions, &copied)
+		}
+	}
+	return deletions, nil
+}
+
+func (r *fakeAccountDeletionRepository) UpdateProgress(ctx context.Context, deletion *entity.AccountDeletion, step int, status entity.AccountDeletionStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.deletions[deletion.ID]
+	if !ok || stored.Step != step || stored.Status != status {
+		return errors.New("account deletion changed since it was claimed")
+	}
+	copied := *deletion
+	r.deletions[deletion.ID] = &copied
+	return nil
+}
+
+func (r *fakeAccountDeletionRepository) MarkCancelled(ctx context.Context, deletion *entity.AccountDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.deletions[deletion.ID]
+	if !ok || stored.Status != entity.AccountDeletionScheduled {
+		return errors.New("account deletion already started")
+	}
+	stored.Status = deletion.Status
+	stored.UpdatedAt = deletion.UpdatedAt
+	return nil
+}
+
+// fakeErasureParticipant records the erasures it was asked to make, failing to erase while err is set
+type fakeErasureParticipant struct {
+	mu        sync.Mutex
+	err       error
+	erased    []string
+	reverted  []string
+	finalized []string
+}
+
+func (p *fakeErasureParticipant) Name() string {
+	return "fake_service"
+}
+
+func (p *fakeErasureParticipant) Erase(ctx context.Context, erasureID, userID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.erased = append(p.erased, userID)
+	return nil
+}
+
+func (p *fakeErasureParticipant) Revert(ctx context.Context, erasureID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reverted = append(p.reverted, erasureID)
+	return nil
+}
+
+func (p *fakeErasureParticipant) Finalize(ctx context.Context, erasureID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finalized = append(p.finalized, erasureID)
+	return nil
+}
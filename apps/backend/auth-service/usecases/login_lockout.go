@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+)
+
+// dummyPassword is hashed at startup; its hash is compared against when the email is unknown,
+// so those logins take as long as a wrong password
+const dummyPassword = "not-a-real-password"
+
+// LoginLockedError is returned when logins are temporarily locked after repeated failures
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *LoginLockedError) Error() string {
+	return "too many failed login attempts, please try again later"
+}
+
+// lockoutKeys returns the failed login tracking keys for an account and a client IP
+func lockoutKeys(email string, client valueobject.ClientInfo) (accountKey, ipKey string) {
+	return "account:" + strings.ToLower(strings.TrimSpace(email)), "ip:" + client.IPAddress
+}
+
+// mfaAttemptKeys returns the tracking keys for wrong second factor codes of an MFA challenge and of its user.
+// The user's key is only reset by a correct code, so logging in again with the password does not restart the count.
+func mfaAttemptKeys(challengeID, userID string) (challengeKey, userKey string) {
+	return "mfa_challenge:" + challengeID, "mfa_user:" + userID
+}
+
+// checkLockout returns a LoginLockedError if any of the keys is locked
+func (uc *AuthUseCase) checkLockout(ctx context.Context, now time.Time, keys ...string) error {
+	var retryAfter time.Duration
+	for _, key := range keys {
+		attempt, err := uc.loginAttemptRepo.FindByKey(ctx, key)
+		if err != nil {
+			return err
+		}
+		if d := attempt.RetryAfter(now); d > retryAfter {
+			retryAfter = d
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the account and client IP,
+// emitting a security event when either becomes locked. The user is nil for unknown emails.
+func (uc *AuthUseCase) recordLoginFailure(ctx context.Context, now time.Time, user *entity.User, email string, client valueobject.ClientInfo) {
+	accountKey, ipKey := lockoutKeys(email, client)
+
+	thresholds := map[string]int{
+		accountKey: uc.lockoutConfig.AccountThreshold,
+		ipKey:      uc.lockoutConfig.IPThreshold,
+	}
+	scopes := map[string]string{
+		accountKey: "account",
+		ipKey:      "ip",
+	}
+
+	for _, key := range []string{accountKey, ipKey} {
+		attempt, err := uc.loginAttemptRepo.RecordFailure(ctx, key, now, uc.lockoutDuration(thresholds[key]))
+		if err != nil {
+			log.Printf("failed to record login failure for %s: %v", key, err)
+			continue
+		}
+
+		if !attempt.IsLocked(now) {
+			continue
+		}
+
+		event := service.SecurityEvent{
+			Type:      service.SecurityEventLoginLockout,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			Outcome:   service.SecurityEventFailure,
+			Reason:    "too_many_failures",
+			Details: map[string]string{
+				"scope":        scopes[key],
+				"email":        email,
+				"failures":     strconv.Itoa(attempt.Failures),
+				"locked_until": attempt.LockedUntil.Format(time.RFC3339),
+			},
+			OccurredAt: now,
+		}
+		if user != nil {
+			event.UserID = user.ID
+		}
+		publishSecurityEvent(ctx, uc.eventPublisher, event)
+	}
+}
+
+// recordMFAFailure counts a wrong second factor code against the challenge, the user,
+// and the account and client IP of the login lockout
+func (uc *AuthUseCase) recordMFAFailure(ctx context.Context, now time.Time, user *entity.User, challengeKey, userKey string, client valueobject.ClientInfo) {
+	// The challenge is rejected once it reaches the attempt limit, so it needs no lock
+	noLock := func(int) time.Duration { return 0 }
+	if _, err := uc.loginAttemptRepo.RecordFailure(ctx, challengeKey, now, noLock); err != nil {
+		log.Printf("failed to record MFA failure for %s: %v", challengeKey, err)
+	}
+
+	attempt, err := uc.loginAttemptRepo.RecordFailure(ctx, userKey, now, uc.lockoutDuration(uc.lockoutConfig.AccountThreshold))
+	if err != nil {
+		log.Printf("failed to record MFA failure for %s: %v", userKey, err)
+	} else if attempt.IsLocked(now) {
+		publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+			Type:      service.SecurityEventLoginLockout,
+			UserID:    user.ID,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			Outcome:   service.SecurityEventFailure,
+			Reason:    "too_many_failures",
+			Details: map[string]string{
+				"scope":        "mfa",
+				"email":        user.Email,
+				"failures":     strconv.Itoa(attempt.Failures),
+				"locked_until": attempt.LockedUntil.Format(time.RFC3339),
+			},
+			OccurredAt: now,
+		})
+	}
+
+	uc.recordLoginFailure(ctx, now, user, user.Email, client)
+}
+
+// lockoutDuration returns the exponential backoff for a failure count: nothing below the threshold,
+// then the base delay doubling with each further failure up to the maximum
+func (uc *AuthUseCase) lockoutDuration(threshold int) func(failures int) time.Duration {
+	return func(failures int) time.Duration {
+		if failures < threshold {
+			return 0
+		}
+
+		d := uc.lockoutConfig.BaseDelay
+		for i := threshold; i < failures && d < uc.lockoutConfig.MaxDelay; i++ {
+			d *= 2
+		}
+		if d > uc.lockoutConfig.MaxDelay {
+			d = uc.lockoutConfig.MaxDelay
+		}
+		return d
+	}
+}
+
+// UnlockUser lifts the lockout on a user's account
+func (uc *AuthUseCase) UnlockUser(ctx context.Context, userID string) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	accountKey, _ := lockoutKeys(user.Email, valueobject.ClientInfo{})
+	if err := uc.loginAttemptRepo.Reset(ctx, accountKey); err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+)
+
+// MagicLinkUseCase implements passwordless login with emailed sign-in links
+type MagicLinkUseCase struct {
+	magicLinkRepo   repository.MagicLinkTokenRepository
+	userRepo        repository.UserRepository
+	mailer          service.Mailer
+	authUseCase     *AuthUseCase
+	magicLinkConfig config.MagicLinkConfig
+}
+
+// NewMagicLinkUseCase creates a new magic link use case
+func NewMagicLinkUseCase(
+	magicLinkRepo repository.MagicLinkTokenRepository,
+	userRepo repository.UserRepository,
+	mailer service.Mailer,
+	authUseCase *AuthUseCase,
+	magicLinkConfig config.MagicLinkConfig,
+) *MagicLinkUseCase {
+	return &MagicLinkUseCase{
+		magicLinkRepo:   magicLinkRepo,
+		userRepo:        userRepo,
+		mailer:          mailer,
+		authUseCase:     authUseCase,
+		magicLinkConfig: magicLinkConfig,
+	}
+}
+
+// Request emails a single-use sign-in link and returns the nonce the requesting browser must keep.
+// The link is sent in the background, so neither the response nor its timing reveals whether the email belongs to an account.
+func (uc *MagicLinkUseCase) Request(ctx context.Context, email string) (string, error) {
+	// Generate browser nonce
+	nonce, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	// Find user by email
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nonce, nil
+	}
+
+	runInBackground("send sign-in link to user "+user.ID, func(ctx context.Context) error {
+		return uc.sendLink(ctx, user, nonce)
+	})
+	return nonce, nil
+}
+
+// sendLink replaces the user's sign-in links with a new one bound to the browser nonce and emails it
+func (uc *MagicLinkUseCase) sendLink(ctx context.Context, user *entity.User, nonce string) error {
+	// Invalidate previously issued links
+	if err := uc.magicLinkRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// Generate link token
+	rawToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(uc.magicLinkConfig.TokenTTL)
+
+	magicLink, err := entity.NewMagicLinkToken(uuid.New().String(), user.ID, hashToken(rawToken), hashToken(nonce), expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// Save token to database
+	if err := uc.magicLinkRepo.Create(ctx, magicLink); err != nil {
+		return err
+	}
+
+	// Send sign-in link
+	link := uc.magicLinkConfig.URL + "?token=" + url.QueryEscape(rawToken)
+	return uc.mailer.Send(ctx, service.Email{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to sign in. It expires in %d minutes and only works in the browser where you requested it.\n\n%s\n\nIf you did not request a sign-in link, you can ignore this email.\n",
+			user.Username, int(uc.magicLinkConfig.TokenTTL.Minutes()), link),
+	})
+}
+
+// Consume exchanges a sign-in link for tokens, checking the nonce of the browser that requested it.
+// Users with MFA still get an MFA token instead of tokens.
+func (uc *MagicLinkUseCase) Consume(ctx context.Context, token, nonce string, client valueobject.ClientInfo) (*dto.LoginResponse, error) {
+	// Find link token in database
+	magicLink, err := uc.magicLinkRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, errors.New("invalid or expired sign-in link")
+	}
+
+	if magicLink.IsUsed() || magicLink.IsExpired() {
+		return nil, errors.New("invalid or expired sign-in link")
+	}
+
+	// Links only work in the browser that requested them
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(magicLink.NonceHash), []byte(hashToken(nonce))) != 1 {
+		return nil, errors.New("this sign-in link was requested from another browser")
+	}
+
+	// Consume link token
+	if err := magicLink.MarkUsed(); err != nil {
+		return nil, errors.New("invalid or expired sign-in link")
+	}
+	if err := uc.magicLinkRepo.MarkUsed(ctx, magicLink); err != nil {
+		return nil, errors.New("invalid or expired sign-in link")
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, magicLink.UserID)
+	if err != nil {
+		return nil, errors.New("invalid or expired sign-in link")
+	}
+
+	// Opening the link proves ownership of the email address
+	if !user.Verified {
+		user.VerifyEmail()
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.authUseCase.completeLogin(ctx, user, "magic_link", client)
+}
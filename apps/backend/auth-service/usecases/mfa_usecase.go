@@ -0,0 +1,393 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+)
+
+const (
+	// totpPeriod is the TOTP time step in seconds
+	totpPeriod = 30
+
+	// recoveryCodeCount is the number of recovery codes issued at once
+	recoveryCodeCount = 10
+
+	// mfaChallengeLogin marks a challenge that is completed with a TOTP or recovery code
+	mfaChallengeLogin = "mfa_login"
+
+	// mfaChallengeEnrollment marks a challenge that is completed by enrolling in MFA
+	mfaChallengeEnrollment = "mfa_enrollment"
+)
+
+// MFAUseCase implements two-factor authentication use cases
+type MFAUseCase struct {
+	userRepo         repository.UserRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+	eventPublisher   service.SecurityEventPublisher
+	mfaConfig        config.MFAConfig
+
+	// now returns the current time and can be replaced with a fixed clock
+	now func() time.Time
+}
+
+// NewMFAUseCase creates a new MFA use case
+func NewMFAUseCase(userRepo repository.UserRepository, recoveryCodeRepo repository.RecoveryCodeRepository, eventPublisher service.SecurityEventPublisher, mfaConfig config.MFAConfig) *MFAUseCase {
+	return &MFAUseCase{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		eventPublisher:   eventPublisher,
+		mfaConfig:        mfaConfig,
+		now:              time.Now,
+	}
+}
+
+// Enroll starts MFA enrollment and returns the TOTP secret as an otpauth URI and QR code
+func (uc *MFAUseCase) Enroll(ctx context.Context, userID string) (*dto.MFAEnrollResponse, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate TOTP key
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      uc.mfaConfig.Issuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.BeginMFAEnrollment(key.Secret()); err != nil {
+		return nil, err
+	}
+
+	// Render QR code
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	// Save pending secret
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollResponse{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+		QRCodePNG:  base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// Confirm activates MFA with a code from the authenticator app and returns fresh recovery codes
+func (uc *MFAUseCase) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := uc.matchTOTP(user.MFAPendingSecret, code)
+	if !ok {
+		return nil, errors.New("invalid two-factor authentication code")
+	}
+
+	if err := user.ConfirmMFAEnrollment(); err != nil {
+		return nil, err
+	}
+	user.MFALastUsedStep = step
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := uc.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:   service.SecurityEventMFAEnabled,
+		UserID: user.ID,
+	})
+	return recoveryCodes, nil
+}
+
+// Disable turns off MFA after checking a TOTP or recovery code
+func (uc *MFAUseCase) Disable(ctx context.Context, userID, code string) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := uc.VerifyCode(ctx, user, code); err != nil {
+		uc.codeRefused(ctx, service.SecurityEventMFADisabled, user.ID)
+		return err
+	}
+
+	if err := user.DisableMFA(); err != nil {
+		return err
+	}
+
+	// Save user to database
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := uc.recoveryCodeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:   service.SecurityEventMFADisabled,
+		UserID: user.ID,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a TOTP or recovery code
+func (uc *MFAUseCase) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MFAEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := uc.VerifyCode(ctx, user, code); err != nil {
+		uc.codeRefused(ctx, service.SecurityEventRecoveryCodesRegenerated, user.ID)
+		return nil, err
+	}
+
+	recoveryCodes, err := uc.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:   service.SecurityEventRecoveryCodesRegenerated,
+		UserID: user.ID,
+	})
+	return recoveryCodes, nil
+}
+
+// codeRefused publishes a change to a user's MFA settings that was refused for a wrong code
+func (uc *MFAUseCase) codeRefused(ctx context.Context, eventType service.SecurityEventType, userID string) {
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    eventType,
+		UserID:  userID,
+		Outcome: service.SecurityEventFailure,
+		Reason:  "invalid_code",
+	})
+}
+
+// VerifyCode checks a TOTP code or consumes a recovery code.
+// A TOTP code is only accepted once, even by concurrent requests, so an intercepted code cannot be replayed.
+func (uc *MFAUseCase) VerifyCode(ctx context.Context, user *entity.User, code string) error {
+	code = strings.TrimSpace(code)
+
+	// Try TOTP first
+	if step, ok := uc.matchTOTP(user.MFASecret, code); ok {
+		if step <= user.MFALastUsedStep {
+			return errors.New("invalid two-factor authentication code")
+		}
+		user.MFALastUsedStep = step
+		if err := uc.userRepo.MarkMFAStepUsed(ctx, user); err != nil {
+			return errors.New("invalid two-factor authentication code")
+		}
+		return nil
+	}
+
+	// Fall back to recovery codes
+	codes, err := uc.recoveryCodeRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	codeHash := hashToken(normalizeRecoveryCode(code))
+	for _, rc := range codes {
+		if rc.IsUsed() || subtle.ConstantTimeCompare([]byte(rc.CodeHash), []byte(codeHash)) != 1 {
+			continue
+		}
+		if err := rc.MarkUsed(); err != nil {
+			break
+		}
+		if err := uc.recoveryCodeRepo.MarkUsed(ctx, rc); err != nil {
+			break
+		}
+		return nil
+	}
+
+	return errors.New("invalid two-factor authentication code")
+}
+
+// IssueChallenge creates a short-lived challenge token that stands in for the login response
+func (uc *MFAUseCase) IssueChallenge(user *entity.User, purpose string) (string, error) {
+	now := uc.now()
+	claims := jwt.MapClaims{
+		"sub":     user.ID,
+		"purpose": purpose,
+		"exp":     now.Add(uc.mfaConfig.ChallengeTTL).Unix(),
+		"iat":     now.Unix(),
+		"jti":     uuid.New().String(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(uc.mfaConfig.ChallengeSecret))
+}
+
+// ParseChallenge validates a challenge token and returns the user it was issued to
+func (uc *MFAUseCase) ParseChallenge(ctx context.Context, challenge, purpose string) (*entity.User, error) {
+	user, _, err := uc.parseChallenge(ctx, challenge, purpose)
+	return user, err
+}
+
+// parseChallenge validates a challenge token and returns the user it was issued to and the challenge's ID.
+// The challenge must still fit the user: a login challenge needs MFA enabled, and an enrollment challenge
+// needs a user who must enroll and has not yet.
+func (uc *MFAUseCase) parseChallenge(ctx context.Context, challenge, purpose string) (*entity.User, string, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.Parse(challenge, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(uc.mfaConfig.ChallengeSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, "", errors.New("invalid or expired MFA token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose || !claims.VerifyExpiresAt(uc.now().Unix(), true) {
+		return nil, "", errors.New("invalid or expired MFA token")
+	}
+
+	challengeID, _ := claims["jti"].(string)
+	if challengeID == "" {
+		return nil, "", errors.New("invalid or expired MFA token")
+	}
+
+	userID, _ := claims["sub"].(string)
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, "", errors.New("invalid or expired MFA token")
+	}
+
+	switch purpose {
+	case mfaChallengeLogin:
+		if !user.MFAEnabled {
+			return nil, "", errors.New("invalid or expired MFA token")
+		}
+	case mfaChallengeEnrollment:
+		if user.MFAEnabled || !user.RequiresMFAEnrollment() {
+			return nil, "", errors.New("invalid or expired MFA token")
+		}
+	}
+
+	return user, challengeID, nil
+}
+
+// matchTOTP checks a code against the current, previous and next time steps and returns the matching step
+func (uc *MFAUseCase) matchTOTP(secret, code string) (int64, bool) {
+	if secret == "" || len(code) != otp.DigitsSix.Length() {
+		return 0, false
+	}
+
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+
+	now := uc.now()
+	current := now.Unix() / totpPeriod
+	for _, step := range []int64{current - 1, current, current + 1} {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// issueRecoveryCodes generates a new set of recovery codes, replacing any existing ones
+func (uc *MFAUseCase) issueRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	plain := make([]string, recoveryCodeCount)
+	codes := make([]*entity.RecoveryCode, recoveryCodeCount)
+	for i := range plain {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		rc, err := entity.NewRecoveryCode(uuid.New().String(), userID, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, err
+		}
+
+		plain[i] = code
+		codes[i] = rc
+	}
+
+	if err := uc.recoveryCodeRepo.ReplaceForUser(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// generateRecoveryCode generates a random recovery code such as "k3j9d-2mx8q"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// normalizeRecoveryCode makes recovery code comparison insensitive to case and separators
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,404 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/repository"
+)
+
+const testPassword = "correct horse battery staple"
+
+// mfaTest is an auth use case with in-memory repositories and a fixed clock that tests move forward by hand
+type mfaTest struct {
+	t             *testing.T
+	auth          *AuthUseCase
+	users         *fakeUserRepository
+	recoveryCodes *fakeRecoveryCodeRepository
+	tokens        *fakeTokenRepository
+	events        *fakeEventPublisher
+	clock         time.Time
+	client        valueobject.ClientInfo
+}
+
+func newMFATest(t *testing.T, role valueobject.UserRole) *mfaTest {
+	t.Helper()
+
+	user, err := entity.NewUser("user-1", "alice@example.com", "alice", testPassword, fakeHasher{})
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	user.Role = role
+
+	test := &mfaTest{
+		t:             t,
+		users:         newFakeUserRepository(user),
+		recoveryCodes: newFakeRecoveryCodeRepository(),
+		tokens:        newFakeTokenRepository(),
+		events:        &fakeEventPublisher{},
+		clock:         time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		client:        valueobject.ClientInfo{IPAddress: "192.0.2.1", UserAgent: "test"},
+	}
+
+	mfa := NewMFAUseCase(test.users, test.recoveryCodes, test.events, config.MFAConfig{
+		Issuer:          "Test",
+		ChallengeSecret: "challenge-secret",
+		ChallengeTTL:    5 * time.Minute,
+		MaxAttempts:     3,
+	})
+	mfa.now = func() time.Time { return test.clock }
+
+	test.auth = NewAuthUseCase(
+		test.users,
+		test.tokens,
+		nil,
+		repository.NewMemoryLoginAttemptRepository(24*time.Hour),
+		nil,
+		nil,
+		nil,
+		test.events,
+		fakeSigner{},
+		fakeHasher{},
+		nil,
+		mfa,
+		config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 24 * time.Hour, Issuer: "test"},
+		config.PasswordResetConfig{},
+		config.EmailVerificationConfig{},
+		config.LockoutConfig{
+			AccountThreshold: 4,
+			IPThreshold:      100,
+			BaseDelay:        30 * time.Second,
+			MaxDelay:         time.Hour,
+			Window:           24 * time.Hour,
+		},
+	)
+	return test
+}
+
+// advance moves the clock forward
+func (test *mfaTest) advance(d time.Duration) {
+	test.clock = test.clock.Add(d)
+}
+
+// code returns the TOTP code for the clock's current time step
+func (test *mfaTest) code(secret string) string {
+	test.t.Helper()
+	code, err := totp.GenerateCodeCustom(secret, test.clock, totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		test.t.Fatalf("GenerateCodeCustom: %v", err)
+	}
+	return code
+}
+
+// enroll turns on MFA for the user and returns the TOTP secret and recovery codes
+func (test *mfaTest) enroll() (string, []string) {
+	test.t.Helper()
+	ctx := context.Background()
+
+	enrollment, err := test.auth.mfa.Enroll(ctx, "user-1")
+	if err != nil {
+		test.t.Fatalf("Enroll: %v", err)
+	}
+	if enrollment.OTPAuthURI == "" || enrollment.QRCodePNG == "" {
+		test.t.Fatalf("Enroll returned no otpauth URI or QR code")
+	}
+
+	recoveryCodes, err := test.auth.mfa.Confirm(ctx, "user-1", test.code(enrollment.Secret))
+	if err != nil {
+		test.t.Fatalf("Confirm: %v", err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		test.t.Fatalf("Confirm returned %d recovery codes, want %d", len(recoveryCodes), recoveryCodeCount)
+	}
+
+	// The enrollment code's time step cannot be used again
+	test.advance(totpPeriod * time.Second)
+	return enrollment.Secret, recoveryCodes
+}
+
+// login logs in with the password and returns the MFA challenge
+func (test *mfaTest) login() string {
+	test.t.Helper()
+
+	response, err := test.auth.Login(context.Background(), "alice@example.com", testPassword, test.client)
+	if err != nil {
+		test.t.Fatalf("Login: %v", err)
+	}
+	if response.TokenResponse != nil || !response.MFARequired || response.MFAToken == "" {
+		test.t.Fatalf("Login did not return an MFA challenge: %+v", response)
+	}
+	return response.MFAToken
+}
+
+// verify completes a login with a code
+func (test *mfaTest) verify(challenge, code string) error {
+	_, err := test.auth.VerifyMFA(context.Background(), challenge, code, test.client)
+	return err
+}
+
+func TestVerifyMFAWithTOTP(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	challenge := test.login()
+	tokens, err := test.auth.VerifyMFA(context.Background(), challenge, test.code(secret), test.client)
+	if err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("VerifyMFA returned no tokens: %+v", tokens)
+	}
+}
+
+func TestVerifyMFAAcceptsAdjacentTimeSteps(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	// A code from the next time step is accepted, as the client's clock may run ahead
+	test.advance(totpPeriod * time.Second)
+	code := test.code(secret)
+	test.advance(-totpPeriod * time.Second)
+	if err := test.verify(test.login(), code); err != nil {
+		t.Fatalf("VerifyMFA with the next step's code: %v", err)
+	}
+
+	// A code two steps old is not
+	test.advance(3 * totpPeriod * time.Second)
+	code = test.code(secret)
+	test.advance(2 * totpPeriod * time.Second)
+	if err := test.verify(test.login(), code); err == nil {
+		t.Fatal("VerifyMFA accepted a code from two time steps ago")
+	}
+}
+
+func TestVerifyMFARejectsReplayedCode(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	code := test.code(secret)
+	if err := test.verify(test.login(), code); err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+	if err := test.verify(test.login(), code); err == nil {
+		t.Fatal("VerifyMFA accepted a code that was already used")
+	}
+}
+
+func TestVerifyMFAWithRecoveryCode(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	_, recoveryCodes := test.enroll()
+
+	if err := test.verify(test.login(), recoveryCodes[0]); err != nil {
+		t.Fatalf("VerifyMFA with a recovery code: %v", err)
+	}
+	if err := test.verify(test.login(), recoveryCodes[0]); err == nil {
+		t.Fatal("VerifyMFA accepted a recovery code twice")
+	}
+}
+
+func TestVerifyMFARejectsExpiredChallenge(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	challenge := test.login()
+	test.advance(6 * time.Minute)
+	if err := test.verify(challenge, test.code(secret)); err == nil {
+		t.Fatal("VerifyMFA accepted an expired challenge")
+	}
+}
+
+func TestVerifyMFARejectsChallengeAfterTooManyWrongCodes(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	challenge := test.login()
+	for i := 0; i < 3; i++ {
+		if err := test.verify(challenge, "000000"); err == nil {
+			t.Fatal("VerifyMFA accepted a wrong code")
+		}
+	}
+
+	// Even the right code no longer completes the challenge
+	if err := test.verify(challenge, test.code(secret)); !errors.Is(err, ErrMFAAttemptsExhausted) {
+		t.Fatalf("VerifyMFA after too many wrong codes: got %v, want %v", err, ErrMFAAttemptsExhausted)
+	}
+}
+
+func TestVerifyMFALocksOutAcrossChallenges(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	// Logging in again with the password does not restart the count of wrong codes
+	for i := 0; i < 2; i++ {
+		challenge := test.login()
+		for j := 0; j < 2; j++ {
+			if err := test.verify(challenge, "000000"); err == nil {
+				t.Fatal("VerifyMFA accepted a wrong code")
+			}
+		}
+	}
+
+	var lockedErr *LoginLockedError
+	if err := test.verify(test.login(), test.code(secret)); !errors.As(err, &lockedErr) {
+		t.Fatalf("VerifyMFA after too many wrong codes: got %v, want a LoginLockedError", err)
+	}
+
+	// The lock wears off
+	test.advance(lockedErr.RetryAfter)
+	if err := test.verify(test.login(), test.code(secret)); err != nil {
+		t.Fatalf("VerifyMFA after the lockout: %v", err)
+	}
+}
+
+func TestLoginRequiresAdminsToEnroll(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleAdmin)
+
+	response, err := test.auth.Login(context.Background(), "alice@example.com", testPassword, test.client)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if response.TokenResponse != nil || !response.MFAEnrollmentRequired {
+		t.Fatalf("Login did not require an admin to enroll: %+v", response)
+	}
+
+	// The enrollment challenge cannot be used to verify a code
+	if err := test.verify(response.MFAToken, "000000"); err == nil {
+		t.Fatal("VerifyMFA accepted an enrollment challenge")
+	}
+}
+
+func TestVerifyMFARejectsConcurrentReplay(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	// Both requests load the user before either marks the code as used
+	first, err := test.users.FindByID(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	second := *first
+
+	code := test.code(secret)
+	if err := test.auth.mfa.VerifyCode(context.Background(), first, code); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+	if err := test.auth.mfa.VerifyCode(context.Background(), &second, code); err == nil {
+		t.Fatal("VerifyCode accepted a code another request already used")
+	}
+}
+
+func TestVerifyMFARefusesAccountDisabledAfterPassword(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	secret, _ := test.enroll()
+
+	challenge := test.login()
+	user, _ := test.users.FindByID(context.Background(), "user-1")
+	user.Disable()
+	test.users.Update(context.Background(), user)
+
+	if err := test.verify(challenge, test.code(secret)); !errors.Is(err, ErrAccountDisabled) {
+		t.Fatalf("VerifyMFA for a disabled account: got %v, want %v", err, ErrAccountDisabled)
+	}
+}
+
+func TestMFAEnrollmentChallengeRequiresPendingEnrollment(t *testing.T) {
+	ctx := context.Background()
+
+	// A user who need not enroll cannot use an enrollment challenge
+	test := newMFATest(t, valueobject.RoleUser)
+	user, _ := test.users.FindByID(ctx, "user-1")
+	challenge, err := test.auth.mfa.IssueChallenge(user, mfaChallengeEnrollment)
+	if err != nil {
+		t.Fatalf("IssueChallenge: %v", err)
+	}
+	if _, err := test.auth.BeginMFAEnrollment(ctx, challenge); err == nil {
+		t.Fatal("BeginMFAEnrollment accepted a challenge for a user who need not enroll")
+	}
+
+	// Nor can an admin who already enrolled
+	test = newMFATest(t, valueobject.RoleAdmin)
+	test.enroll()
+	user, _ = test.users.FindByID(ctx, "user-1")
+	challenge, err = test.auth.mfa.IssueChallenge(user, mfaChallengeEnrollment)
+	if err != nil {
+		t.Fatalf("IssueChallenge: %v", err)
+	}
+	if _, err := test.auth.ConfirmMFAEnrollment(ctx, challenge, "000000", test.client); err == nil {
+		t.Fatal("ConfirmMFAEnrollment accepted a challenge for a user who already enrolled")
+	}
+}
+
+func TestConfirmMFAEnrollmentRefusesDisabledAccount(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleAdmin)
+	ctx := context.Background()
+
+	response, err := test.auth.Login(ctx, "alice@example.com", testPassword, test.client)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	enrollment, err := test.auth.BeginMFAEnrollment(ctx, response.MFAToken)
+	if err != nil {
+		t.Fatalf("BeginMFAEnrollment: %v", err)
+	}
+
+	user, _ := test.users.FindByID(ctx, "user-1")
+	user.Disable()
+	test.users.Update(ctx, user)
+
+	if _, err := test.auth.ConfirmMFAEnrollment(ctx, response.MFAToken, test.code(enrollment.Secret), test.client); !errors.Is(err, ErrAccountDisabled) {
+		t.Fatalf("ConfirmMFAEnrollment for a disabled account: got %v, want %v", err, ErrAccountDisabled)
+	}
+}
+
+func TestMFASettingChangesAreAudited(t *testing.T) {
+	test := newMFATest(t, valueobject.RoleUser)
+	ctx := valueobject.ContextWithClientInfo(context.Background(), test.client)
+	secret, _ := test.enroll()
+
+	// A wrong code is recorded as a refused attempt
+	if _, err := test.auth.mfa.RegenerateRecoveryCodes(ctx, "user-1", "000000"); err == nil {
+		t.Fatal("RegenerateRecoveryCodes accepted a wrong code")
+	}
+	if _, err := test.auth.mfa.RegenerateRecoveryCodes(ctx, "user-1", test.code(secret)); err != nil {
+		t.Fatalf("RegenerateRecoveryCodes: %v", err)
+	}
+	test.advance(totpPeriod * time.Second)
+	if err := test.auth.mfa.Disable(ctx, "user-1", test.code(secret)); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+
+	want := []struct {
+		eventType service.SecurityEventType
+		outcome   service.SecurityEventOutcome
+	}{
+		{service.SecurityEventMFAEnabled, service.SecurityEventSuccess},
+		{service.SecurityEventRecoveryCodesRegenerated, service.SecurityEventFailure},
+		{service.SecurityEventRecoveryCodesRegenerated, service.SecurityEventSuccess},
+		{service.SecurityEventMFADisabled, service.SecurityEventSuccess},
+	}
+	if len(test.events.events) != len(want) {
+		t.Fatalf("got %d security events, want %d: %+v", len(test.events.events), len(want), test.events.events)
+	}
+	for i, event := range test.events.events {
+		if event.Type != want[i].eventType || event.Outcome != want[i].outcome || event.UserID != "user-1" {
+			t.Errorf("event %d: got %s (%s) for %q, want %s (%s)", i, event.Type, event.Outcome, event.UserID, want[i].eventType, want[i].outcome)
+		}
+	}
+
+	// Changes made during a request record the client that made it
+	if last := test.events.events[len(want)-1]; last.IPAddress != test.client.IPAddress || last.UserAgent != test.client.UserAgent {
+		t.Errorf("got client %s %q, want %s %q", last.IPAddress, last.UserAgent, test.client.IPAddress, test.client.UserAgent)
+	}
+}
@@ -0,0 +1,306 @@
+package usecases_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/handlers"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+const (
+	testIssuer      = "https://auth.example.com"
+	testLoginURL    = "https://blog.example.com/login"
+	testRedirectURI = "https://app.example.com/callback"
+	testVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+// oidcServer serves the OpenID Connect provider routes in-process. The signed-in user is named by
+// the X-Test-User header, and access tokens are read back with DecodeTestToken and checked like the
+// auth middleware does, standing in for the session and bearer token middleware.
+type oidcServer struct {
+	t    *testing.T
+	e    *echo.Echo
+	oidc *usecases.OIDCUseCase
+}
+
+func newOIDCServer(t *testing.T) *oidcServer {
+	t.Helper()
+
+	user, err := entity.NewUser("user-1", "alice@example.com", "alice", "password", usecases.TestHasher)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	user.VerifyEmail()
+
+	oidcConfig := config.OIDCConfig{
+		IssuerURL:  testIssuer,
+		LoginURL:   testLoginURL,
+		CodeTTL:    time.Minute,
+		IDTokenTTL: time.Hour,
+	}
+	oidc := usecases.NewTestOIDCUseCase(oidcConfig, user)
+	handler := handlers.NewOIDCHandler(oidc, oidcConfig)
+
+	signedIn := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if userID := c.Request().Header.Get("X-Test-User"); userID != "" {
+				c.Set("user_id", userID)
+			}
+			return next(c)
+		}
+	}
+	bearer := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := usecases.DecodeTestToken(strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer "))
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+			}
+			principal, err := authz.NewPrincipal(claims, testIssuer+"/oauth/userinfo", c.Request())
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+			}
+			c.Set(authz.PrincipalKey, principal)
+			c.Set("user_id", principal.UserID())
+			return next(c)
+		}
+	}
+
+	e := echo.New()
+	e.GET("/.well-known/openid-configuration", handler.Discovery)
+	e.GET("/oauth/authorize", handler.Authorize, signedIn)
+	e.POST("/oauth/token", handler.Token)
+	e.GET("/oauth/userinfo", handler.UserInfo, bearer)
+
+	return &oidcServer{t: t, e: e, oidc: oidc}
+}
+
+// do serves a request and returns the response
+func (s *oidcServer) do(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// registerClient registers a confidential client and returns its ID and secret
+func (s *oidcServer) registerClient(name string) (string, string) {
+	s.t.Helper()
+	client, secret, err := s.oidc.RegisterClient(context.Background(), name, []string{testRedirectURI}, nil, nil, false)
+	if err != nil {
+		s.t.Fatalf("RegisterClient: %v", err)
+	}
+	return client.ID, secret
+}
+
+// authorize sends a signed-in user through the authorization endpoint and returns the redirect
+func (s *oidcServer) authorize(clientID, redirectURI string) *httptest.ResponseRecorder {
+	sum := sha256.Sum256([]byte(testVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"openid email profile"},
+		"state":                 {"state-1"},
+		"nonce":                 {"nonce-1"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+query.Encode(), nil)
+	req.Header.Set("X-Test-User", "user-1")
+	return s.do(req)
+}
+
+// token posts a token request with the client's credentials in HTTP Basic auth
+func (s *oidcServer) token(clientID, clientSecret string, form url.Values) (int, map[string]interface{}) {
+	s.t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.SetBasicAuth(clientID, clientSecret)
+	rec := s.do(req)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		s.t.Fatalf("token response is not JSON: %s", rec.Body.String())
+	}
+	return rec.Code, body
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	s := newOIDCServer(t)
+	clientID, clientSecret := s.registerClient("Reader")
+
+	// Discovery points at the provider's endpoints
+	rec := s.do(httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil))
+	var discovery dto.OpenIDConfiguration
+	if err := json.Unmarshal(rec.Body.Bytes(), &discovery); err != nil {
+		t.Fatalf("discovery document: %v", err)
+	}
+	if discovery.Issuer != testIssuer || discovery.TokenEndpoint != testIssuer+"/oauth/token" {
+		t.Fatalf("unexpected discovery document: %+v", discovery)
+	}
+
+	// Signed-out users are sent to the login page first
+	req := httptest.NewRequest(http.MethodGet, "/oauth/authorize?client_id="+clientID+"&redirect_uri="+url.QueryEscape(testRedirectURI), nil)
+	rec = s.do(req)
+	if rec.Code != http.StatusFound || !strings.HasPrefix(rec.Header().Get("Location"), testLoginURL+"?return_to=") {
+		t.Fatalf("signed-out authorize: got %d to %q, want a redirect to the login page", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Unregistered redirect URIs are refused without redirecting
+	rec = s.authorize(clientID, "https://evil.example.com/callback")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("authorize with an unregistered redirect URI: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// A signed-in user is sent back to the client with a code and the state
+	rec = s.authorize(clientID, testRedirectURI)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if rec.Code != http.StatusFound || err != nil || !strings.HasPrefix(location.String(), testRedirectURI+"?") {
+		t.Fatalf("authorize: got %d to %q, want a redirect to the client", rec.Code, rec.Header().Get("Location"))
+	}
+	if location.Query().Get("state") != "state-1" || location.Query().Get("code") == "" {
+		t.Fatalf("authorize redirect is missing the code or state: %s", location)
+	}
+	code := location.Query().Get("code")
+
+	// The code needs the PKCE verifier
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {testRedirectURI},
+		"code_verifier": {strings.Repeat("x", 43)},
+	}
+	if status, body := s.token(clientID, clientSecret, exchange); status != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Fatalf("exchange with a wrong verifier: got %d %v, want invalid_grant", status, body)
+	}
+
+	// and the client's secret
+	exchange.Set("code_verifier", testVerifier)
+	if status, body := s.token(clientID, "wrong-secret", exchange); status != http.StatusUnauthorized || body["error"] != "invalid_client" {
+		t.Fatalf("exchange with a wrong secret: got %d %v, want invalid_client", status, body)
+	}
+
+	status, tokens := s.token(clientID, clientSecret, exchange)
+	if status != http.StatusOK {
+		t.Fatalf("exchange: got %d %v", status, tokens)
+	}
+	if tokens["access_token"] == nil || tokens["refresh_token"] == nil || tokens["id_token"] == nil {
+		t.Fatalf("exchange did not return every token: %v", tokens)
+	}
+
+	// The ID token is for the client and carries the nonce and granted claims
+	idToken, err := usecases.DecodeTestToken(tokens["id_token"].(string))
+	if err != nil {
+		t.Fatalf("ID token: %v", err)
+	}
+	if idToken["iss"] != testIssuer || idToken["aud"] != clientID || idToken["sub"] != "user-1" || idToken["nonce"] != "nonce-1" {
+		t.Fatalf("unexpected ID token claims: %v", idToken)
+	}
+	if idToken["email"] != "alice@example.com" || idToken["email_verified"] != true || idToken["preferred_username"] != "alice" {
+		t.Fatalf("ID token is missing the email or profile claims: %v", idToken)
+	}
+
+	// The ID token is never accepted as an access token
+	if err := authz.CheckAccessToken(idToken, "auth-service"); err != authz.ErrNotAccessToken {
+		t.Fatalf("ID token checked as an access token: got %v, want %v", err, authz.ErrNotAccessToken)
+	}
+
+	// The access token carries the granted scope rather than the user's role, and only works at userinfo
+	accessToken, err := usecases.DecodeTestToken(tokens["access_token"].(string))
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	if accessToken["scope"] != "openid profile email" || accessToken["client_id"] != clientID || accessToken["role"] != nil {
+		t.Fatalf("unexpected access token claims: %v", accessToken)
+	}
+	if err := authz.CheckAccessToken(accessToken, "auth-service"); err != authz.ErrInvalidAudience {
+		t.Fatalf("client access token checked for the auth service: got %v, want %v", err, authz.ErrInvalidAudience)
+	}
+
+	// Codes are single-use
+	if status, body := s.token(clientID, clientSecret, exchange); status != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Fatalf("second exchange of a code: got %d %v, want invalid_grant", status, body)
+	}
+
+	// The access token works at the userinfo endpoint
+	req = httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens["access_token"].(string))
+	rec = s.do(req)
+	var userInfo dto.UserInfoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &userInfo); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("userinfo: got %d %s", rec.Code, rec.Body.String())
+	}
+	if userInfo.Sub != "user-1" || userInfo.Email != "alice@example.com" {
+		t.Fatalf("unexpected userinfo: %+v", userInfo)
+	}
+
+	// The ID token does not work there
+	req = httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens["id_token"].(string))
+	if rec = s.do(req); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("userinfo with an ID token: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	// The refresh token works for the client it was issued to
+	refresh := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {tokens["refresh_token"].(string)},
+	}
+	status, refreshed := s.token(clientID, clientSecret, refresh)
+	if status != http.StatusOK || refreshed["access_token"] == nil || refreshed["refresh_token"] == nil {
+		t.Fatalf("refresh: got %d %v", status, refreshed)
+	}
+
+	// and keeps the access token limited to the granted scope
+	refreshedToken, err := usecases.DecodeTestToken(refreshed["access_token"].(string))
+	if err != nil || refreshedToken["scope"] != "openid profile email" || refreshedToken["role"] != nil {
+		t.Fatalf("unexpected refreshed access token claims: %v (%v)", refreshedToken, err)
+	}
+}
+
+func TestOIDCRefreshTokenIsBoundToItsClient(t *testing.T) {
+	s := newOIDCServer(t)
+	clientID, clientSecret := s.registerClient("Reader")
+	otherID, otherSecret := s.registerClient("Other")
+
+	rec := s.authorize(clientID, testRedirectURI)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize redirect: %v", err)
+	}
+	status, tokens := s.token(clientID, clientSecret, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {location.Query().Get("code")},
+		"redirect_uri":  {testRedirectURI},
+		"code_verifier": {testVerifier},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("exchange: got %d %v", status, tokens)
+	}
+
+	refresh := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {tokens["refresh_token"].(string)},
+	}
+	if status, body := s.token(otherID, otherSecret, refresh); status != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Fatalf("refresh by another client: got %d %v, want invalid_grant", status, body)
+	}
+
+	// Being refused for another client does not use up the token
+	if status, body := s.token(clientID, clientSecret, refresh); status != http.StatusOK {
+		t.Fatalf("refresh by the client it was issued to: got %d %v", status, body)
+	}
+}
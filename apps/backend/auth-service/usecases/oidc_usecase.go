@@ -0,0 +1,478 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+// supportedOIDCScopes lists the scopes the provider grants; other requested scopes are ignored
+var supportedOIDCScopes = []string{"openid", "profile", "email"}
+
+// OAuthError is an OAuth 2.0 error response (RFC 6749 section 5.2)
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+// Error implements the error interface
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// OIDCUseCase implements the OpenID Connect provider use cases
+type OIDCUseCase struct {
+	clientRepo  repository.OAuthClientRepository
+	codeRepo    repository.AuthorizationCodeRepository
+	userRepo    repository.UserRepository
+	authUseCase *AuthUseCase
+	signer      service.TokenSigner
+	jwtConfig   config.JWTConfig
+	oidcConfig  config.OIDCConfig
+}
+
+// NewOIDCUseCase creates a new OIDC use case
+func NewOIDCUseCase(
+	clientRepo repository.OAuthClientRepository,
+	codeRepo repository.AuthorizationCodeRepository,
+	userRepo repository.UserRepository,
+	authUseCase *AuthUseCase,
+	signer service.TokenSigner,
+	jwtConfig config.JWTConfig,
+	oidcConfig config.OIDCConfig,
+) *OIDCUseCase {
+	return &OIDCUseCase{
+		clientRepo:  clientRepo,
+		codeRepo:    codeRepo,
+		userRepo:    userRepo,
+		authUseCase: authUseCase,
+		signer:      signer,
+		jwtConfig:   jwtConfig,
+		oidcConfig:  oidcConfig,
+	}
+}
+
+// Discovery returns the OpenID Connect discovery document
+func (uc *OIDCUseCase) Discovery() dto.OpenIDConfiguration {
+	issuer := strings.TrimSuffix(uc.oidcConfig.IssuerURL, "/")
+	return dto.OpenIDConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/oauth/userinfo",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		RevocationEndpoint:                issuer + "/oauth/revoke",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{uc.jwtConfig.SigningAlgorithm},
+		ScopesSupported:                   supportedOIDCScopes,
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "preferred_username"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}
+}
+
+// RegisterClient registers an OAuth client and returns it with its secret, which is not stored.
+// Public clients get no secret. Clients with audiences may get client-credentials tokens for them.
+func (uc *OIDCUseCase) RegisterClient(ctx context.Context, name string, redirectURIs, audiences, scopes []string, public bool) (*entity.OAuthClient, string, error) {
+	var secret, secretHash string
+	if !public {
+		var err error
+		secret, err = generateSecureToken()
+		if err != nil {
+			return nil, "", err
+		}
+		secretHash = hashToken(secret)
+	}
+
+	client, err := entity.NewOAuthClient(uuid.New().String(), name, secretHash, redirectURIs, audiences, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Save client to database
+	if err := uc.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+// EnsureServiceClient registers an internal service's client with the given ID and secret, replacing its
+// registration if it already exists, so the service can be deployed with credentials it knows in advance
+func (uc *OIDCUseCase) EnsureServiceClient(ctx context.Context, id, name, secret string, audiences []string) error {
+	client, err := entity.NewOAuthClient(id, name, hashToken(secret), nil, audiences, nil)
+	if err != nil {
+		return err
+	}
+
+	// Keep the original registration date
+	if existing, err := uc.clientRepo.FindByID(ctx, id); err == nil {
+		client.CreatedAt = existing.CreatedAt
+	}
+
+	// Save client to database
+	return uc.clientRepo.Save(ctx, client)
+}
+
+// ListClients returns all registered OAuth clients
+func (uc *OIDCUseCase) ListClients(ctx context.Context) ([]*entity.OAuthClient, error) {
+	return uc.clientRepo.FindAll(ctx)
+}
+
+// DeleteClient deletes a registered OAuth client
+func (uc *OIDCUseCase) DeleteClient(ctx context.Context, clientID string) error {
+	if _, err := uc.clientRepo.FindByID(ctx, clientID); err != nil {
+		return err
+	}
+	return uc.clientRepo.Delete(ctx, clientID)
+}
+
+// ValidateClientRedirect checks the client and redirect URI of an authorization request.
+// Its errors must be shown to the user rather than sent to the unverified redirect URI.
+func (uc *OIDCUseCase) ValidateClientRedirect(ctx context.Context, req dto.AuthorizeRequest) error {
+	client, err := uc.clientRepo.FindByID(ctx, req.ClientID)
+	if err != nil {
+		return &OAuthError{Code: "invalid_request", Description: "unknown client_id"}
+	}
+
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return &OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered for this client"}
+	}
+
+	return nil
+}
+
+// AuthorizationErrorRedirect returns the redirect URL that reports an authorization error to the client
+func (uc *OIDCUseCase) AuthorizationErrorRedirect(req dto.AuthorizeRequest, oauthErr *OAuthError) string {
+	params := url.Values{}
+	params.Set("error", oauthErr.Code)
+	params.Set("error_description", oauthErr.Description)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, params)
+}
+
+// Authorize issues an authorization code to a signed-in user and returns the redirect URL carrying it.
+// The client and redirect URI must already have been checked with ValidateClientRedirect.
+func (uc *OIDCUseCase) Authorize(ctx context.Context, userID string, req dto.AuthorizeRequest) (string, error) {
+	if req.ResponseType != "code" {
+		return uc.AuthorizationErrorRedirect(req, &OAuthError{Code: "unsupported_response_type", Description: "only the code response type is supported"}), nil
+	}
+
+	scope := grantedScope(req.Scope)
+	if !hasScope(scope, "openid") {
+		return uc.AuthorizationErrorRedirect(req, &OAuthError{Code: "invalid_scope", Description: "the openid scope is required"}), nil
+	}
+
+	// PKCE is required for every client
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return uc.AuthorizationErrorRedirect(req, &OAuthError{Code: "invalid_request", Description: "a S256 code_challenge is required"}), nil
+	}
+
+	// Generate authorization code
+	rawCode, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	code, err := entity.NewAuthorizationCode(
+		uuid.New().String(),
+		hashToken(rawCode),
+		req.ClientID,
+		userID,
+		req.RedirectURI,
+		scope,
+		req.Nonce,
+		req.CodeChallenge,
+		time.Now().Add(uc.oidcConfig.CodeTTL),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// Save code to database
+	if err := uc.codeRepo.Create(ctx, code); err != nil {
+		return "", err
+	}
+
+	// Prune codes that were never exchanged
+	if err := uc.codeRepo.DeleteExpired(ctx); err != nil {
+		log.Printf("failed to delete expired authorization codes: %v", err)
+	}
+
+	params := url.Values{}
+	params.Set("code", rawCode)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, params), nil
+}
+
+// Token handles a token request, authenticating the client by its secret or, for public clients, its ID alone
+func (uc *OIDCUseCase) Token(ctx context.Context, req dto.OAuthTokenRequest, clientInfo valueobject.ClientInfo) (*dto.OAuthTokenResponse, error) {
+	client, err := uc.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case "authorization_code":
+		return uc.exchangeCode(ctx, client, req, clientInfo)
+	case "refresh_token":
+		tokens, err := uc.authUseCase.refreshTokenForClient(ctx, req.RefreshToken, client.ID, clientInfo)
+		if err != nil {
+			return nil, &OAuthError{Code: "invalid_grant", Description: err.Error()}
+		}
+		return &dto.OAuthTokenResponse{
+			AccessToken:  tokens.AccessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    tokens.ExpiresIn,
+			RefreshToken: tokens.RefreshToken,
+		}, nil
+	case "client_credentials":
+		return uc.issueClientCredentials(ctx, client, req)
+	default:
+		return nil, &OAuthError{Code: "unsupported_grant_type", Description: "grant_type must be authorization_code, refresh_token or client_credentials"}
+	}
+}
+
+// UserInfo returns the OpenID Connect claims for a user that the access token's scope grants
+func (uc *OIDCUseCase) UserInfo(ctx context.Context, userID, scope string) (*dto.UserInfoResponse, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UserInfoResponse{Sub: user.ID}
+	if hasScope(scope, "email") {
+		response.Email = user.Email
+		response.EmailVerified = &user.Verified
+	}
+	if hasScope(scope, "profile") {
+		response.PreferredUsername = user.Username
+	}
+	return response, nil
+}
+
+// exchangeCode redeems an authorization code for tokens and an ID token
+func (uc *OIDCUseCase) exchangeCode(ctx context.Context, client *entity.OAuthClient, req dto.OAuthTokenRequest, clientInfo valueobject.ClientInfo) (*dto.OAuthTokenResponse, error) {
+	invalidGrant := &OAuthError{Code: "invalid_grant", Description: "invalid, expired or already used authorization code"}
+
+	// Find code by hash
+	code, err := uc.codeRepo.FindByCodeHash(ctx, hashToken(req.Code))
+	if err != nil || code.IsUsed() || code.IsExpired() {
+		return nil, invalidGrant
+	}
+
+	// The code is bound to the client, redirect URI and PKCE challenge it was issued for
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return nil, invalidGrant
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "code_verifier does not match the code_challenge"}
+	}
+
+	// Mark code as used
+	if err := code.MarkUsed(); err != nil {
+		return nil, invalidGrant
+	}
+	if err := uc.codeRepo.MarkUsed(ctx, code); err != nil {
+		return nil, invalidGrant
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, code.UserID)
+	if err != nil {
+		return nil, invalidGrant
+	}
+
+	// Generate tokens
+	tokens, err := uc.authUseCase.generateTokensForClient(ctx, user, nil, client.ID, code.Scope, clientInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := uc.generateIDToken(ctx, user, client.ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OAuthTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      idToken,
+		Scope:        code.Scope,
+	}, nil
+}
+
+// issueClientCredentials issues an access token to an internal service acting as itself.
+// The token's subject and client_id are the client, and it is only accepted by the requested audiences.
+func (uc *OIDCUseCase) issueClientCredentials(ctx context.Context, client *entity.OAuthClient, req dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	if !client.AllowsClientCredentials() {
+		return nil, &OAuthError{Code: "unauthorized_client", Description: "the client is not allowed to use the client_credentials grant"}
+	}
+
+	// Default to everything the client is registered for
+	audiences := client.AudienceList()
+	if requested := strings.Fields(req.Audience); len(requested) > 0 {
+		if !isSubset(requested, audiences) {
+			return nil, &OAuthError{Code: "invalid_target", Description: "the requested audience is not registered for this client"}
+		}
+		audiences = requested
+	}
+
+	scopes := client.ScopeList()
+	if requested := strings.Fields(req.Scope); len(requested) > 0 {
+		if !isSubset(requested, scopes) {
+			return nil, &OAuthError{Code: "invalid_scope", Description: "the requested scope is not registered for this client"}
+		}
+		scopes = requested
+	}
+
+	// Create claims
+	now := time.Now()
+	scope := strings.Join(scopes, " ")
+	claims := map[string]interface{}{
+		"sub":       client.ID,
+		"client_id": client.ID,
+		"aud":       audiences,
+		"scope":     scope,
+		"exp":       now.Add(uc.oidcConfig.ClientCredentialsTTL).Unix(),
+		"iat":       now.Unix(),
+		"iss":       uc.jwtConfig.Issuer,
+		"jti":       uuid.New().String(),
+		"token_use": authz.TokenUseAccess,
+	}
+
+	// Sign token
+	accessToken, err := uc.signer.Sign(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(uc.oidcConfig.ClientCredentialsTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClient finds the client and checks its secret; public clients must not send one
+func (uc *OIDCUseCase) authenticateClient(ctx context.Context, clientID, clientSecret string) (*entity.OAuthClient, error) {
+	invalidClient := &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+
+	client, err := uc.clientRepo.FindByID(ctx, clientID)
+	if err != nil {
+		return nil, invalidClient
+	}
+
+	if client.IsPublic() {
+		if clientSecret != "" {
+			return nil, invalidClient
+		}
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, invalidClient
+	}
+	return client, nil
+}
+
+// generateIDToken generates an OpenID Connect ID token for the client, with claims for the granted scopes
+func (uc *OIDCUseCase) generateIDToken(ctx context.Context, user *entity.User, clientID string, code *entity.AuthorizationCode) (string, error) {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":       strings.TrimSuffix(uc.oidcConfig.IssuerURL, "/"),
+		"sub":       user.ID,
+		"aud":       clientID,
+		"exp":       now.Add(uc.oidcConfig.IDTokenTTL).Unix(),
+		"iat":       now.Unix(),
+		"auth_time": code.AuthTime.Unix(),
+		"token_use": authz.TokenUseID,
+	}
+	if code.Nonce != "" {
+		claims["nonce"] = code.Nonce
+	}
+	if hasScope(code.Scope, "email") {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.Verified
+	}
+	if hasScope(code.Scope, "profile") {
+		claims["preferred_username"] = user.Username
+	}
+
+	return uc.signer.Sign(ctx, claims)
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against its S256 challenge (RFC 7636)
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// grantedScope keeps the supported scopes of a requested scope string, in a stable order
+func grantedScope(requested string) string {
+	granted := make([]string, 0, len(supportedOIDCScopes))
+	for _, scope := range supportedOIDCScopes {
+		if hasScope(requested, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return strings.Join(granted, " ")
+}
+
+// hasScope checks if a space-separated scope string contains a scope
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubset checks if every value is one of the allowed values
+func isSubset(values, allowed []string) bool {
+	for _, v := range values {
+		if !hasScope(strings.Join(allowed, " "), v) {
+			return false
+		}
+	}
+	return true
+}
+
+// appendQuery adds query parameters to a URL that may already have some
+func appendQuery(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode()
+}
@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+)
+
+// PasswordPolicyError is returned when a new password breaks one or more password policy rules
+type PasswordPolicyError struct {
+	Violations []service.PasswordViolation
+}
+
+// Error implements the error interface
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the password policy"
+}
+
+// checkPasswordPolicy checks a new password for the account with the given email and username
+func (uc *AuthUseCase) checkPasswordPolicy(password, email, username string) error {
+	if violations := uc.passwordPolicy.Validate(password, email, username); len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
+)
+
+const (
+	// defaultPersonalAccessTokenTTL is used when no lifetime is requested
+	defaultPersonalAccessTokenTTL = 30 * 24 * time.Hour
+
+	// maxPersonalAccessTokenTTL caps the requested lifetime
+	maxPersonalAccessTokenTTL = 365 * 24 * time.Hour
+
+	// personalAccessTokenPrefixLength is how much of a token is kept for display
+	personalAccessTokenPrefixLength = len(pat.Prefix) + 4
+)
+
+// PersonalAccessTokenUseCase implements personal access token use cases
+type PersonalAccessTokenUseCase struct {
+	tokenRepo      repository.PersonalAccessTokenRepository
+	userRepo       repository.UserRepository
+	eventPublisher service.SecurityEventPublisher
+}
+
+// NewPersonalAccessTokenUseCase creates a new personal access token use case
+func NewPersonalAccessTokenUseCase(tokenRepo repository.PersonalAccessTokenRepository, userRepo repository.UserRepository, eventPublisher service.SecurityEventPublisher) *PersonalAccessTokenUseCase {
+	return &PersonalAccessTokenUseCase{
+		tokenRepo:      tokenRepo,
+		userRepo:       userRepo,
+		eventPublisher: eventPublisher,
+	}
+}
+
+// Create issues a new personal access token and returns it with the raw token, which is not stored
+func (uc *PersonalAccessTokenUseCase) Create(ctx context.Context, userID, name string, scopes []string, ttl time.Duration) (*entity.PersonalAccessToken, string, error) {
+	parsedScopes, err := valueobject.ParseScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if ttl == 0 {
+		ttl = defaultPersonalAccessTokenTTL
+	}
+	if ttl < 0 || ttl > maxPersonalAccessTokenTTL {
+		return nil, "", errors.New("expiration must be between 1 and 365 days")
+	}
+
+	// Generate token
+	secret, err := generateSecureToken()
+	if err != nil {
+		return nil, "", err
+	}
+	rawToken := pat.Prefix + secret
+
+	token, err := entity.NewPersonalAccessToken(
+		uuid.New().String(),
+		userID,
+		name,
+		hashToken(rawToken),
+		rawToken[:personalAccessTokenPrefixLength],
+		parsedScopes,
+		time.Now().Add(ttl),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Save token to database
+	if err := uc.tokenRepo.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:   service.SecurityEventPersonalAccessTokenCreated,
+		UserID: userID,
+		Details: map[string]string{
+			"token_id":   token.ID,
+			"name":       token.Name,
+			"scopes":     token.Scopes,
+			"expires_at": token.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+	return token, rawToken, nil
+}
+
+// List returns a user's personal access tokens
+func (uc *PersonalAccessTokenUseCase) List(ctx context.Context, userID string) ([]*entity.PersonalAccessToken, error) {
+	return uc.tokenRepo.FindByUserID(ctx, userID)
+}
+
+// Revoke deletes one of a user's personal access tokens
+func (uc *PersonalAccessTokenUseCase) Revoke(ctx context.Context, userID, id string) error {
+	// Find token
+	token, err := uc.tokenRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Another user's token is reported as missing so its existence is not disclosed
+	if token.UserID != userID {
+		return errors.New("personal access token not found")
+	}
+
+	if err := uc.tokenRepo.Delete(ctx, token.ID); err != nil {
+		return err
+	}
+
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventPersonalAccessTokenRevoked,
+		UserID:  userID,
+		Details: map[string]string{"token_id": token.ID, "name": token.Name},
+	})
+	return nil
+}
+
+// Verify resolves a raw personal access token to the user and scopes it acts for
+func (uc *PersonalAccessTokenUseCase) Verify(ctx context.Context, rawToken string) (*pat.Principal, error) {
+	if !pat.IsToken(rawToken) {
+		return nil, pat.ErrInvalidToken
+	}
+
+	// Find token by hash
+	token, err := uc.tokenRepo.FindByTokenHash(ctx, hashToken(rawToken))
+	if err != nil || token.IsExpired() {
+		return nil, pat.ErrInvalidToken
+	}
+
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, token.UserID)
+	if err != nil || user.Disabled {
+		return nil, pat.ErrInvalidToken
+	}
+
+	token.MarkUsed()
+	if err := uc.tokenRepo.UpdateLastUsed(ctx, token); err != nil {
+		log.Printf("failed to record use of personal access token %s: %v", token.ID, err)
+	}
+
+	return &pat.Principal{
+		UserID:    user.ID,
+		Role:      string(user.Role),
+		Verified:  user.Verified,
+		Scopes:    token.ScopeList(),
+		ExpiresAt: token.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+)
+
+// publishSecurityEvent publishes a security event, filling in the time and the requesting client
+// from the context when they are not set. Failing to publish does not fail the action.
+func publishSecurityEvent(ctx context.Context, publisher service.SecurityEventPublisher, event service.SecurityEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Outcome == "" {
+		event.Outcome = service.SecurityEventSuccess
+	}
+	client := valueobject.ClientInfoFromContext(ctx)
+	if event.IPAddress == "" {
+		event.IPAddress = client.IPAddress
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = client.UserAgent
+	}
+
+	if err := publisher.Publish(ctx, event); err != nil {
+		log.Printf("failed to publish security event %s: %v", event.Type, err)
+	}
+}
+
+// loginFailed publishes a failed login attempt. The user ID is empty for unknown emails.
+func (uc *AuthUseCase) loginFailed(ctx context.Context, userID, email, reason string, client valueobject.ClientInfo) {
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:      service.SecurityEventLoginFailed,
+		UserID:    userID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Outcome:   service.SecurityEventFailure,
+		Reason:    reason,
+		Details:   map[string]string{"email": email},
+	})
+}
+
+// loginSucceeded publishes a login that ended with tokens being issued
+func (uc *AuthUseCase) loginSucceeded(ctx context.Context, user *entity.User, method string, client valueobject.ClientInfo) {
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:      service.SecurityEventLoginSucceeded,
+		UserID:    user.ID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details:   map[string]string{"method": method},
+	})
+}
+
+// refreshFailed publishes a refused refresh token. The token is nil when it was not found.
+func (uc *AuthUseCase) refreshFailed(ctx context.Context, token *entity.Token, reason string, client valueobject.ClientInfo) {
+	event := service.SecurityEvent{
+		Type:      service.SecurityEventTokenRefreshed,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Outcome:   service.SecurityEventFailure,
+		Reason:    reason,
+	}
+	if token != nil {
+		event.UserID = token.UserID
+		event.Details = map[string]string{"family_id": token.FamilyID}
+	}
+	publishSecurityEvent(ctx, uc.eventPublisher, event)
+}
+
+// registrationFailed publishes a refused registration
+func (uc *AuthUseCase) registrationFailed(ctx context.Context, email, reason string) {
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventRegister,
+		Outcome: service.SecurityEventFailure,
+		Reason:  reason,
+		Details: map[string]string{"email": email},
+	})
+}
+
+// passwordChangeFailed publishes a refused password change
+func (uc *AuthUseCase) passwordChangeFailed(ctx context.Context, userID, reason string) {
+	publishSecurityEvent(ctx, uc.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventPasswordChanged,
+		UserID:  userID,
+		Outcome: service.SecurityEventFailure,
+		Reason:  reason,
+	})
+}
@@ -0,0 +1,379 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+)
+
+// usernameUnsafe matches characters that are not kept when deriving a username from an email
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// ErrLinkMFACodeRequired is returned when confirming a link to an account with two-factor authentication without a code
+var ErrLinkMFACodeRequired = errors.New("a two-factor authentication code is required to link this identity")
+
+// ErrLinkMFAEnrollmentRequired is returned when confirming a link to an account that must set up two-factor authentication first
+var ErrLinkMFAEnrollmentRequired = errors.New("set up two-factor authentication before linking an identity")
+
+// SocialLoginUseCase implements logging in with and linking external identity providers
+type SocialLoginUseCase struct {
+	providers    map[string]service.IdentityProvider
+	identityRepo repository.LinkedIdentityRepository
+	userRepo     repository.UserRepository
+	authUseCase  *AuthUseCase
+	socialConfig config.SocialLoginConfig
+}
+
+// NewSocialLoginUseCase creates a new social login use case
+func NewSocialLoginUseCase(
+	providers map[string]service.IdentityProvider,
+	identityRepo repository.LinkedIdentityRepository,
+	userRepo repository.UserRepository,
+	authUseCase *AuthUseCase,
+	socialConfig config.SocialLoginConfig,
+) *SocialLoginUseCase {
+	return &SocialLoginUseCase{
+		providers:    providers,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		authUseCase:  authUseCase,
+		socialConfig: socialConfig,
+	}
+}
+
+// Start begins a flow at the provider and returns its URL and a signed state token that must be
+// presented again at the callback. A non-empty userID links the identity to that user instead of logging in.
+func (uc *SocialLoginUseCase) Start(ctx context.Context, providerName, userID string) (string, string, error) {
+	provider, ok := uc.providers[providerName]
+	if !ok {
+		return "", "", errors.New("unknown identity provider")
+	}
+
+	// Generate state, nonce and PKCE verifier
+	state, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]), uc.redirectURI(providerName))
+	if err != nil {
+		return "", "", err
+	}
+
+	claims := jwt.MapClaims{
+		"purpose":  "social_login_state",
+		"provider": providerName,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(uc.socialConfig.StateTTL).Unix(),
+	}
+	if userID != "" {
+		claims["uid"] = userID
+	}
+	stateToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(uc.socialConfig.StateSecret))
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, stateToken, nil
+}
+
+// Callback completes a flow started with Start.
+// Logging in with an unlinked identity whose verified email matches an existing account does not link it;
+// a link token is returned instead, to be confirmed with the account password.
+func (uc *SocialLoginUseCase) Callback(ctx context.Context, providerName, code, state, stateToken string, client valueobject.ClientInfo) (*dto.SocialLoginResponse, error) {
+	provider, ok := uc.providers[providerName]
+	if !ok {
+		return nil, errors.New("unknown identity provider")
+	}
+
+	// Check state
+	claims, err := uc.parseToken(stateToken, "social_login_state")
+	if err != nil || claims["provider"] != providerName {
+		return nil, errors.New("invalid or expired login state")
+	}
+	expectedState, _ := claims["state"].(string)
+	if state == "" || subtle.ConstantTimeCompare([]byte(expectedState), []byte(state)) != 1 {
+		return nil, errors.New("invalid or expired login state")
+	}
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	linkUserID, _ := claims["uid"].(string)
+
+	// Redeem code at the provider
+	identity, err := provider.Exchange(ctx, code, verifier, uc.redirectURI(providerName), nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := uc.identityRepo.FindByProviderSubject(ctx, providerName, identity.Subject)
+	if err != nil {
+		linked = nil
+	}
+
+	// Link to the signed-in user
+	if linkUserID != "" {
+		if linked != nil {
+			if linked.UserID != linkUserID {
+				return nil, errors.New("this identity is already linked to another account")
+			}
+			return &dto.SocialLoginResponse{LinkedProvider: providerName}, nil
+		}
+		if err := uc.link(ctx, linkUserID, identity); err != nil {
+			return nil, err
+		}
+		return &dto.SocialLoginResponse{LinkedProvider: providerName}, nil
+	}
+
+	// Log in with a linked identity
+	if linked != nil {
+		user, err := uc.userRepo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return uc.login(ctx, user, identity.Provider, client)
+	}
+
+	if identity.Email == "" {
+		return nil, errors.New("the identity provider did not share an email address")
+	}
+
+	// An account with the same email must confirm the link
+	if user, err := uc.userRepo.FindByEmail(ctx, identity.Email); err == nil {
+		if !identity.EmailVerified {
+			return nil, errors.New("an account with this email already exists; sign in and link the provider from your account")
+		}
+
+		linkToken, err := uc.issueLinkToken(user, identity)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.SocialLoginResponse{LinkRequired: true, LinkToken: linkToken, Email: user.Email}, nil
+	}
+
+	// Register a new user for the identity
+	user, err := uc.register(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	return uc.login(ctx, user, identity.Provider, client)
+}
+
+// ConfirmLink links an identity to the existing account it matched once the account password is given,
+// then logs the user in. Accounts with two-factor authentication must also give a code, as the identity
+// is only linked once the login is complete.
+func (uc *SocialLoginUseCase) ConfirmLink(ctx context.Context, linkToken, password, code string, client valueobject.ClientInfo) (*dto.LoginResponse, error) {
+	claims, err := uc.parseToken(linkToken, "social_link")
+	if err != nil {
+		return nil, errors.New("invalid or expired link token")
+	}
+
+	userID, _ := claims["sub"].(string)
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("invalid or expired link token")
+	}
+
+	// Check the password with the usual lockout and second factor rules
+	response, err := uc.authUseCase.Login(ctx, user.Email, password, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Complete the second factor before linking
+	if response.TokenResponse == nil {
+		if !response.MFARequired {
+			return nil, ErrLinkMFAEnrollmentRequired
+		}
+		if code == "" {
+			return nil, ErrLinkMFACodeRequired
+		}
+		tokens, err := uc.authUseCase.VerifyMFA(ctx, response.MFAToken, code, client)
+		if err != nil {
+			return nil, err
+		}
+		response = &dto.LoginResponse{TokenResponse: tokens}
+	}
+
+	provider, _ := claims["provider"].(string)
+	subject, _ := claims["subject"].(string)
+	email, _ := claims["email"].(string)
+	if err := uc.link(ctx, user.ID, &service.ExternalIdentity{Provider: provider, Subject: subject, Email: email}); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ListIdentities returns the identities linked to a user
+func (uc *SocialLoginUseCase) ListIdentities(ctx context.Context, userID string) ([]*entity.LinkedIdentity, error) {
+	return uc.identityRepo.FindByUserID(ctx, userID)
+}
+
+// Unlink removes one of a user's linked identities
+func (uc *SocialLoginUseCase) Unlink(ctx context.Context, userID, id string) error {
+	// Find identity
+	identity, err := uc.identityRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Another user's identity is reported as missing so its existence is not disclosed
+	if identity.UserID != userID {
+		return errors.New("linked identity not found")
+	}
+
+	return uc.identityRepo.Delete(ctx, identity.ID)
+}
+
+// link stores a linked identity, refusing one already linked to any account
+func (uc *SocialLoginUseCase) link(ctx context.Context, userID string, identity *service.ExternalIdentity) error {
+	if _, err := uc.identityRepo.FindByProviderSubject(ctx, identity.Provider, identity.Subject); err == nil {
+		return errors.New("this identity is already linked to an account")
+	}
+
+	linked, err := entity.NewLinkedIdentity(uuid.New().String(), userID, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		return err
+	}
+	return uc.identityRepo.Create(ctx, linked)
+}
+
+// login completes a login for the user an identity from a provider belongs to
+func (uc *SocialLoginUseCase) login(ctx context.Context, user *entity.User, provider string, client valueobject.ClientInfo) (*dto.SocialLoginResponse, error) {
+	response, err := uc.authUseCase.completeLogin(ctx, user, "social:"+provider, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SocialLoginResponse{LoginResponse: response}, nil
+}
+
+// register creates a user for an external identity, with a random password that can be reset later
+func (uc *SocialLoginUseCase) register(ctx context.Context, identity *service.ExternalIdentity) (*entity.User, error) {
+	password, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := uc.availableUsername(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create new user
+	user, err := entity.NewUser(uuid.New().String(), identity.Email, username, password, uc.authUseCase.hasher)
+	if err != nil {
+		return nil, err
+	}
+	if identity.EmailVerified {
+		user.VerifyEmail()
+	}
+
+	// Save user to database along with the event that provisions their profile
+	if err := uc.authUseCase.createUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := uc.link(ctx, user.ID, identity); err != nil {
+		return nil, err
+	}
+
+	publishSecurityEvent(ctx, uc.authUseCase.eventPublisher, service.SecurityEvent{
+		Type:    service.SecurityEventRegister,
+		UserID:  user.ID,
+		Details: map[string]string{"email": user.Email, "method": "social:" + identity.Provider},
+	})
+
+	// Addresses the provider has not verified are verified as usual
+	if !user.Verified {
+		if err := uc.authUseCase.sendVerificationEmail(ctx, user); err != nil {
+			log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// availableUsername derives an unused username from an email address
+func (uc *SocialLoginUseCase) availableUsername(ctx context.Context, email string) (string, error) {
+	base := strings.ToLower(strings.SplitN(email, "@", 2)[0])
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(base, "_"), "_")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		if _, err := uc.userRepo.FindByUsername(ctx, candidate); err != nil {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s_%s", base, uuid.New().String()[:6])
+	}
+	return "", errors.New("could not find an available username")
+}
+
+// issueLinkToken signs a token that allows linking an identity to a user once confirmed
+func (uc *SocialLoginUseCase) issueLinkToken(user *entity.User, identity *service.ExternalIdentity) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose":  "social_link",
+		"sub":      user.ID,
+		"provider": identity.Provider,
+		"subject":  identity.Subject,
+		"email":    identity.Email,
+		"exp":      time.Now().Add(uc.socialConfig.StateTTL).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(uc.socialConfig.StateSecret))
+}
+
+// parseToken validates a token signed with the state secret and checks its purpose
+func (uc *SocialLoginUseCase) parseToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(uc.socialConfig.StateSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// redirectURI returns the callback URL registered at a provider
+func (uc *SocialLoginUseCase) redirectURI(providerName string) string {
+	return strings.TrimSuffix(uc.socialConfig.CallbackURL, "/") + "/" + providerName + "/callback"
+}
@@ -0,0 +1,313 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/valueobject"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/infrastructure/identity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+)
+
+const (
+	mockClientID     = "blog"
+	mockClientSecret = "blog-secret"
+)
+
+// mockIdP is an in-process OpenID Connect provider. Every authorization request is approved
+// for the identity it is set up with, and codes are redeemed once with the client's secret and PKCE verifier.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	identity service.ExternalIdentity
+	codes    map[string]url.Values
+}
+
+func newMockIdP(t *testing.T, identity service.ExternalIdentity) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	idp := &mockIdP{key: key, identity: identity, codes: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 idp.server.URL,
+		"authorization_endpoint": idp.server.URL + "/authorize",
+		"token_endpoint":         idp.server.URL + "/token",
+		"jwks_uri":               idp.server.URL + "/jwks",
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	key, err := jwks.NewJSONWebKey("mock-key", "RS256", &idp.key.PublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(jwks.KeySet{Keys: []jwks.JSONWebKey{key}})
+}
+
+// authorize approves the request and redirects back with a code
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != mockClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	code := base64.RawURLEncoding.EncodeToString([]byte(query.Get("state")))
+	idp.mu.Lock()
+	idp.codes[code] = query
+	idp.mu.Unlock()
+
+	http.Redirect(w, r, query.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {query.Get("state")}}.Encode(), http.StatusFound)
+}
+
+// token redeems a code for an ID token signed with the provider's key
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != mockClientID || clientSecret != mockClientSecret {
+		http.Error(w, "invalid client", http.StatusUnauthorized)
+		return
+	}
+
+	idp.mu.Lock()
+	authorization, ok := idp.codes[r.FormValue("code")]
+	delete(idp.codes, r.FormValue("code"))
+	identity := idp.identity
+	idp.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || authorization.Get("redirect_uri") != r.FormValue("redirect_uri") ||
+		authorization.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+		http.Error(w, "invalid grant", http.StatusBadRequest)
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            mockClientID,
+		"sub":            identity.Subject,
+		"email":          identity.Email,
+		"email_verified": identity.EmailVerified,
+		"nonce":          authorization.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = "mock-key"
+	signed, err := idToken.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+}
+
+// socialLoginTest is an MFA test with a social login use case for the mock provider
+type socialLoginTest struct {
+	*mfaTest
+	social     *SocialLoginUseCase
+	identities *fakeLinkedIdentityRepository
+	idp        *mockIdP
+}
+
+func newSocialLoginTest(t *testing.T, asserted service.ExternalIdentity) *socialLoginTest {
+	t.Helper()
+
+	test := &socialLoginTest{
+		mfaTest:    newMFATest(t, valueobject.RoleUser),
+		identities: newFakeLinkedIdentityRepository(),
+		idp:        newMockIdP(t, asserted),
+	}
+	providers := map[string]service.IdentityProvider{
+		"mock": identity.NewOIDCProvider("mock", config.IdentityProviderConfig{
+			Issuer:       test.idp.server.URL,
+			ClientID:     mockClientID,
+			ClientSecret: mockClientSecret,
+			Scopes:       []string{"openid", "email"},
+		}),
+	}
+	test.social = NewSocialLoginUseCase(providers, test.identities, test.users, test.auth, config.SocialLoginConfig{
+		CallbackURL: "https://blog.example.com/api/v1/auth/oidc",
+		StateSecret: "state-secret",
+		StateTTL:    10 * time.Minute,
+	})
+	return test
+}
+
+// callback goes through the provider as a browser would and returns the result of the callback
+func (test *socialLoginTest) callback() (*dto.SocialLoginResponse, error) {
+	test.t.Helper()
+	ctx := context.Background()
+
+	authURL, stateToken, err := test.social.Start(ctx, "mock", "")
+	if err != nil {
+		test.t.Fatalf("Start: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		test.t.Fatalf("authorize at the provider: %v", err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if resp.StatusCode != http.StatusFound || err != nil {
+		test.t.Fatalf("provider did not redirect back: %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if location.Path != "/api/v1/auth/oidc/mock/callback" {
+		test.t.Fatalf("provider redirected to %s, want the mock callback", location)
+	}
+
+	return test.social.Callback(ctx, "mock", location.Query().Get("code"), location.Query().Get("state"), stateToken, test.client)
+}
+
+// linkToken logs in with the provider and returns the token for confirming the link to the matching account
+func (test *socialLoginTest) linkToken() string {
+	test.t.Helper()
+
+	response, err := test.callback()
+	if err != nil {
+		test.t.Fatalf("Callback: %v", err)
+	}
+	if !response.LinkRequired || response.LinkToken == "" || response.LoginResponse != nil {
+		test.t.Fatalf("Callback did not ask to confirm the link: %+v", response)
+	}
+	return response.LinkToken
+}
+
+// linked reports whether the provider's identity is linked to the user
+func (test *socialLoginTest) linked() bool {
+	identities, _ := test.identities.FindByUserID(context.Background(), "user-1")
+	return len(identities) == 1 && identities[0].Provider == "mock" && identities[0].Subject == "mock-subject"
+}
+
+var mockIdentity = service.ExternalIdentity{Subject: "mock-subject", Email: "alice@example.com", EmailVerified: true}
+
+func TestSocialLoginLinksMatchingAccountWithPassword(t *testing.T) {
+	test := newSocialLoginTest(t, mockIdentity)
+	ctx := context.Background()
+
+	// A wrong password does not link
+	linkToken := test.linkToken()
+	if _, err := test.social.ConfirmLink(ctx, linkToken, "wrong password", "", test.client); err == nil {
+		t.Fatal("ConfirmLink accepted a wrong password")
+	}
+	if test.linked() {
+		t.Fatal("identity was linked with a wrong password")
+	}
+
+	response, err := test.social.ConfirmLink(ctx, linkToken, testPassword, "", test.client)
+	if err != nil {
+		t.Fatalf("ConfirmLink: %v", err)
+	}
+	if response.TokenResponse == nil {
+		t.Fatalf("ConfirmLink did not log in: %+v", response)
+	}
+	if !test.linked() {
+		t.Fatal("ConfirmLink did not link the identity")
+	}
+
+	// The linked identity now logs in directly
+	callback, err := test.callback()
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if callback.LoginResponse == nil || callback.TokenResponse == nil {
+		t.Fatalf("Callback with a linked identity did not log in: %+v", callback)
+	}
+}
+
+func TestSocialLoginRequiresConfirmationOfUnverifiedEmail(t *testing.T) {
+	unverified := mockIdentity
+	unverified.EmailVerified = false
+	test := newSocialLoginTest(t, unverified)
+
+	if _, err := test.callback(); err == nil {
+		t.Fatal("Callback matched an account by an unverified email")
+	}
+	if test.linked() {
+		t.Fatal("identity was linked by an unverified email")
+	}
+}
+
+func TestSocialLoginConfirmLinkRequiresSecondFactor(t *testing.T) {
+	test := newSocialLoginTest(t, mockIdentity)
+	secret, _ := test.enroll()
+	ctx := context.Background()
+	linkToken := test.linkToken()
+
+	// The password alone does not link
+	if _, err := test.social.ConfirmLink(ctx, linkToken, testPassword, "", test.client); !errors.Is(err, ErrLinkMFACodeRequired) {
+		t.Fatalf("ConfirmLink without a code: got %v, want %v", err, ErrLinkMFACodeRequired)
+	}
+	if _, err := test.social.ConfirmLink(ctx, linkToken, testPassword, "000000", test.client); err == nil {
+		t.Fatal("ConfirmLink accepted a wrong code")
+	}
+	if test.linked() {
+		t.Fatal("identity was linked without a second factor")
+	}
+
+	response, err := test.social.ConfirmLink(ctx, linkToken, testPassword, test.code(secret), test.client)
+	if err != nil {
+		t.Fatalf("ConfirmLink with a code: %v", err)
+	}
+	if response.TokenResponse == nil {
+		t.Fatalf("ConfirmLink did not log in: %+v", response)
+	}
+	if !test.linked() {
+		t.Fatal("ConfirmLink did not link the identity")
+	}
+
+	// Logging in with the linked identity still asks for the second factor
+	callback, err := test.callback()
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if callback.LoginResponse == nil || callback.TokenResponse != nil || !callback.MFARequired {
+		t.Fatalf("Callback with a linked identity skipped the second factor: %+v", callback)
+	}
+}
+
+func TestSocialLoginRejectsWrongState(t *testing.T) {
+	test := newSocialLoginTest(t, mockIdentity)
+	ctx := context.Background()
+
+	_, stateToken, err := test.social.Start(ctx, "mock", "")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := test.social.Callback(ctx, "mock", "code", "forged-state", stateToken, test.client); err == nil {
+		t.Fatal("Callback accepted a state that does not match the state token")
+	}
+}
@@ -0,0 +1,211 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/config"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/domain/service"
+	"github.com/vcd-simple-blog/apps/backend/auth-service/interfaces/http/dto"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+)
+
+// TokenIntrospectionUseCase implements token introspection (RFC 7662) and revocation (RFC 7009).
+// Revoked access tokens are denylisted by their jti until they expire.
+type TokenIntrospectionUseCase struct {
+	revokedTokenRepo repository.RevokedTokenRepository
+	tokenRepo        repository.TokenRepository
+	authUseCase      *AuthUseCase
+	oidcUseCase      *OIDCUseCase
+	signer           service.TokenSigner
+	jwtConfig        config.JWTConfig
+}
+
+// NewTokenIntrospectionUseCase creates a new token introspection use case
+func NewTokenIntrospectionUseCase(
+	revokedTokenRepo repository.RevokedTokenRepository,
+	tokenRepo repository.TokenRepository,
+	authUseCase *AuthUseCase,
+	oidcUseCase *OIDCUseCase,
+	signer service.TokenSigner,
+	jwtConfig config.JWTConfig,
+) *TokenIntrospectionUseCase {
+	return &TokenIntrospectionUseCase{
+		revokedTokenRepo: revokedTokenRepo,
+		tokenRepo:        tokenRepo,
+		authUseCase:      authUseCase,
+		oidcUseCase:      oidcUseCase,
+		signer:           signer,
+		jwtConfig:        jwtConfig,
+	}
+}
+
+// AuthenticateClient checks the credentials of the OAuth client calling introspection or revocation.
+// Only confidential clients may introspect tokens.
+func (uc *TokenIntrospectionUseCase) AuthenticateClient(ctx context.Context, clientID, clientSecret string, confidential bool) error {
+	client, err := uc.oidcUseCase.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	if confidential && client.IsPublic() {
+		return &OAuthError{Code: "invalid_client", Description: "public clients cannot introspect tokens"}
+	}
+	return nil
+}
+
+// Introspect returns the state of an access or refresh token
+func (uc *TokenIntrospectionUseCase) Introspect(ctx context.Context, token, tokenTypeHint string) *dto.TokenIntrospectionResponse {
+	inactive := &dto.TokenIntrospectionResponse{Active: false}
+
+	// Refresh tokens are opaque
+	if tokenTypeHint == "refresh_token" || !isJWT(token) {
+		refreshToken, err := uc.tokenRepo.FindByTokenHash(ctx, hashToken(token))
+		if err != nil || !refreshToken.IsActive() {
+			return inactive
+		}
+		return &dto.TokenIntrospectionResponse{
+			Active:    true,
+			TokenType: "refresh_token",
+			Sub:       refreshToken.UserID,
+			Exp:       refreshToken.ExpiresAt.Unix(),
+			Iat:       refreshToken.CreatedAt.Unix(),
+			Iss:       uc.jwtConfig.Issuer,
+			SessionID: refreshToken.FamilyID,
+		}
+	}
+
+	claims, err := uc.parseAccessToken(ctx, token)
+	if err != nil || !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return inactive
+	}
+
+	// Check denylist
+	jti, _ := claims["jti"].(string)
+	if uc.IsRevoked(ctx, jti) {
+		return inactive
+	}
+
+	// The access token dies with its session
+	sub, _ := claims["sub"].(string)
+	sid, _ := claims["sid"].(string)
+	if sid != "" && !uc.sessionActive(ctx, sub, sid) {
+		return inactive
+	}
+
+	response := &dto.TokenIntrospectionResponse{
+		Active:    true,
+		TokenType: "access_token",
+		Sub:       sub,
+		Iss:       uc.jwtConfig.Issuer,
+		JTI:       jti,
+		SessionID: sid,
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		response.Exp = int64(exp)
+	}
+	if iat, ok := claims["iat"].(float64); ok {
+		response.Iat = int64(iat)
+	}
+	response.Role, _ = claims["role"].(string)
+	response.Verified, _ = claims["verified"].(bool)
+	response.ClientID, _ = claims["client_id"].(string)
+	response.Scope, _ = claims["scope"].(string)
+	if actor := authz.Actor(claims); actor != "" {
+		response.Act = &dto.ActorClaim{Sub: actor}
+	}
+	return response
+}
+
+// Revoke revokes an access token until it expires, or the session of a refresh token.
+// Unknown and invalid tokens are ignored, as RFC 7009 requires.
+func (uc *TokenIntrospectionUseCase) Revoke(ctx context.Context, token, tokenTypeHint string) error {
+	// Refresh tokens end their whole session
+	if tokenTypeHint == "refresh_token" || !isJWT(token) {
+		if err := uc.authUseCase.Logout(ctx, token); err != nil {
+			log.Printf("ignoring revocation of unknown refresh token: %v", err)
+		}
+		return nil
+	}
+
+	claims, err := uc.parseAccessToken(ctx, token)
+	if err != nil {
+		return nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	expiresAt := time.Unix(int64(exp), 0)
+	if jti == "" || expiresAt.Before(time.Now()) {
+		return nil
+	}
+
+	revoked, err := entity.NewRevokedToken(jti, expiresAt)
+	if err != nil {
+		return err
+	}
+	return uc.revokedTokenRepo.Create(ctx, revoked)
+}
+
+// IsRevoked checks if an access token ID is on the denylist.
+// It fails closed: a token counts as revoked when the denylist cannot be read.
+func (uc *TokenIntrospectionUseCase) IsRevoked(ctx context.Context, jti string) bool {
+	if jti == "" {
+		return false
+	}
+
+	revoked, err := uc.revokedTokenRepo.IsRevoked(ctx, jti)
+	if err != nil {
+		log.Printf("failed to check token denylist: %v", err)
+		return true
+	}
+	return revoked
+}
+
+// parseAccessToken verifies the signature and issuer of an access token, but not its expiry
+func (uc *TokenIntrospectionUseCase) parseAccessToken(ctx context.Context, token string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	parsed, err := parser.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		default:
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return uc.signer.Key(ctx, kid)
+	})
+	if err != nil || !parsed.Valid {
+		return nil, errors.New("invalid access token")
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyIssuer(uc.jwtConfig.Issuer, true) || claims[authz.TokenUseClaim] != authz.TokenUseAccess {
+		return nil, errors.New("invalid access token")
+	}
+	return claims, nil
+}
+
+// sessionActive checks if the user still has an active refresh token in the session
+func (uc *TokenIntrospectionUseCase) sessionActive(ctx context.Context, userID, sessionID string) bool {
+	tokens, err := uc.tokenRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false
+	}
+	for _, token := range tokens {
+		if token.FamilyID == sessionID && token.IsActive() {
+			return true
+		}
+	}
+	return false
+}
+
+// isJWT checks if a token has the three dot-separated parts of a JWT
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
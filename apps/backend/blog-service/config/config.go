@@ -1,14 +1,16 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"strconv"
 )
 
 // Config holds all configuration for the Blog Service
 type Config struct {
-	Environment string
-	Database    DatabaseConfig
+	Environment   string
+	Database      DatabaseConfig
+	AuthorErasure AuthorErasureConfig
 }
 
 // DatabaseConfig holds database configuration
@@ -21,6 +23,13 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// AuthorErasureConfig holds the policy for the blogs of deleted accounts
+type AuthorErasureConfig struct {
+	Policy            string // "anonymize" or "reassign"
+	ReassignTo        string // author ID blogs are reassigned to under the reassign policy
+	AnonymousAuthorID string // author ID of anonymized blogs
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	env := os.Getenv("ENV")
@@ -59,6 +68,28 @@ func LoadConfig() (*Config, error) {
 		dbSSLMode = "disable"
 	}
 
+	// Author erasure config
+	erasurePolicy := os.Getenv("AUTHOR_ERASURE_POLICY")
+	if erasurePolicy == "" {
+		erasurePolicy = "anonymize"
+	}
+
+	erasureReassignTo := os.Getenv("AUTHOR_ERASURE_REASSIGN_TO")
+	switch erasurePolicy {
+	case "anonymize":
+	case "reassign":
+		if erasureReassignTo == "" {
+			return nil, errors.New("AUTHOR_ERASURE_REASSIGN_TO is required by the reassign author erasure policy")
+		}
+	default:
+		return nil, errors.New("AUTHOR_ERASURE_POLICY must be anonymize or reassign")
+	}
+
+	erasureAnonymousAuthorID := os.Getenv("AUTHOR_ERASURE_ANONYMOUS_ID")
+	if erasureAnonymousAuthorID == "" {
+		erasureAnonymousAuthorID = "deleted-user"
+	}
+
 	return &Config{
 		Environment: env,
 		Database: DatabaseConfig{
@@ -69,5 +100,10 @@ func LoadConfig() (*Config, error) {
 			DBName:   dbName,
 			SSLMode:  dbSSLMode,
 		},
+		AuthorErasure: AuthorErasureConfig{
+			Policy:            erasurePolicy,
+			ReassignTo:        erasureReassignTo,
+			AnonymousAuthorID: erasureAnonymousAuthorID,
+		},
 	}, nil
 }
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// AuthorErasure remembers the original author of a blog whose author was erased when their account
+// was deleted, so the erasure can be undone until it is finalized
+type AuthorErasure struct {
+	ErasureID        string `gorm:"primaryKey"`
+	BlogID           string `gorm:"primaryKey"`
+	OriginalAuthorID string
+	CreatedAt        time.Time
+}
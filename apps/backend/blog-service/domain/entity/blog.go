@@ -7,9 +7,12 @@ import (
 	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/valueobject"
 )
 
-// Blog represents a blog post entity
+// Blog represents a blog post entity.
+// Blogs without a slug predate slugs; a slug chosen by hand is kept when the title changes.
 type Blog struct {
 	ID          string
+	Slug        string `gorm:"uniqueIndex:idx_blogs_slug,where:slug <> ''"`
+	CustomSlug  bool
 	Title       string
 	Content     string
 	AuthorID    string
@@ -77,6 +80,18 @@ func (b *Blog) Update(title, content string, tags []string) error {
 	return nil
 }
 
+// SetSlug changes the blog's slug, remembering whether it was chosen by hand
+func (b *Blog) SetSlug(slug string, custom bool) error {
+	if err := valueobject.ValidateSlug(slug); err != nil {
+		return err
+	}
+
+	b.Slug = slug
+	b.CustomSlug = custom
+	b.UpdatedAt = time.Now()
+	return nil
+}
+
 // IsAuthor checks if the given user ID is the author of the blog
 func (b *Blog) IsAuthor(userID string) bool {
 	return b.AuthorID == userID
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// BlogSlugRedirect is a slug a blog used to have, kept so links to it lead to the blog's current slug
+type BlogSlugRedirect struct {
+	Slug      string `gorm:"primaryKey"`
+	BlogID    string `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// NewBlogSlugRedirect creates a new blog slug redirect entity
+func NewBlogSlugRedirect(slug, blogID string) (*BlogSlugRedirect, error) {
+	if slug == "" {
+		return nil, errors.New("slug cannot be empty")
+	}
+
+	if blogID == "" {
+		return nil, errors.New("blog ID cannot be empty")
+	}
+
+	return &BlogSlugRedirect{
+		Slug:      slug,
+		BlogID:    blogID,
+		CreatedAt: time.Now(),
+	}, nil
+}
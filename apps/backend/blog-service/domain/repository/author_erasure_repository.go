@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+)
+
+// AuthorErasureRepository defines the interface for erasing authors from their blogs.
+// Every method is idempotent.
+type AuthorErasureRepository interface {
+	Apply(ctx context.Context, erasureID, authorID, replacementAuthorID string) error
+	Revert(ctx context.Context, erasureID string) error
+	Finalize(ctx context.Context, erasureID string) error
+}
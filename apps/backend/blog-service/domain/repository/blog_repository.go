@@ -2,14 +2,20 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
 )
 
+// ErrSlugConflict is returned when a blog is saved with a slug another blog already has
+var ErrSlugConflict = errors.New("slug is already in use")
+
 // BlogRepository defines the interface for blog data access
 type BlogRepository interface {
 	FindAll(ctx context.Context, limit, offset int) ([]*entity.Blog, error)
 	FindByID(ctx context.Context, id string) (*entity.Blog, error)
+	FindBySlug(ctx context.Context, slug string) (*entity.Blog, error)
+	FindWithoutSlug(ctx context.Context, limit int) ([]*entity.Blog, error)
 	FindByAuthorID(ctx context.Context, authorID string, limit, offset int) ([]*entity.Blog, error)
 	Create(ctx context.Context, blog *entity.Blog) error
 	Update(ctx context.Context, blog *entity.Blog) error
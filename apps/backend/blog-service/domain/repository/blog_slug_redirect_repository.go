@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+)
+
+// BlogSlugRedirectRepository defines the interface for the slugs blogs used to have
+type BlogSlugRedirectRepository interface {
+	FindBySlug(ctx context.Context, slug string) (*entity.BlogSlugRedirect, error)
+	Save(ctx context.Context, redirect *entity.BlogSlugRedirect) error
+	Delete(ctx context.Context, slug string) error
+	DeleteByBlogID(ctx context.Context, blogID string) error
+}
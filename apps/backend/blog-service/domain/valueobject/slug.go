@@ -0,0 +1,88 @@
+package valueobject
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength is the maximum length of a slug
+const MaxSlugLength = 80
+
+// slugPattern matches lowercase words of letters and digits joined by single hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// transliterations spells out lowercase letters that do not decompose into an ASCII letter and accents
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l",
+	'ı': "i", 'ħ': "h", '&': " and ",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify turns text into a slug: letters are transliterated to ASCII, everything else
+// separates words, and the result is cut to MaxSlugLength at a word boundary.
+// It returns an empty string when nothing of the text can be spelled in ASCII.
+func Slugify(text string) string {
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(text) {
+		// Look letters up before and after splitting off their accents, so й is not spelled as и
+		spelled, ok := transliterations[r]
+		if !ok {
+			spelled = ""
+			for _, c := range norm.NFD.String(string(r)) {
+				if t, ok := transliterations[c]; ok {
+					spelled += t
+				} else if !unicode.Is(unicode.Mn, c) {
+					spelled += string(c)
+				}
+			}
+		}
+
+		for _, c := range spelled {
+			if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+				if separate && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				b.WriteRune(c)
+				separate = false
+			} else {
+				separate = true
+			}
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// ValidateSlug checks that a slug is well formed
+func ValidateSlug(slug string) error {
+	if len(slug) > MaxSlugLength {
+		return errors.New("slug is too long")
+	}
+
+	if !slugPattern.MatchString(slug) {
+		return errors.New("slug may only contain lowercase letters, digits and single hyphens between them")
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ require (
 	github.com/golang-jwt/jwt/v4 v4.5.0
 	github.com/google/uuid v1.3.1
 	github.com/labstack/echo/v4 v4.11.3
+	github.com/vcd-simple-blog/packages/go/common v0.0.0
+	golang.org/x/text v0.13.0
 	gorm.io/driver/postgres v1.5.4
 	gorm.io/gorm v1.25.5
 )
@@ -25,8 +27,9 @@ require (
 	golang.org/x/crypto v0.14.0 // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
-	golang.org/x/text v0.13.0 // indirect
 	golang.org/x/time v0.3.0 // indirect
 )
 
 replace github.com/vcd-simple-blog/common => ../../../packages/go/common
+
+replace github.com/vcd-simple-blog/packages/go/common => ../../../packages/go/common
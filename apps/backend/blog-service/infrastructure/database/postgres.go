@@ -13,13 +13,14 @@ func NewPostgresDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode)
 	
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Translate unique index violations to gorm.ErrDuplicatedKey, which the repositories report as slug conflicts
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Auto migrate the schema
-	if err := db.AutoMigrate(&entity.Blog{}); err != nil {
+	if err := db.AutoMigrate(&entity.Blog{}, &entity.BlogSlugRedirect{}, &entity.AuthorErasure{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
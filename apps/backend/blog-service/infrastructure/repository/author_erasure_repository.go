@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuthorErasureRepository implements the domain.repository.AuthorErasureRepository interface
+type AuthorErasureRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthorErasureRepository creates a new author erasure repository
+func NewAuthorErasureRepository(db *gorm.DB) *AuthorErasureRepository {
+	return &AuthorErasureRepository{
+		db: db,
+	}
+}
+
+// Apply atomically moves every blog by the author to the replacement author, remembering the original author
+func (r *AuthorErasureRepository) Apply(ctx context.Context, erasureID, authorID, replacementAuthorID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var blogs []*entity.Blog
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&blogs, "author_id = ?", authorID).Error; err != nil {
+			return err
+		}
+		if len(blogs) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		erasures := make([]*entity.AuthorErasure, len(blogs))
+		ids := make([]string, len(blogs))
+		for i, blog := range blogs {
+			erasures[i] = &entity.AuthorErasure{
+				ErasureID:        erasureID,
+				BlogID:           blog.ID,
+				OriginalAuthorID: authorID,
+				CreatedAt:        now,
+			}
+			ids[i] = blog.ID
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&erasures).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.Blog{}).Where("id IN ?", ids).Update("author_id", replacementAuthorID).Error
+	})
+}
+
+// Revert atomically gives the blogs of an erasure back to their original author
+func (r *AuthorErasureRepository) Revert(ctx context.Context, erasureID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var erasures []*entity.AuthorErasure
+		if err := tx.Find(&erasures, "erasure_id = ?", erasureID).Error; err != nil {
+			return err
+		}
+
+		for _, erasure := range erasures {
+			if err := tx.Model(&entity.Blog{}).Where("id = ?", erasure.BlogID).Update("author_id", erasure.OriginalAuthorID).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&entity.AuthorErasure{}, "erasure_id = ?", erasureID).Error
+	})
+}
+
+// Finalize forgets the original authors of an erasure
+func (r *AuthorErasureRepository) Finalize(ctx context.Context, erasureID string) error {
+	return r.db.WithContext(ctx).Delete(&entity.AuthorErasure{}, "erasure_id = ?", erasureID).Error
+}
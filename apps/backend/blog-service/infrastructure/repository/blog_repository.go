@@ -5,6 +5,7 @@ import (
 	"errors"
 	"gorm.io/gorm"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/repository"
 )
 
 // BlogRepository implements the domain.repository.BlogRepository interface
@@ -42,10 +43,33 @@ func (r *BlogRepository) FindByID(ctx context.Context, id string) (*entity.Blog,
 	return &blog, nil
 }
 
+// FindBySlug finds a blog by its current slug
+func (r *BlogRepository) FindBySlug(ctx context.Context, slug string) (*entity.Blog, error) {
+	var blog entity.Blog
+	result := r.db.WithContext(ctx).First(&blog, "slug = ?", slug)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("blog not found")
+		}
+		return nil, result.Error
+	}
+	return &blog, nil
+}
+
+// FindWithoutSlug finds blogs that do not have a slug yet
+func (r *BlogRepository) FindWithoutSlug(ctx context.Context, limit int) ([]*entity.Blog, error) {
+	var blogs []*entity.Blog
+	result := r.db.WithContext(ctx).Where("slug = '' OR slug IS NULL").Order("created_at").Limit(limit).Find(&blogs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blogs, nil
+}
+
 // FindByAuthorID finds blogs by author ID with pagination
 func (r *BlogRepository) FindByAuthorID(ctx context.Context, authorID string, limit, offset int) ([]*entity.Blog, error) {
 	var blogs []*entity.Blog
-	result := r.db.WithContext(ctx).Where("author_id = ?", authorID).Limit(limit).Offset(offset).Find(&blogs)
+	result := r.db.WithContext(ctx).Where("author_id = ?", authorID).Order("created_at").Limit(limit).Offset(offset).Find(&blogs)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -54,12 +78,20 @@ func (r *BlogRepository) FindByAuthorID(ctx context.Context, authorID string, li
 
 // Create creates a new blog
 func (r *BlogRepository) Create(ctx context.Context, blog *entity.Blog) error {
-	return r.db.WithContext(ctx).Create(blog).Error
+	return slugConflict(r.db.WithContext(ctx).Create(blog).Error)
 }
 
 // Update updates a blog
 func (r *BlogRepository) Update(ctx context.Context, blog *entity.Blog) error {
-	return r.db.WithContext(ctx).Save(blog).Error
+	return slugConflict(r.db.WithContext(ctx).Save(blog).Error)
+}
+
+// slugConflict reports a violation of the unique slug index as repository.ErrSlugConflict
+func slugConflict(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repository.ErrSlugConflict
+	}
+	return err
 }
 
 // Delete deletes a blog
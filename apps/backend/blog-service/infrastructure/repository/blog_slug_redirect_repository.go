@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+	"gorm.io/gorm"
+)
+
+// BlogSlugRedirectRepository implements the domain.repository.BlogSlugRedirectRepository interface
+type BlogSlugRedirectRepository struct {
+	db *gorm.DB
+}
+
+// NewBlogSlugRedirectRepository creates a new blog slug redirect repository
+func NewBlogSlugRedirectRepository(db *gorm.DB) *BlogSlugRedirectRepository {
+	return &BlogSlugRedirectRepository{
+		db: db,
+	}
+}
+
+// FindBySlug finds the redirect of a former slug
+func (r *BlogSlugRedirectRepository) FindBySlug(ctx context.Context, slug string) (*entity.BlogSlugRedirect, error) {
+	var redirect entity.BlogSlugRedirect
+	result := r.db.WithContext(ctx).First(&redirect, "slug = ?", slug)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("slug redirect not found")
+		}
+		return nil, result.Error
+	}
+	return &redirect, nil
+}
+
+// Save creates a redirect, or points an existing one at the redirect's blog
+func (r *BlogSlugRedirectRepository) Save(ctx context.Context, redirect *entity.BlogSlugRedirect) error {
+	return r.db.WithContext(ctx).Save(redirect).Error
+}
+
+// Delete deletes the redirect of a former slug
+func (r *BlogSlugRedirectRepository) Delete(ctx context.Context, slug string) error {
+	return r.db.WithContext(ctx).Delete(&entity.BlogSlugRedirect{}, "slug = ?", slug).Error
+}
+
+// DeleteByBlogID deletes all redirects to a blog
+func (r *BlogSlugRedirectRepository) DeleteByBlogID(ctx context.Context, blogID string) error {
+	return r.db.WithContext(ctx).Delete(&entity.BlogSlugRedirect{}, "blog_id = ?", blogID).Error
+}
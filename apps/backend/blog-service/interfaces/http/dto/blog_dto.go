@@ -7,10 +7,10 @@ import (
 
 // CreateBlogRequest represents the request for creating a blog
 type CreateBlogRequest struct {
-	Title    string   `json:"title" validate:"required"`
-	Content  string   `json:"content" validate:"required"`
-	Tags     []string `json:"tags"`
-	AuthorID string   `json:"author_id" validate:"required"`
+	Title   string   `json:"title" validate:"required"`
+	Content string   `json:"content" validate:"required"`
+	Tags    []string `json:"tags"`
+	Slug    string   `json:"slug,omitempty"` // generated from the title when empty
 }
 
 // UpdateBlogRequest represents the request for updating a blog
@@ -18,12 +18,14 @@ type UpdateBlogRequest struct {
 	Title   string   `json:"title" validate:"required"`
 	Content string   `json:"content" validate:"required"`
 	Tags    []string `json:"tags"`
+	Slug    string   `json:"slug,omitempty"` // generated from the title when empty
 }
 
 // BlogResponse represents the response with blog information
 type BlogResponse struct {
 	ID          string             `json:"id"`
 	Title       string             `json:"title"`
+	Slug        string             `json:"slug"`
 	Content     string             `json:"content"`
 	AuthorID    string             `json:"author_id"`
 	Status      valueobject.BlogStatus `json:"status"`
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"github.com/labstack/echo/v4"
@@ -45,6 +46,7 @@ func (h *BlogHandler) GetBlogs(c echo.Context) error {
 		response[i] = dto.BlogResponse{
 			ID:          blog.ID,
 			Title:       blog.Title,
+			Slug:        blog.Slug,
 			Content:     blog.Content,
 			AuthorID:    blog.AuthorID,
 			Status:      blog.Status,
@@ -76,6 +78,37 @@ func (h *BlogHandler) GetBlog(c echo.Context) error {
 	return c.JSON(http.StatusOK, dto.BlogResponse{
 		ID:          blog.ID,
 		Title:       blog.Title,
+		Slug:        blog.Slug,
+		Content:     blog.Content,
+		AuthorID:    blog.AuthorID,
+		Status:      blog.Status,
+		Tags:        blog.Tags,
+		PublishedAt: blog.PublishedAt,
+		CreatedAt:   blog.CreatedAt,
+		UpdatedAt:   blog.UpdatedAt,
+	})
+}
+
+// GetBlogBySlug handles getting a blog by its slug. A slug the blog used to have
+// is permanently redirected to its current one.
+func (h *BlogHandler) GetBlogBySlug(c echo.Context) error {
+	slug := c.Param("slug")
+	if slug == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Slug is required"})
+	}
+
+	blog, currentSlug, err := h.blogUseCase.GetBlogBySlug(c.Request().Context(), slug)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if currentSlug != "" {
+		return c.Redirect(http.StatusMovedPermanently, "/api/v1/blogs/by-slug/"+currentSlug)
+	}
+
+	return c.JSON(http.StatusOK, dto.BlogResponse{
+		ID:          blog.ID,
+		Title:       blog.Title,
+		Slug:        blog.Slug,
 		Content:     blog.Content,
 		AuthorID:    blog.AuthorID,
 		Status:      blog.Status,
@@ -93,7 +126,13 @@ func (h *BlogHandler) CreateBlog(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	blog, err := h.blogUseCase.CreateBlog(c.Request().Context(), req.Title, req.Content, req.AuthorID, req.Tags)
+	// The author is the user from the token, never the request body
+	authorID := c.Get("user_id").(string)
+
+	blog, err := h.blogUseCase.CreateBlog(c.Request().Context(), req.Title, req.Content, authorID, req.Tags, req.Slug)
+	if errors.Is(err, usecases.ErrSlugTaken) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -101,6 +140,7 @@ func (h *BlogHandler) CreateBlog(c echo.Context) error {
 	return c.JSON(http.StatusCreated, dto.BlogResponse{
 		ID:          blog.ID,
 		Title:       blog.Title,
+		Slug:        blog.Slug,
 		Content:     blog.Content,
 		AuthorID:    blog.AuthorID,
 		Status:      blog.Status,
@@ -126,7 +166,10 @@ func (h *BlogHandler) UpdateBlog(c echo.Context) error {
 	// Get user ID from token
 	userID := c.Get("user_id").(string)
 
-	blog, err := h.blogUseCase.UpdateBlog(c.Request().Context(), id, req.Title, req.Content, req.Tags, userID)
+	blog, err := h.blogUseCase.UpdateBlog(c.Request().Context(), id, req.Title, req.Content, req.Tags, req.Slug, userID)
+	if errors.Is(err, usecases.ErrSlugTaken) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -134,6 +177,7 @@ func (h *BlogHandler) UpdateBlog(c echo.Context) error {
 	return c.JSON(http.StatusOK, dto.BlogResponse{
 		ID:          blog.ID,
 		Title:       blog.Title,
+		Slug:        blog.Slug,
 		Content:     blog.Content,
 		AuthorID:    blog.AuthorID,
 		Status:      blog.Status,
@@ -162,6 +206,7 @@ func (h *BlogHandler) PublishBlog(c echo.Context) error {
 	return c.JSON(http.StatusOK, dto.BlogResponse{
 		ID:          blog.ID,
 		Title:       blog.Title,
+		Slug:        blog.Slug,
 		Content:     blog.Content,
 		AuthorID:    blog.AuthorID,
 		Status:      blog.Status,
@@ -179,10 +224,11 @@ func (h *BlogHandler) DeleteBlog(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
 	}
 
-	// Get user ID from token
+	// Get user ID and role from token
 	userID := c.Get("user_id").(string)
+	role, _ := c.Get("user_role").(string)
 
-	if err := h.blogUseCase.DeleteBlog(c.Request().Context(), id, userID); err != nil {
+	if err := h.blogUseCase.DeleteBlog(c.Request().Context(), id, userID, role); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
@@ -1,63 +1,174 @@
 package middleware
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
-	"net/http"
-	"strings"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
 )
 
 // AuthMiddleware handles authentication
 type AuthMiddleware struct {
-	jwtSecret string
+	keys     jwks.KeyProvider
+	pats     pat.Verifier
+	cookies  cookieauth.Config
+	audience string
 }
 
-// NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(jwtSecret string) *AuthMiddleware {
+// NewAuthMiddleware creates a new auth middleware that verifies tokens against the auth service's public keys.
+// Tokens are read from the Authorization header or, for browser sessions, the access token cookie.
+// Personal access tokens are verified by the auth service; service tokens must be issued for the audience,
+// and a service acting for a user must forward the user's token.
+func NewAuthMiddleware(keys jwks.KeyProvider, pats pat.Verifier, cookies cookieauth.Config, audience string) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSecret: jwtSecret,
+		keys:     keys,
+		pats:     pats,
+		cookies:  cookies,
+		audience: audience,
 	}
 }
 
 // Authenticate authenticates a request
 func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		// Get token from header
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
+		// Get token from header or cookie
+		tokenString, err := m.cookies.AccessToken(c.Request())
+		switch {
+		case errors.Is(err, cookieauth.ErrMissingToken):
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header is required"})
+		case errors.Is(err, cookieauth.ErrInvalidCSRFToken):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Invalid CSRF token"})
+		case err != nil:
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid authorization format"})
 		}
 
-		// Check if token is in correct format
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid authorization format"})
+		// Resolve personal access tokens to the user they act for
+		if pat.IsToken(tokenString) {
+			principal, err := m.pats.Verify(c.Request().Context(), tokenString)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			}
+
+			c.Set("user_id", principal.UserID)
+			c.Set("user_role", principal.Role)
+			c.Set("user_verified", principal.Verified)
+			c.Set("token_principal", principal)
+			return next(c)
 		}
 
 		// Parse token
-		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
-			return []byte(m.jwtSecret), nil
-		})
-
+		claims, err := m.parse(c, tokenString)
 		if err != nil {
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
 		}
 
-		// Check if token is valid
-		if !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		// Tell end users from internal services
+		principal, err := authz.NewPrincipal(claims, m.audience, c.Request())
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 		}
+		c.Set(authz.PrincipalKey, principal)
 
-		// Get claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token claims"})
+		// A service acting for a user is held to the user's own token
+		if principal.ActsForUser() {
+			subjectToken := c.Request().Header.Get(authz.SubjectTokenHeader)
+			if pat.IsToken(subjectToken) {
+				subject, err := m.pats.Verify(c.Request().Context(), subjectToken)
+				if err != nil || subject.UserID != principal.OnBehalfOf {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": authz.ErrInvalidSubjectToken.Error()})
+				}
+
+				c.Set("user_id", subject.UserID)
+				c.Set("user_role", subject.Role)
+				c.Set("user_verified", subject.Verified)
+				c.Set("token_principal", subject)
+				return next(c)
+			}
+
+			// It must be the user's access token for this service, not another service's
+			claims, err = m.parse(c, subjectToken)
+			if err != nil || authz.CheckAccessToken(claims, m.audience) != nil ||
+				claims["sub"] != principal.OnBehalfOf || claims["client_id"] == principal.OnBehalfOf {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": authz.ErrInvalidSubjectToken.Error()})
+			}
 		}
 
 		// Set user ID in context
-		c.Set("user_id", claims["sub"].(string))
-		c.Set("user_role", claims["role"].(string))
+		userID := principal.UserID()
+		c.Set("user_id", userID)
+		role, _ := claims["role"].(string)
+		c.Set("user_role", role)
+
+		// Set email verification status in context
+		verified, _ := claims["verified"].(bool)
+		c.Set("user_verified", verified)
+
+		// Tag requests made by an admin acting as the user
+		if actor := authz.Actor(claims); actor != "" {
+			c.Set("impersonator_id", actor)
+			authz.LogImpersonatedRequest(c.Request(), userID, actor)
+		}
+
+		return next(c)
+	}
+}
+
+// parse verifies a JWT against the auth service's public keys and returns its claims
+func (m *AuthMiddleware) parse(c echo.Context, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Only accept asymmetric signatures so a verifier can never forge tokens
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		default:
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return m.keys.Key(c.Request().Context(), kid)
+	})
+
+	// Check if token is valid
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	// Get claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// RequireVerifiedEmail rejects requests from users whose email is not verified
+func (m *AuthMiddleware) RequireVerifiedEmail(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		verified, _ := c.Get("user_verified").(bool)
+		if !verified {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Email address must be verified"})
+		}
 
 		return next(c)
 	}
 }
+
+// RequireScope rejects personal access tokens that were not granted the scope.
+// Session tokens act with the user's full permissions.
+func (m *AuthMiddleware) RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if principal, ok := c.Get("token_principal").(*pat.Principal); ok && !principal.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Token is missing the " + scope + " scope"})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -1,24 +1,46 @@
 package http
 
 import (
+	"os"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/interfaces/http/handlers"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/interfaces/http/middleware"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/usecases"
-	"os"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
 )
 
 // RegisterRoutes registers all API routes
-func RegisterRoutes(e *echo.Echo, blogUseCase *usecases.BlogUseCase) {
+func RegisterRoutes(e *echo.Echo, blogUseCase *usecases.BlogUseCase, erasureUseCase *usecases.AuthorErasureUseCase, exportUseCase *usecases.DataExportUseCase, authzChecker *authz.Checker) {
 	// Create handlers
 	blogHandler := handlers.NewBlogHandler(blogUseCase)
+	erasureHandler := handlers.NewErasureHandler(erasureUseCase)
+	exportHandler := handlers.NewDataExportHandler(exportUseCase)
 
 	// Create middleware
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "dev_secret_key"
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = "http://localhost:8081/.well-known/jwks.json"
+	}
+	patVerifyURL := os.Getenv("PAT_VERIFY_URL")
+	if patVerifyURL == "" {
+		patVerifyURL = "http://localhost:8081/api/v1/auth/tokens/verify"
 	}
-	authMiddleware := middleware.NewAuthMiddleware(jwtSecret)
+	serviceAudience := os.Getenv("SERVICE_AUDIENCE")
+	if serviceAudience == "" {
+		serviceAudience = "blog-service"
+	}
+	authMiddleware := middleware.NewAuthMiddleware(
+		jwks.NewClient(jwksURL, 5*time.Minute),
+		pat.NewClient(patVerifyURL, 30*time.Second),
+		cookieauth.LoadConfig(),
+		serviceAudience,
+	)
+	authzMiddleware := authz.NewMiddleware(authzChecker, "user_role")
 
 	// API v1 group
 	v1 := e.Group("/api/v1")
@@ -26,9 +48,21 @@ func RegisterRoutes(e *echo.Echo, blogUseCase *usecases.BlogUseCase) {
 	// Blog routes
 	blogs := v1.Group("/blogs")
 	blogs.GET("", blogHandler.GetBlogs)
+	blogs.GET("/by-slug/:slug", blogHandler.GetBlogBySlug)
 	blogs.GET("/:id", blogHandler.GetBlog)
-	blogs.POST("", blogHandler.CreateBlog, authMiddleware.Authenticate)
-	blogs.PUT("/:id", blogHandler.UpdateBlog, authMiddleware.Authenticate)
-	blogs.POST("/:id/publish", blogHandler.PublishBlog, authMiddleware.Authenticate)
-	blogs.DELETE("/:id", blogHandler.DeleteBlog, authMiddleware.Authenticate)
+	blogsWrite := authMiddleware.RequireScope("blogs:write")
+	blogs.POST("", blogHandler.CreateBlog, authMiddleware.Authenticate, blogsWrite, authzMiddleware.RequirePermission(authz.BlogCreate))
+	blogs.PUT("/:id", blogHandler.UpdateBlog, authMiddleware.Authenticate, blogsWrite)
+	blogs.POST("/:id/publish", blogHandler.PublishBlog, authMiddleware.Authenticate, blogsWrite, authMiddleware.RequireVerifiedEmail, authzMiddleware.RequirePermission(authz.BlogPublish))
+	blogs.DELETE("/:id", blogHandler.DeleteBlog, authMiddleware.Authenticate, blogsWrite)
+
+	// Internal routes for erasing deleted accounts, called by the auth service
+	erasures := v1.Group("/internal/erasures", authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserErase))
+	erasures.PUT("/:id", erasureHandler.Erase)
+	erasures.DELETE("/:id", erasureHandler.Revert)
+	erasures.POST("/:id/finalize", erasureHandler.Finalize)
+
+	// Internal routes for personal data exports, called by the API gateway
+	exports := v1.Group("/internal/exports", authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserExport))
+	exports.GET("/:userID", exportHandler.Export)
 }
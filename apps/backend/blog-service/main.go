@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/vcd-simple-blog/apps/backend/blog-service/infrastructure/repository"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/interfaces/http"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
 )
 
 func main() {
@@ -28,9 +30,19 @@ func main() {
 
 	// Initialize repositories
 	blogRepo := repository.NewBlogRepository(db)
+	blogSlugRedirectRepo := repository.NewBlogSlugRedirectRepository(db)
+	authorErasureRepo := repository.NewAuthorErasureRepository(db)
 
 	// Initialize use cases
-	blogUseCase := usecases.NewBlogUseCase(blogRepo)
+	authzChecker := authz.NewChecker(authz.DefaultMatrix)
+	blogUseCase := usecases.NewBlogUseCase(blogRepo, blogSlugRedirectRepo, authzChecker)
+	erasureUseCase := usecases.NewAuthorErasureUseCase(authorErasureRepo, cfg.AuthorErasure)
+	exportUseCase := usecases.NewDataExportUseCase(blogRepo)
+
+	// Give blogs created before slugs existed a slug
+	if err := blogUseCase.AssignMissingSlugs(context.Background()); err != nil {
+		log.Printf("Failed to assign missing blog slugs: %v", err)
+	}
 
 	// Create Echo instance
 	e := echo.New()
@@ -41,7 +53,7 @@ func main() {
 	e.Use(middleware.CORS())
 
 	// Initialize API routes
-	http.RegisterRoutes(e, blogUseCase)
+	http.RegisterRoutes(e, blogUseCase, erasureUseCase, exportUseCase, authzChecker)
 
 	// Start server
 	port := os.Getenv("PORT")
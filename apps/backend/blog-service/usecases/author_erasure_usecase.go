@@ -0,0 +1,47 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/config"
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/repository"
+)
+
+// AuthorErasureUseCase removes deleted accounts from their blogs, following the deployment's erasure policy:
+// blogs are either anonymized or reassigned to another author
+type AuthorErasureUseCase struct {
+	erasureRepo repository.AuthorErasureRepository
+	config      config.AuthorErasureConfig
+}
+
+// NewAuthorErasureUseCase creates a new author erasure use case
+func NewAuthorErasureUseCase(erasureRepo repository.AuthorErasureRepository, config config.AuthorErasureConfig) *AuthorErasureUseCase {
+	return &AuthorErasureUseCase{
+		erasureRepo: erasureRepo,
+		config:      config,
+	}
+}
+
+// EraseAuthor anonymizes or reassigns every blog by a user
+func (uc *AuthorErasureUseCase) EraseAuthor(ctx context.Context, erasureID, userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+
+	replacement := uc.config.AnonymousAuthorID
+	if uc.config.Policy == "reassign" {
+		replacement = uc.config.ReassignTo
+	}
+	return uc.erasureRepo.Apply(ctx, erasureID, userID, replacement)
+}
+
+// RevertErasure gives a user's blogs back to them
+func (uc *AuthorErasureUseCase) RevertErasure(ctx context.Context, erasureID string) error {
+	return uc.erasureRepo.Revert(ctx, erasureID)
+}
+
+// FinalizeErasure makes an erasure permanent
+func (uc *AuthorErasureUseCase) FinalizeErasure(ctx context.Context, erasureID string) error {
+	return uc.erasureRepo.Finalize(ctx, erasureID)
+}
@@ -3,21 +3,40 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
 	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/repository"
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/valueobject"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
 )
 
+// maxSlugSuffix is the highest numeric suffix tried before a generated slug falls back to the blog's ID
+const maxSlugSuffix = 100
+
+// maxSlugConflicts is how many times a blog is saved with the next generated slug when another blog
+// took its slug between the availability check and the save
+const maxSlugConflicts = 5
+
+// ErrSlugTaken is returned when a slug chosen by hand belongs to another blog
+var ErrSlugTaken = errors.New("slug is already taken")
+
 // BlogUseCase implements the blog use cases
 type BlogUseCase struct {
-	blogRepo repository.BlogRepository
+	blogRepo         repository.BlogRepository
+	slugRedirectRepo repository.BlogSlugRedirectRepository
+	authz            *authz.Checker
 }
 
 // NewBlogUseCase creates a new blog use case
-func NewBlogUseCase(blogRepo repository.BlogRepository) *BlogUseCase {
+func NewBlogUseCase(blogRepo repository.BlogRepository, slugRedirectRepo repository.BlogSlugRedirectRepository, checker *authz.Checker) *BlogUseCase {
 	return &BlogUseCase{
-		blogRepo: blogRepo,
+		blogRepo:         blogRepo,
+		slugRedirectRepo: slugRedirectRepo,
+		authz:            checker,
 	}
 }
 
@@ -31,28 +50,52 @@ func (uc *BlogUseCase) GetBlogByID(ctx context.Context, id string) (*entity.Blog
 	return uc.blogRepo.FindByID(ctx, id)
 }
 
+// GetBlogBySlug retrieves a blog by its slug. For a slug the blog used to have, it returns
+// the blog's current slug instead, so the caller can redirect to it.
+func (uc *BlogUseCase) GetBlogBySlug(ctx context.Context, slug string) (*entity.Blog, string, error) {
+	blog, err := uc.blogRepo.FindBySlug(ctx, slug)
+	if err == nil {
+		return blog, "", nil
+	}
+
+	redirect, err := uc.slugRedirectRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, "", errors.New("blog not found")
+	}
+	blog, err = uc.blogRepo.FindByID(ctx, redirect.BlogID)
+	if err != nil || blog.Slug == "" {
+		return nil, "", errors.New("blog not found")
+	}
+	return nil, blog.Slug, nil
+}
+
 // GetBlogsByAuthor retrieves blogs by author ID
 func (uc *BlogUseCase) GetBlogsByAuthor(ctx context.Context, authorID string, limit, offset int) ([]*entity.Blog, error) {
 	return uc.blogRepo.FindByAuthorID(ctx, authorID, limit, offset)
 }
 
-// CreateBlog creates a new blog
-func (uc *BlogUseCase) CreateBlog(ctx context.Context, title, content, authorID string, tags []string) (*entity.Blog, error) {
+// CreateBlog creates a new blog. Without a slug, one is generated from the title.
+func (uc *BlogUseCase) CreateBlog(ctx context.Context, title, content, authorID string, tags []string, slug string) (*entity.Blog, error) {
 	id := uuid.New().String()
 	blog, err := entity.NewBlog(id, title, content, authorID, tags)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := uc.blogRepo.Create(ctx, blog); err != nil {
+	if err := uc.assignSlug(ctx, blog, slug); err != nil {
+		return nil, err
+	}
+
+	if err := uc.saveBlog(ctx, blog, "", uc.blogRepo.Create); err != nil {
 		return nil, err
 	}
 
 	return blog, nil
 }
 
-// UpdateBlog updates a blog
-func (uc *BlogUseCase) UpdateBlog(ctx context.Context, id, title, content string, tags []string, userID string) (*entity.Blog, error) {
+// UpdateBlog updates a blog. A new title gives the blog a new slug, unless its slug was chosen by hand;
+// the old slug keeps leading to the blog.
+func (uc *BlogUseCase) UpdateBlog(ctx context.Context, id, title, content string, tags []string, slug, userID string) (*entity.Blog, error) {
 	blog, err := uc.blogRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -62,14 +105,40 @@ func (uc *BlogUseCase) UpdateBlog(ctx context.Context, id, title, content string
 		return nil, errors.New("user is not the author of this blog")
 	}
 
+	previousTitle, previousSlug := blog.Title, blog.Slug
 	if err := blog.Update(title, content, tags); err != nil {
 		return nil, err
 	}
 
-	if err := uc.blogRepo.Update(ctx, blog); err != nil {
+	// Choose the new slug
+	if slug != "" || (blog.Title != previousTitle && !blog.CustomSlug) || blog.Slug == "" {
+		if err := uc.assignSlug(ctx, blog, slug); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the old slug leading to the blog before moving it to the new one
+	if previousSlug != "" && blog.Slug != previousSlug {
+		redirect, err := entity.NewBlogSlugRedirect(previousSlug, blog.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.slugRedirectRepo.Save(ctx, redirect); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.saveBlog(ctx, blog, previousSlug, uc.blogRepo.Update); err != nil {
 		return nil, err
 	}
 
+	// A blog going back to one of its old slugs no longer needs the redirect
+	if blog.Slug != previousSlug {
+		if err := uc.slugRedirectRepo.Delete(ctx, blog.Slug); err != nil {
+			log.Printf("failed to delete slug redirect %s: %v", blog.Slug, err)
+		}
+	}
+
 	return blog, nil
 }
 
@@ -95,16 +164,111 @@ func (uc *BlogUseCase) PublishBlog(ctx context.Context, id, userID string) (*ent
 	return blog, nil
 }
 
-// DeleteBlog deletes a blog
-func (uc *BlogUseCase) DeleteBlog(ctx context.Context, id, userID string) error {
+// DeleteBlog deletes a blog; moderators may delete any blog
+func (uc *BlogUseCase) DeleteBlog(ctx context.Context, id, userID, role string) error {
 	blog, err := uc.blogRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if !blog.IsAuthor(userID) {
+	if !blog.IsAuthor(userID) && !uc.authz.Can(role, authz.BlogDeleteAny) {
 		return errors.New("user is not the author of this blog")
 	}
 
-	return uc.blogRepo.Delete(ctx, id)
+	if err := uc.blogRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Free the blog's old slugs
+	return uc.slugRedirectRepo.DeleteByBlogID(ctx, id)
+}
+
+// AssignMissingSlugs gives every blog created before slugs existed a slug generated from its title
+func (uc *BlogUseCase) AssignMissingSlugs(ctx context.Context) error {
+	for {
+		blogs, err := uc.blogRepo.FindWithoutSlug(ctx, 100)
+		if err != nil {
+			return err
+		}
+		if len(blogs) == 0 {
+			return nil
+		}
+
+		for _, blog := range blogs {
+			if err := uc.assignSlug(ctx, blog, ""); err != nil {
+				return err
+			}
+			if err := uc.saveBlog(ctx, blog, "", uc.blogRepo.Update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// assignSlug gives a blog the slug chosen by hand, or else one generated from its title.
+// A generated slug that is taken gets the first free numeric suffix.
+func (uc *BlogUseCase) assignSlug(ctx context.Context, blog *entity.Blog, slug string) error {
+	if slug != "" {
+		if err := valueobject.ValidateSlug(slug); err != nil {
+			return err
+		}
+		if !uc.slugAvailable(ctx, slug, blog.ID) {
+			return ErrSlugTaken
+		}
+		return blog.SetSlug(slug, true)
+	}
+
+	// Titles without anything to spell in ASCII fall back to the blog's ID
+	base := valueobject.Slugify(blog.Title)
+	if base == "" {
+		base = blog.ID
+	}
+
+	for n := 1; n <= maxSlugSuffix; n++ {
+		candidate := base
+		if n > 1 {
+			suffix := fmt.Sprintf("-%d", n)
+			if len(base)+len(suffix) > valueobject.MaxSlugLength {
+				candidate = strings.TrimRight(base[:valueobject.MaxSlugLength-len(suffix)], "-")
+			}
+			candidate += suffix
+		}
+
+		if candidate == blog.Slug || uc.slugAvailable(ctx, candidate, blog.ID) {
+			return blog.SetSlug(candidate, false)
+		}
+	}
+	return blog.SetSlug(blog.ID, false)
+}
+
+// saveBlog saves a blog with save. When another blog took a generated slug since it was assigned,
+// the blog moves to the next free slug and is saved again; a slug chosen by hand is reported as taken.
+// previousSlug is the slug the blog was stored with, which it may keep.
+func (uc *BlogUseCase) saveBlog(ctx context.Context, blog *entity.Blog, previousSlug string, save func(context.Context, *entity.Blog) error) error {
+	for conflicts := 0; ; conflicts++ {
+		err := save(ctx, blog)
+		if !errors.Is(err, repository.ErrSlugConflict) {
+			return err
+		}
+		if blog.CustomSlug || conflicts >= maxSlugConflicts {
+			return ErrSlugTaken
+		}
+
+		// Forget the slug that was taken so it is checked again
+		blog.Slug = previousSlug
+		if err := uc.assignSlug(ctx, blog, ""); err != nil {
+			return err
+		}
+	}
+}
+
+// slugAvailable checks that no other blog uses a slug now or used it before
+func (uc *BlogUseCase) slugAvailable(ctx context.Context, slug, blogID string) bool {
+	if blog, err := uc.blogRepo.FindBySlug(ctx, slug); err == nil && blog.ID != blogID {
+		return false
+	}
+	if redirect, err := uc.slugRedirectRepo.FindBySlug(ctx, slug); err == nil && redirect.BlogID != blogID {
+		return false
+	}
+	return true
 }
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/valueobject"
+)
+
+type blogTest struct {
+	t         *testing.T
+	blogs     *BlogUseCase
+	repo      *fakeBlogRepository
+	redirects *fakeBlogSlugRedirectRepository
+}
+
+func newBlogTest(t *testing.T) *blogTest {
+	test := &blogTest{
+		t:         t,
+		repo:      newFakeBlogRepository(),
+		redirects: newFakeBlogSlugRedirectRepository(),
+	}
+	test.blogs = NewBlogUseCase(test.repo, test.redirects, nil)
+	return test
+}
+
+// create creates a blog by the test author
+func (test *blogTest) create(title, slug string) *entity.Blog {
+	test.t.Helper()
+
+	blog, err := test.blogs.CreateBlog(context.Background(), title, "content", "author-1", nil, slug)
+	if err != nil {
+		test.t.Fatalf("CreateBlog(%q): %v", title, err)
+	}
+	return blog
+}
+
+func TestCreateBlogGeneratesSlugsWithSuffixes(t *testing.T) {
+	test := newBlogTest(t)
+
+	want := []string{"hello-world", "hello-world-2", "hello-world-3"}
+	for _, slug := range want {
+		if blog := test.create("Hello, World!", ""); blog.Slug != slug || blog.CustomSlug {
+			t.Errorf("got slug %q custom %t, want generated %q", blog.Slug, blog.CustomSlug, slug)
+		}
+	}
+}
+
+func TestCreateBlogTruncatesSlugBeforeSuffix(t *testing.T) {
+	test := newBlogTest(t)
+
+	// The title's slug fills the maximum length, with a hyphen where the suffix has to go
+	title := strings.Repeat("a", valueobject.MaxSlugLength-3) + " bc"
+	first := test.create(title, "")
+	if len(first.Slug) != valueobject.MaxSlugLength {
+		t.Fatalf("got slug of length %d, want %d", len(first.Slug), valueobject.MaxSlugLength)
+	}
+
+	second := test.create(title, "")
+	if want := strings.Repeat("a", valueobject.MaxSlugLength-3) + "-2"; second.Slug != want {
+		t.Errorf("got slug %q, want %q", second.Slug, want)
+	}
+}
+
+func TestCreateBlogRefusesTakenCustomSlug(t *testing.T) {
+	test := newBlogTest(t)
+	test.create("First", "my-post")
+
+	if _, err := test.blogs.CreateBlog(context.Background(), "Second", "content", "author-1", nil, "my-post"); !errors.Is(err, ErrSlugTaken) {
+		t.Fatalf("got %v, want ErrSlugTaken", err)
+	}
+	if _, err := test.blogs.CreateBlog(context.Background(), "Second", "content", "author-1", nil, "Not A Slug"); err == nil {
+		t.Fatal("CreateBlog accepted a malformed slug")
+	}
+}
+
+func TestCreateBlogRetriesSlugTakenConcurrently(t *testing.T) {
+	test := newBlogTest(t)
+
+	// Another blog takes the slug between the availability check and the save
+	test.repo.raced["hello-world"] = true
+
+	blog := test.create("Hello, World!", "")
+	if blog.Slug != "hello-world-2" {
+		t.Errorf("got slug %q, want hello-world-2", blog.Slug)
+	}
+	if stored, err := test.repo.FindByID(context.Background(), blog.ID); err != nil || stored.Slug != "hello-world-2" {
+		t.Errorf("stored blog %+v: %v", stored, err)
+	}
+}
+
+func TestUpdateBlogTitleRedirectsOldSlug(t *testing.T) {
+	test := newBlogTest(t)
+	ctx := context.Background()
+	blog := test.create("Hello, World!", "")
+
+	updated, err := test.blogs.UpdateBlog(ctx, blog.ID, "Goodbye, World!", "content", nil, "", "author-1")
+	if err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+	if updated.Slug != "goodbye-world" {
+		t.Fatalf("got slug %q, want goodbye-world", updated.Slug)
+	}
+
+	// The old slug leads to the new one
+	found, redirect, err := test.blogs.GetBlogBySlug(ctx, "hello-world")
+	if err != nil || found != nil || redirect != "goodbye-world" {
+		t.Fatalf("GetBlogBySlug(hello-world) = %v, %q, %v; want a redirect to goodbye-world", found, redirect, err)
+	}
+	if found, _, err := test.blogs.GetBlogBySlug(ctx, "goodbye-world"); err != nil || found.ID != blog.ID {
+		t.Fatalf("GetBlogBySlug(goodbye-world) = %v, %v", found, err)
+	}
+
+	// Another blog cannot take the old slug
+	if other := test.create("Hello, World!", ""); other.Slug != "hello-world-2" {
+		t.Errorf("got slug %q for a new blog, want hello-world-2", other.Slug)
+	}
+
+	// Going back to the old title takes the old slug back and drops its redirect
+	reverted, err := test.blogs.UpdateBlog(ctx, blog.ID, "Hello, World!", "content", nil, "", "author-1")
+	if err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+	if reverted.Slug != "hello-world" {
+		t.Errorf("got slug %q, want hello-world", reverted.Slug)
+	}
+	if _, err := test.redirects.FindBySlug(ctx, "hello-world"); err == nil {
+		t.Error("the redirect for the blog's current slug was kept")
+	}
+}
+
+func TestUpdateBlogKeepsCustomSlug(t *testing.T) {
+	test := newBlogTest(t)
+	blog := test.create("Hello, World!", "my-post")
+
+	updated, err := test.blogs.UpdateBlog(context.Background(), blog.ID, "Goodbye, World!", "content", nil, "", "author-1")
+	if err != nil {
+		t.Fatalf("UpdateBlog: %v", err)
+	}
+	if updated.Slug != "my-post" || !updated.CustomSlug {
+		t.Errorf("got slug %q custom %t, want my-post kept", updated.Slug, updated.CustomSlug)
+	}
+}
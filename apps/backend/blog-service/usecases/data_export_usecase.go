@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/repository"
+	"github.com/vcd-simple-blog/packages/go/common/dataexport"
+)
+
+// exportPageSize is how many blogs are read at a time while exporting
+const exportPageSize = 100
+
+// exportedBlog is a blog's entry in the blog index of a data export
+type exportedBlog struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug,omitempty"`
+	Status      string     `json:"status"`
+	Tags        []string   `json:"tags"`
+	File        string     `json:"file"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// DataExportUseCase gathers a user's blogs for their personal data export
+type DataExportUseCase struct {
+	blogRepo repository.BlogRepository
+}
+
+// NewDataExportUseCase creates a new data export use case
+func NewDataExportUseCase(blogRepo repository.BlogRepository) *DataExportUseCase {
+	return &DataExportUseCase{
+		blogRepo: blogRepo,
+	}
+}
+
+// ExportUserData exports every blog by a user as a Markdown file, with an index of them in JSON
+func (uc *DataExportUseCase) ExportUserData(ctx context.Context, userID string) (*dataexport.Bundle, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	bundle := &dataexport.Bundle{}
+	index := []exportedBlog{}
+	for offset := 0; ; offset += exportPageSize {
+		blogs, err := uc.blogRepo.FindByAuthorID(ctx, userID, exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, blog := range blogs {
+			// Slugs are unique, so they make readable file names
+			file := "blogs/" + blog.ID + ".md"
+			if blog.Slug != "" {
+				file = "blogs/" + blog.Slug + ".md"
+			}
+			bundle.AddText(file, blogMarkdown(blog))
+			index = append(index, exportedBlog{
+				ID:          blog.ID,
+				Title:       blog.Title,
+				Slug:        blog.Slug,
+				Status:      string(blog.Status),
+				Tags:        blog.Tags,
+				File:        file,
+				PublishedAt: blog.PublishedAt,
+				CreatedAt:   blog.CreatedAt,
+				UpdatedAt:   blog.UpdatedAt,
+			})
+		}
+
+		if len(blogs) < exportPageSize {
+			break
+		}
+	}
+
+	if err := bundle.AddJSON("blogs.json", index); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// blogMarkdown renders a blog as Markdown with its metadata as front matter
+func blogMarkdown(blog *entity.Blog) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %s\n", blog.ID)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(blog.Title))
+	if blog.Slug != "" {
+		fmt.Fprintf(&b, "slug: %s\n", blog.Slug)
+	}
+	fmt.Fprintf(&b, "status: %s\n", blog.Status)
+
+	tags := make([]string, len(blog.Tags))
+	for i, tag := range blog.Tags {
+		tags[i] = strconv.Quote(tag)
+	}
+	fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(tags, ", "))
+
+	fmt.Fprintf(&b, "created_at: %s\n", blog.CreatedAt.UTC().Format(time.RFC3339))
+	if blog.PublishedAt != nil {
+		fmt.Fprintf(&b, "published_at: %s\n", blog.PublishedAt.UTC().Format(time.RFC3339))
+	}
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", blog.Title)
+	b.WriteString(blog.Content)
+	if !strings.HasSuffix(blog.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
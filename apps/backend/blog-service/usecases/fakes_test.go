@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/entity"
+	"github.com/vcd-simple-blog/apps/backend/blog-service/domain/repository"
+)
+
+// fakeBlogRepository keeps blogs in memory and enforces unique slugs like the database's index
+type fakeBlogRepository struct {
+	mu    sync.Mutex
+	blogs map[string]*entity.Blog
+
+	// raced are slugs another blog takes between the availability check and the save
+	raced map[string]bool
+}
+
+func newFakeBlogRepository() *fakeBlogRepository {
+	return &fakeBlogRepository{blogs: make(map[string]*entity.Blog), raced: make(map[string]bool)}
+}
+
+func (r *fakeBlogRepository) FindAll(ctx context.Context, limit, offset int) ([]*entity.Blog, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeBlogRepository) FindByID(ctx context.Context, id string) (*entity.Blog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	blog, ok := r.blogs[id]
+	if !ok {
+		return nil, errors.New("blog not found")
+	}
+	copied := *blog
+	return &copied, nil
+}
+
+func (r *fakeBlogRepository) FindBySlug(ctx context.Context, slug string) (*entity.Blog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, blog := range r.blogs {
+		if blog.Slug == slug {
+			copied := *blog
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("blog not found")
+}
+
+func (r *fakeBlogRepository) FindWithoutSlug(ctx context.Context, limit int) ([]*entity.Blog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var blogs []*entity.Blog
+	for _, blog := range r.blogs {
+		if blog.Slug == "" && len(blogs) < limit {
+			copied := *blog
+			blogs = append(blogs, &copied)
+		}
+	}
+	return blogs, nil
+}
+
+func (r *fakeBlogRepository) FindByAuthorID(ctx context.Context, authorID string, limit, offset int) ([]*entity.Blog, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeBlogRepository) Create(ctx context.Context, blog *entity.Blog) error {
+	return r.save(blog)
+}
+
+func (r *fakeBlogRepository) Update(ctx context.Context, blog *entity.Blog) error {
+	return r.save(blog)
+}
+
+func (r *fakeBlogRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.blogs, id)
+	return nil
+}
+
+// save stores a blog unless another blog has its slug
+func (r *fakeBlogRepository) save(blog *entity.Blog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.raced[blog.Slug] {
+		delete(r.raced, blog.Slug)
+		r.blogs["racer-"+blog.Slug] = &entity.Blog{ID: "racer-" + blog.Slug, Slug: blog.Slug}
+		return repository.ErrSlugConflict
+	}
+	for _, other := range r.blogs {
+		if blog.Slug != "" && other.Slug == blog.Slug && other.ID != blog.ID {
+			return repository.ErrSlugConflict
+		}
+	}
+	copied := *blog
+	r.blogs[blog.ID] = &copied
+	return nil
+}
+
+// fakeBlogSlugRedirectRepository keeps slug redirects in memory
+type fakeBlogSlugRedirectRepository struct {
+	mu        sync.Mutex
+	redirects map[string]*entity.BlogSlugRedirect
+}
+
+func newFakeBlogSlugRedirectRepository() *fakeBlogSlugRedirectRepository {
+	return &fakeBlogSlugRedirectRepository{redirects: make(map[string]*entity.BlogSlugRedirect)}
+}
+
+func (r *fakeBlogSlugRedirectRepository) FindBySlug(ctx context.Context, slug string) (*entity.BlogSlugRedirect, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	redirect, ok := r.redirects[slug]
+	if !ok {
+		return nil, errors.New("slug redirect not found")
+	}
+	copied := *redirect
+	return &copied, nil
+}
+
+func (r *fakeBlogSlugRedirectRepository) Save(ctx context.Context, redirect *entity.BlogSlugRedirect) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *redirect
+	r.redirects[redirect.Slug] = &copied
+	return nil
+}
+
+func (r *fakeBlogSlugRedirectRepository) Delete(ctx context.Context, slug string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.redirects, slug)
+	return nil
+}
+
+func (r *fakeBlogSlugRedirectRepository) DeleteByBlogID(ctx context.Context, blogID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for slug, redirect := range r.redirects {
+		if redirect.BlogID == blogID {
+			delete(r.redirects, slug)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ErasedProfile keeps a deleted account's profile until the erasure is finalized, so it can be restored
+// if the account deletion is rolled back. Snapshot is empty when the user had no profile.
+type ErasedProfile struct {
+	ErasureID string `gorm:"primaryKey"`
+	UserID    string `gorm:"index"`
+	Snapshot  string `gorm:"type:text"`
+	ErasedAt  time.Time
+}
+
+// NewErasedProfile creates an erased profile holding a snapshot of the user, which may be nil
+func NewErasedProfile(erasureID, userID string, user *User) (*ErasedProfile, error) {
+	snapshot := ""
+	if user != nil {
+		encoded, err := json.Marshal(user)
+		if err != nil {
+			return nil, err
+		}
+		snapshot = string(encoded)
+	}
+
+	return &ErasedProfile{
+		ErasureID: erasureID,
+		UserID:    userID,
+		Snapshot:  snapshot,
+		ErasedAt:  time.Now(),
+	}, nil
+}
+
+// User decodes the snapshot, returning nil when the user had no profile
+func (p *ErasedProfile) User() (*User, error) {
+	if p.Snapshot == "" {
+		return nil, nil
+	}
+
+	var user User
+	if err := json.Unmarshal([]byte(p.Snapshot), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
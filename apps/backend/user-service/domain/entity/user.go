@@ -61,6 +61,17 @@ func (u *User) UpdateProfile(displayName, bio, avatarURL string) {
 	u.UpdatedAt = time.Now()
 }
 
+// ChangeEmail changes the user's email address
+func (u *User) ChangeEmail(email string) error {
+	if email == "" {
+		return errors.New("email cannot be empty")
+	}
+
+	u.Email = email
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
 // SetProfileStatus sets the user's profile status
 func (u *User) SetProfileStatus(status valueobject.ProfileStatus) {
 	u.ProfileStatus = status
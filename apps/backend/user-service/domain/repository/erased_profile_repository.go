@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+)
+
+// ErasedProfileRepository defines the interface for erasing profiles of deleted accounts.
+// Every method is idempotent.
+type ErasedProfileRepository interface {
+	Erase(ctx context.Context, erasureID, userID string) error
+	Restore(ctx context.Context, erasureID string) error
+	Finalize(ctx context.Context, erasureID string) error
+}
@@ -1,15 +1,18 @@
 package valueobject
 
-// UserRole represents the role of a user
-type UserRole string
+import "github.com/vcd-simple-blog/packages/go/common/authz"
+
+// UserRole represents the role of a user.
+// Roles and the permissions they grant are shared by all services through the authz package.
+type UserRole = authz.Role
 
 const (
 	// RoleUser is the standard user role
-	RoleUser UserRole = "user"
+	RoleUser = authz.RoleUser
 
 	// RoleAdmin is the administrator role
-	RoleAdmin UserRole = "admin"
+	RoleAdmin = authz.RoleAdmin
 
 	// RoleAuthor is the author role
-	RoleAuthor UserRole = "author"
+	RoleAuthor = authz.RoleAuthor
 )
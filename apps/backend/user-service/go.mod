@@ -6,6 +6,7 @@ require (
 	github.com/golang-jwt/jwt/v4 v4.5.0
 	github.com/google/uuid v1.3.1
 	github.com/labstack/echo/v4 v4.11.3
+	github.com/vcd-simple-blog/packages/go/common v0.0.0
 	gorm.io/driver/postgres v1.5.4
 	gorm.io/gorm v1.25.5
 )
@@ -28,3 +29,5 @@ require (
 	golang.org/x/text v0.13.0 // indirect
 	golang.org/x/time v0.3.0 // indirect
 )
+
+replace github.com/vcd-simple-blog/packages/go/common => ../../../packages/go/common
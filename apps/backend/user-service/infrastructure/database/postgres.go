@@ -19,7 +19,7 @@ func NewPostgresDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	}
 
 	// Auto migrate the schema
-	if err := db.AutoMigrate(&entity.User{}); err != nil {
+	if err := db.AutoMigrate(&entity.User{}, &entity.ErasedProfile{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
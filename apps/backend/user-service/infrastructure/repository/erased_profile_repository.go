@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/user-service/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErasedProfileRepository implements the domain.repository.ErasedProfileRepository interface
+type ErasedProfileRepository struct {
+	db *gorm.DB
+}
+
+// NewErasedProfileRepository creates a new erased profile repository
+func NewErasedProfileRepository(db *gorm.DB) *ErasedProfileRepository {
+	return &ErasedProfileRepository{
+		db: db,
+	}
+}
+
+// Erase atomically deletes a user's profile, keeping a snapshot under the erasure ID
+func (r *ErasedProfileRepository) Erase(ctx context.Context, erasureID, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user *entity.User
+		var found entity.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&found, "user_id = ?", userID).Error
+		switch {
+		case err == nil:
+			user = &found
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		// A repeated erasure finds no profile and keeps the first snapshot
+		erased, err := entity.NewErasedProfile(erasureID, userID, user)
+		if err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(erased).Error; err != nil {
+			return err
+		}
+
+		if user == nil {
+			return nil
+		}
+		return tx.Delete(&entity.User{}, "id = ?", user.ID).Error
+	})
+}
+
+// Restore atomically recreates an erased profile from its snapshot
+func (r *ErasedProfileRepository) Restore(ctx context.Context, erasureID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var erased entity.ErasedProfile
+		err := tx.First(&erased, "erasure_id = ?", erasureID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err := erased.User()
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(user).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&entity.ErasedProfile{}, "erasure_id = ?", erasureID).Error
+	})
+}
+
+// Finalize deletes the snapshot of an erased profile
+func (r *ErasedProfileRepository) Finalize(ctx context.Context, erasureID string) error {
+	return r.db.WithContext(ctx).Delete(&entity.ErasedProfile{}, "erasure_id = ?", erasureID).Error
+}
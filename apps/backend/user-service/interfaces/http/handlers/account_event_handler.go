@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/user-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+// AccountEventHandler handles account events delivered by the auth service
+type AccountEventHandler struct {
+	accountEventUseCase *usecases.AccountEventUseCase
+}
+
+// NewAccountEventHandler creates a new account event handler
+func NewAccountEventHandler(accountEventUseCase *usecases.AccountEventUseCase) *AccountEventHandler {
+	return &AccountEventHandler{
+		accountEventUseCase: accountEventUseCase,
+	}
+}
+
+// Receive applies a delivered account event. Events that can never be applied get 422 so the
+// auth service dead-letters them; other failures get 500 so it retries.
+func (h *AccountEventHandler) Receive(c echo.Context) error {
+	var event accountevents.Envelope
+	if err := c.Bind(&event); err != nil || event.ID == "" || event.Type == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event"})
+	}
+
+	if err := h.accountEventUseCase.HandleEvent(c.Request().Context(), &event); err != nil {
+		if errors.Is(err, usecases.ErrEventRejected) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		log.Printf("failed to apply account event %s: %v", event.ID, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to apply event"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/user-service/usecases"
+)
+
+// DataExportHandler handles the requests the API gateway makes to build a user's personal data export
+type DataExportHandler struct {
+	exportUseCase *usecases.DataExportUseCase
+}
+
+// NewDataExportHandler creates a new data export handler
+func NewDataExportHandler(exportUseCase *usecases.DataExportUseCase) *DataExportHandler {
+	return &DataExportHandler{
+		exportUseCase: exportUseCase,
+	}
+}
+
+// Export handles exporting a user's profile
+func (h *DataExportHandler) Export(c echo.Context) error {
+	bundle, err := h.exportUseCase.ExportUserData(c.Request().Context(), c.Param("userID"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, bundle)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/apps/backend/user-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/erasure"
+)
+
+// ErasureHandler handles the erasure requests the auth service makes when deleting an account
+type ErasureHandler struct {
+	erasureUseCase *usecases.ErasureUseCase
+}
+
+// NewErasureHandler creates a new erasure handler
+func NewErasureHandler(erasureUseCase *usecases.ErasureUseCase) *ErasureHandler {
+	return &ErasureHandler{
+		erasureUseCase: erasureUseCase,
+	}
+}
+
+// Erase handles erasing a user's profile
+func (h *ErasureHandler) Erase(c echo.Context) error {
+	var req erasure.Request
+	if err := c.Bind(&req); err != nil || req.UserID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	if err := h.erasureUseCase.EraseProfile(c.Request().Context(), c.Param("id"), req.UserID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Revert handles undoing an erasure
+func (h *ErasureHandler) Revert(c echo.Context) error {
+	if err := h.erasureUseCase.RevertErasure(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Finalize handles making an erasure permanent
+func (h *ErasureHandler) Finalize(c echo.Context) error {
+	if err := h.erasureUseCase.FinalizeErasure(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,62 +1,90 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
-	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
 )
 
 // AuthMiddleware handles authentication
 type AuthMiddleware struct {
-	jwtSecret string
+	keys     jwks.KeyProvider
+	pats     pat.Verifier
+	cookies  cookieauth.Config
+	audience string
 }
 
-// NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(jwtSecret string) *AuthMiddleware {
+// NewAuthMiddleware creates a new auth middleware that verifies tokens against the auth service's public keys.
+// Tokens are read from the Authorization header or, for browser sessions, the access token cookie.
+// Service tokens must be issued for the audience, and a service acting for a user must forward the user's token,
+// which may be a personal access token verified by the auth service.
+func NewAuthMiddleware(keys jwks.KeyProvider, pats pat.Verifier, cookies cookieauth.Config, audience string) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSecret: jwtSecret,
+		keys:     keys,
+		pats:     pats,
+		cookies:  cookies,
+		audience: audience,
 	}
 }
 
 // Authenticate authenticates a request
 func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		// Get token from header
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
+		// Get token from header or cookie
+		tokenString, err := m.cookies.AccessToken(c.Request())
+		switch {
+		case errors.Is(err, cookieauth.ErrMissingToken):
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header is required"})
-		}
-
-		// Check if token is in correct format
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		case errors.Is(err, cookieauth.ErrInvalidCSRFToken):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Invalid CSRF token"})
+		case err != nil:
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid authorization format"})
 		}
 
 		// Parse token
-		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
-			return []byte(m.jwtSecret), nil
-		})
-
+		claims, err := m.parse(c, tokenString)
 		if err != nil {
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
 		}
 
-		if !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		// Tell end users from internal services
+		principal, err := authz.NewPrincipal(claims, m.audience, c.Request())
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 		}
+		c.Set(authz.PrincipalKey, principal)
+
+		// A service acting for a user is held to the user's own token
+		if principal.ActsForUser() {
+			subjectToken := c.Request().Header.Get(authz.SubjectTokenHeader)
+			if pat.IsToken(subjectToken) {
+				subject, err := m.pats.Verify(c.Request().Context(), subjectToken)
+				if err != nil || subject.UserID != principal.OnBehalfOf {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": authz.ErrInvalidSubjectToken.Error()})
+				}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token claims"})
+				c.Set("userID", subject.UserID)
+				c.Set("userRole", subject.Role)
+				return next(c)
+			}
+
+			// It must be the user's access token for this service, not another service's
+			claims, err = m.parse(c, subjectToken)
+			if err != nil || authz.CheckAccessToken(claims, m.audience) != nil ||
+				claims["sub"] != principal.OnBehalfOf || claims["client_id"] == principal.OnBehalfOf {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": authz.ErrInvalidSubjectToken.Error()})
+			}
 		}
 
 		// Set user ID in context
-		userID, ok := claims["sub"].(string)
-		if !ok {
+		userID := principal.UserID()
+		if userID == "" {
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid user ID in token"})
 		}
 		c.Set("userID", userID)
@@ -66,6 +94,39 @@ func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			c.Set("userRole", role)
 		}
 
+		// Tag requests made by an admin acting as the user
+		if actor := authz.Actor(claims); actor != "" {
+			c.Set("impersonatorID", actor)
+			authz.LogImpersonatedRequest(c.Request(), userID, actor)
+		}
+
 		return next(c)
 	}
 }
+
+// parse verifies a JWT against the auth service's public keys and returns its claims
+func (m *AuthMiddleware) parse(c echo.Context, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Only accept asymmetric signatures so a verifier can never forge tokens
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		default:
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return m.keys.Key(c.Request().Context(), kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	// Extract claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
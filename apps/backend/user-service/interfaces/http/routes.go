@@ -2,24 +2,46 @@ package http
 
 import (
 	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/vcd-simple-blog/apps/backend/user-service/interfaces/http/handlers"
 	"github.com/vcd-simple-blog/apps/backend/user-service/interfaces/http/middleware"
 	"github.com/vcd-simple-blog/apps/backend/user-service/usecases"
+	"github.com/vcd-simple-blog/packages/go/common/authz"
+	"github.com/vcd-simple-blog/packages/go/common/cookieauth"
+	"github.com/vcd-simple-blog/packages/go/common/jwks"
+	"github.com/vcd-simple-blog/packages/go/common/pat"
 )
 
 // RegisterRoutes registers all API routes
-func RegisterRoutes(e *echo.Echo, userUseCase *usecases.UserUseCase) {
+func RegisterRoutes(e *echo.Echo, userUseCase *usecases.UserUseCase, accountEventUseCase *usecases.AccountEventUseCase, erasureUseCase *usecases.ErasureUseCase, exportUseCase *usecases.DataExportUseCase) {
 	// Create handlers
 	userHandler := handlers.NewUserHandler(userUseCase)
+	accountEventHandler := handlers.NewAccountEventHandler(accountEventUseCase)
+	erasureHandler := handlers.NewErasureHandler(erasureUseCase)
+	exportHandler := handlers.NewDataExportHandler(exportUseCase)
 
 	// Create middleware
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "dev_secret_key"
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = "http://localhost:8081/.well-known/jwks.json"
 	}
-	authMiddleware := middleware.NewAuthMiddleware(jwtSecret)
+	patVerifyURL := os.Getenv("PAT_VERIFY_URL")
+	if patVerifyURL == "" {
+		patVerifyURL = "http://localhost:8081/api/v1/auth/tokens/verify"
+	}
+	serviceAudience := os.Getenv("SERVICE_AUDIENCE")
+	if serviceAudience == "" {
+		serviceAudience = "user-service"
+	}
+	authMiddleware := middleware.NewAuthMiddleware(
+		jwks.NewClient(jwksURL, 5*time.Minute),
+		pat.NewClient(patVerifyURL, 30*time.Second),
+		cookieauth.LoadConfig(),
+		serviceAudience,
+	)
+	authzMiddleware := authz.NewMiddleware(authz.NewChecker(authz.DefaultMatrix), "userRole")
 
 	// API v1 group
 	v1 := e.Group("/api/v1")
@@ -36,8 +58,23 @@ func RegisterRoutes(e *echo.Echo, userUseCase *usecases.UserUseCase) {
 	users.PUT("/me/profile", userHandler.UpdateProfile)
 	users.PUT("/me/profile-status", userHandler.UpdateProfileStatus)
 
-	// Admin routes for user creation (typically handled by auth service)
+	// Admin routes for user creation, used by admins and by internal services granted user:create
 	admin := v1.Group("/admin/users")
-	admin.Use(authMiddleware.Authenticate)
+	admin.Use(authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserCreate))
 	admin.POST("", userHandler.CreateUser)
+
+	// Internal routes for account events relayed by the auth service
+	internal := v1.Group("/internal")
+	internal.Use(authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserSync))
+	internal.POST("/account-events", accountEventHandler.Receive)
+
+	// Internal routes for erasing deleted accounts, called by the auth service
+	erasures := v1.Group("/internal/erasures", authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserErase))
+	erasures.PUT("/:id", erasureHandler.Erase)
+	erasures.DELETE("/:id", erasureHandler.Revert)
+	erasures.POST("/:id/finalize", erasureHandler.Finalize)
+
+	// Internal routes for personal data exports, called by the API gateway
+	exports := v1.Group("/internal/exports", authMiddleware.Authenticate, authzMiddleware.RequirePermission(authz.UserExport))
+	exports.GET("/:userID", exportHandler.Export)
 }
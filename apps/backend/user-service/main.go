@@ -28,9 +28,13 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	erasedProfileRepo := repository.NewErasedProfileRepository(db)
 
 	// Initialize use cases
 	userUseCase := usecases.NewUserUseCase(userRepo)
+	accountEventUseCase := usecases.NewAccountEventUseCase(userUseCase)
+	erasureUseCase := usecases.NewErasureUseCase(erasedProfileRepo)
+	exportUseCase := usecases.NewDataExportUseCase(userRepo)
 
 	// Create Echo instance
 	e := echo.New()
@@ -41,7 +45,7 @@ func main() {
 	e.Use(middleware.CORS())
 
 	// Initialize API routes
-	http.RegisterRoutes(e, userUseCase)
+	http.RegisterRoutes(e, userUseCase, accountEventUseCase, erasureUseCase, exportUseCase)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/vcd-simple-blog/packages/go/common/accountevents"
+)
+
+// ErrEventRejected is returned for account events that can never be applied, so redelivering them cannot help
+var ErrEventRejected = errors.New("account event cannot be applied")
+
+// AccountEventUseCase keeps user profiles in step with accounts in the auth service.
+// Every event can be applied more than once with the same result, as the auth service redelivers
+// events until they are acknowledged.
+type AccountEventUseCase struct {
+	userUseCase *UserUseCase
+}
+
+// NewAccountEventUseCase creates a new account event use case
+func NewAccountEventUseCase(userUseCase *UserUseCase) *AccountEventUseCase {
+	return &AccountEventUseCase{
+		userUseCase: userUseCase,
+	}
+}
+
+// HandleEvent applies an account event. Unknown event types are acknowledged and ignored.
+func (uc *AccountEventUseCase) HandleEvent(ctx context.Context, event *accountevents.Envelope) error {
+	switch event.Type {
+	case accountevents.UserRegisteredType:
+		var payload accountevents.UserRegistered
+		if err := decodePayload(event, &payload); err != nil {
+			return err
+		}
+		return uc.provisionUser(ctx, &payload)
+	case accountevents.UserEmailChangedType:
+		var payload accountevents.UserEmailChanged
+		if err := decodePayload(event, &payload); err != nil {
+			return err
+		}
+		return uc.changeEmail(ctx, &payload)
+	case accountevents.UserDeletedType:
+		var payload accountevents.UserDeleted
+		if err := decodePayload(event, &payload); err != nil {
+			return err
+		}
+		return uc.deleteUser(ctx, &payload)
+	default:
+		log.Printf("ignoring account event %s of unknown type %s", event.ID, event.Type)
+		return nil
+	}
+}
+
+// provisionUser creates the profile of a newly registered user, unless it already exists
+func (uc *AccountEventUseCase) provisionUser(ctx context.Context, payload *accountevents.UserRegistered) error {
+	if _, err := uc.userUseCase.GetUserByUserID(ctx, payload.UserID); err == nil {
+		return nil
+	}
+
+	_, err := uc.userUseCase.CreateUser(ctx, payload.UserID, payload.Username, payload.Email)
+	if errors.Is(err, ErrUserExists) {
+		return nil
+	}
+	if errors.Is(err, ErrUsernameTaken) {
+		return fmt.Errorf("%w: %v", ErrEventRejected, err)
+	}
+	return err
+}
+
+// changeEmail updates the email address of a user's profile
+func (uc *AccountEventUseCase) changeEmail(ctx context.Context, payload *accountevents.UserEmailChanged) error {
+	user, err := uc.userUseCase.GetUserByUserID(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("%w: no profile for user %s", ErrEventRejected, payload.UserID)
+	}
+	if user.Email == payload.Email {
+		return nil
+	}
+
+	_, err = uc.userUseCase.ChangeEmail(ctx, user.ID, payload.Email)
+	return err
+}
+
+// deleteUser deletes a user's profile, if it still exists
+func (uc *AccountEventUseCase) deleteUser(ctx context.Context, payload *accountevents.UserDeleted) error {
+	user, err := uc.userUseCase.GetUserByUserID(ctx, payload.UserID)
+	if err != nil {
+		return nil
+	}
+
+	return uc.userUseCase.DeleteUser(ctx, user.ID)
+}
+
+// decodePayload decodes an event's payload, rejecting events whose payload does not match their type
+func decodePayload(event *accountevents.Envelope, payload interface{}) error {
+	if err := json.Unmarshal(event.Payload, payload); err != nil {
+		return fmt.Errorf("%w: invalid %s payload: %v", ErrEventRejected, event.Type, err)
+	}
+	return nil
+}
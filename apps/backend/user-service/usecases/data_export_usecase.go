@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vcd-simple-blog/apps/backend/user-service/domain/repository"
+	"github.com/vcd-simple-blog/packages/go/common/dataexport"
+)
+
+// exportedProfile is a user's profile in their data export
+type exportedProfile struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	AvatarURL     string    `json:"avatar_url"`
+	ProfileStatus string    `json:"profile_status"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DataExportUseCase gathers a user's profile for their personal data export
+type DataExportUseCase struct {
+	userRepo repository.UserRepository
+}
+
+// NewDataExportUseCase creates a new data export use case
+func NewDataExportUseCase(userRepo repository.UserRepository) *DataExportUseCase {
+	return &DataExportUseCase{
+		userRepo: userRepo,
+	}
+}
+
+// ExportUserData exports a user's profile as JSON. A user without a profile exports nothing.
+func (uc *DataExportUseCase) ExportUserData(ctx context.Context, userID string) (*dataexport.Bundle, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	bundle := &dataexport.Bundle{}
+
+	// Find profile
+	user, err := uc.userRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return bundle, nil
+		}
+		return nil, err
+	}
+
+	err = bundle.AddJSON("profile.json", exportedProfile{
+		ID:            user.ID,
+		UserID:        user.UserID,
+		Username:      user.Username,
+		Email:         user.Email,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarURL:     user.AvatarURL,
+		ProfileStatus: string(user.ProfileStatus),
+		Role:          string(user.Role),
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
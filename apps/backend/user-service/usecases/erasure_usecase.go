@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vcd-simple-blog/apps/backend/user-service/domain/repository"
+)
+
+// ErasureUseCase removes the profiles of deleted accounts
+type ErasureUseCase struct {
+	erasedProfileRepo repository.ErasedProfileRepository
+}
+
+// NewErasureUseCase creates a new erasure use case
+func NewErasureUseCase(erasedProfileRepo repository.ErasedProfileRepository) *ErasureUseCase {
+	return &ErasureUseCase{
+		erasedProfileRepo: erasedProfileRepo,
+	}
+}
+
+// EraseProfile deletes a user's profile, keeping a snapshot until the erasure is finalized
+func (uc *ErasureUseCase) EraseProfile(ctx context.Context, erasureID, userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+	return uc.erasedProfileRepo.Erase(ctx, erasureID, userID)
+}
+
+// RevertErasure restores an erased profile
+func (uc *ErasureUseCase) RevertErasure(ctx context.Context, erasureID string) error {
+	return uc.erasedProfileRepo.Restore(ctx, erasureID)
+}
+
+// FinalizeErasure deletes the snapshot of an erased profile
+func (uc *ErasureUseCase) FinalizeErasure(ctx context.Context, erasureID string) error {
+	return uc.erasedProfileRepo.Finalize(ctx, erasureID)
+}
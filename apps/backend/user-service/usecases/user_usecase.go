@@ -10,6 +10,12 @@ import (
 	"github.com/vcd-simple-blog/apps/backend/user-service/domain/valueobject"
 )
 
+// ErrUserExists is returned when a profile already exists for an auth service user ID
+var ErrUserExists = errors.New("user already exists with this user ID")
+
+// ErrUsernameTaken is returned when another profile already has the username
+var ErrUsernameTaken = errors.New("username is already taken")
+
 // UserUseCase implements user-related use cases
 type UserUseCase struct {
 	userRepo repository.UserRepository
@@ -50,13 +56,13 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, userID, username, email s
 	// Check if user already exists with this userID
 	existingUser, err := uc.userRepo.FindByUserID(ctx, userID)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("user already exists with this user ID")
+		return nil, ErrUserExists
 	}
 
 	// Check if username is taken
 	existingUser, err = uc.userRepo.FindByUsername(ctx, username)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("username is already taken")
+		return nil, ErrUsernameTaken
 	}
 
 	// Create new user
@@ -112,6 +118,27 @@ func (uc *UserUseCase) UpdateProfileStatus(ctx context.Context, id string, statu
 	return user, nil
 }
 
+// ChangeEmail changes a user's email address
+func (uc *UserUseCase) ChangeEmail(ctx context.Context, id, email string) (*entity.User, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update email
+	if err := user.ChangeEmail(email); err != nil {
+		return nil, err
+	}
+
+	// Save changes
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // DeleteUser deletes a user
 func (uc *UserUseCase) DeleteUser(ctx context.Context, id string) error {
 	// Check if user exists
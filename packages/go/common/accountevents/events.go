@@ -0,0 +1,51 @@
+// Package accountevents defines the account lifecycle events the auth service publishes
+// through its outbox and other services consume.
+package accountevents
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Type identifies a kind of account event
+type Type string
+
+const (
+	// UserRegisteredType is published when an account is created
+	UserRegisteredType Type = "UserRegistered"
+
+	// UserEmailChangedType is published when an account's email address changes
+	UserEmailChangedType Type = "UserEmailChanged"
+
+	// UserDeletedType is published when an account is deleted
+	UserDeletedType Type = "UserDeleted"
+)
+
+// Envelope carries one event. Consumers use the ID to recognise redelivered events,
+// and may see events again but never out of order for the same user.
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       Type            `json:"type"`
+	UserID     string          `json:"user_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// UserRegistered is the payload of a UserRegisteredType event
+type UserRegistered struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// UserEmailChanged is the payload of a UserEmailChangedType event
+type UserEmailChanged struct {
+	UserID        string `json:"user_id"`
+	Email         string `json:"email"`
+	PreviousEmail string `json:"previous_email"`
+}
+
+// UserDeleted is the payload of a UserDeletedType event
+type UserDeleted struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,155 @@
+// Package authz defines the roles and permissions shared by all services and checks them
+// against a declarative permission matrix.
+package authz
+
+import "errors"
+
+// ErrForbidden is returned when a role lacks a required permission
+var ErrForbidden = errors.New("forbidden")
+
+// Role is the role of a user, as carried in the role claim of access tokens
+type Role string
+
+const (
+	// RoleUser is the standard user role
+	RoleUser Role = "user"
+
+	// RoleAuthor is the author role
+	RoleAuthor Role = "author"
+
+	// RoleAdmin is the administrator role
+	RoleAdmin Role = "admin"
+)
+
+// Permission is an action a role may perform, written as "resource:action" or "resource:action:any"
+// where ":any" extends the action to resources owned by other users
+type Permission string
+
+const (
+	// BlogCreate allows writing blogs
+	BlogCreate Permission = "blog:create"
+
+	// BlogPublish allows publishing one's own blogs
+	BlogPublish Permission = "blog:publish"
+
+	// BlogDeleteAny allows deleting any user's blog
+	BlogDeleteAny Permission = "blog:delete:any"
+
+	// UserCreate allows creating user profiles directly
+	UserCreate Permission = "user:create"
+
+	// UserManage allows administering other users' accounts
+	UserManage Permission = "user:manage"
+
+	// UserRoleAssign allows changing users' roles
+	UserRoleAssign Permission = "user:role:assign"
+
+	// UserImpersonate allows acting as another user with an impersonation token
+	UserImpersonate Permission = "user:impersonate"
+
+	// OAuthClientManage allows registering and deleting OAuth clients
+	OAuthClientManage Permission = "oauth:client:manage"
+
+	// TokenRevokeAny allows revoking any user's tokens
+	TokenRevokeAny Permission = "token:revoke:any"
+
+	// AuditRead allows querying and exporting the security audit log
+	AuditRead Permission = "audit:read"
+
+	// UserSync allows delivering account events from the auth service to user profiles.
+	// It is only granted to services as a client-credentials scope.
+	UserSync Permission = "user:sync"
+
+	// UserErase allows erasing a deleted account's data as part of account deletion.
+	// It is only granted to services as a client-credentials scope.
+	UserErase Permission = "user:erase"
+
+	// UserExport allows reading all of a user's data to build their personal data export.
+	// It is only granted to services as a client-credentials scope.
+	UserExport Permission = "user:export"
+
+	// ActOnBehalf allows an internal service to act for the end user named in the X-On-Behalf-Of header.
+	// It is only granted to services as a client-credentials scope.
+	ActOnBehalf Permission = "user:act_on_behalf"
+)
+
+// Matrix maps each role to the permissions it is granted
+type Matrix map[Role][]Permission
+
+// DefaultMatrix is the permission matrix used by all services
+var DefaultMatrix = Matrix{
+	RoleUser: {
+		BlogCreate,
+		BlogPublish,
+	},
+	RoleAuthor: {
+		BlogCreate,
+		BlogPublish,
+	},
+	RoleAdmin: {
+		BlogCreate,
+		BlogPublish,
+		BlogDeleteAny,
+		UserCreate,
+		UserManage,
+		UserRoleAssign,
+		UserImpersonate,
+		OAuthClientManage,
+		TokenRevokeAny,
+		AuditRead,
+	},
+}
+
+// Checker checks roles against a permission matrix
+type Checker struct {
+	grants map[Role]map[Permission]bool
+}
+
+// NewChecker creates a new checker for a permission matrix
+func NewChecker(matrix Matrix) *Checker {
+	grants := make(map[Role]map[Permission]bool, len(matrix))
+	for role, permissions := range matrix {
+		grants[role] = make(map[Permission]bool, len(permissions))
+		for _, permission := range permissions {
+			grants[role][permission] = true
+		}
+	}
+
+	return &Checker{
+		grants: grants,
+	}
+}
+
+// Can checks if a role is granted every one of the permissions.
+// Unknown roles are granted nothing.
+func (c *Checker) Can(role string, permissions ...Permission) bool {
+	granted := c.grants[Role(role)]
+	for _, permission := range permissions {
+		if !granted[permission] {
+			return false
+		}
+	}
+	return true
+}
+
+// Allows checks if a principal is granted every one of the permissions.
+// Services are granted the permissions named by their token's scopes; users those of their role.
+func (c *Checker) Allows(principal *Principal, permissions ...Permission) bool {
+	if !principal.IsService() {
+		return c.Can(string(principal.Role), permissions...)
+	}
+	for _, permission := range permissions {
+		if !principal.HasScope(string(permission)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Require returns ErrForbidden unless a role is granted every one of the permissions
+func (c *Checker) Require(role string, permissions ...Permission) error {
+	if !c.Can(role, permissions...) {
+		return ErrForbidden
+	}
+	return nil
+}
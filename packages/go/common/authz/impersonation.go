@@ -0,0 +1,22 @@
+package authz
+
+import (
+	"log"
+	"net/http"
+)
+
+// Actor returns the user named by the act claim (RFC 8693) of an impersonation token,
+// or an empty string for a token that acts for its own subject
+func Actor(claims map[string]interface{}) string {
+	act, ok := claims["act"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	actor, _ := act["sub"].(string)
+	return actor
+}
+
+// LogImpersonatedRequest tags a request made with an impersonation token in the log
+func LogImpersonatedRequest(r *http.Request, subject, actor string) {
+	log.Printf("IMPERSONATED REQUEST: %s %s sub=%s act=%s", r.Method, r.URL.Path, subject, actor)
+}
@@ -0,0 +1,51 @@
+package authz
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware enforces permissions on Echo routes, reading the role an auth middleware
+// stored in the request context, or the scopes of a service principal.
+// A service acting on behalf of a user is held to the user's role.
+type Middleware struct {
+	checker *Checker
+	roleKey string
+}
+
+// NewMiddleware creates a new authorization middleware that reads the role from the given context key
+func NewMiddleware(checker *Checker, roleKey string) *Middleware {
+	return &Middleware{
+		checker: checker,
+		roleKey: roleKey,
+	}
+}
+
+// RequirePermission rejects requests whose role is not granted every one of the permissions.
+// It must run after authentication.
+func (m *Middleware) RequirePermission(permissions ...Permission) echo.MiddlewareFunc {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	message := "Missing permission " + strings.Join(names, ", ")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var allowed bool
+			if principal, ok := c.Get(PrincipalKey).(*Principal); ok && principal.IsService() && !principal.ActsForUser() {
+				allowed = m.checker.Allows(principal, permissions...)
+			} else {
+				role, _ := c.Get(m.roleKey).(string)
+				allowed = m.checker.Can(role, permissions...)
+			}
+			if !allowed {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": message})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package authz
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// PrincipalKey is the context key auth middlewares store the request's principal under
+const PrincipalKey = "principal"
+
+// OnBehalfOfHeader names the end user a service principal acts for
+const OnBehalfOfHeader = "X-On-Behalf-Of"
+
+// SubjectTokenHeader carries the access token or personal access token of the end user a service acts for.
+// Receiving services verify it and take the user's role, verification and actor from it, never from the service token.
+const SubjectTokenHeader = "X-Subject-Token"
+
+// TokenUseClaim tells access tokens apart from ID tokens, which are signed with the same keys
+const TokenUseClaim = "token_use"
+
+const (
+	// TokenUseAccess marks access tokens, the only tokens accepted as bearer tokens
+	TokenUseAccess = "access"
+
+	// TokenUseID marks OpenID Connect ID tokens, which only tell a client who signed in
+	TokenUseID = "id"
+)
+
+// ErrNotAccessToken is returned when a bearer token is not an access token, such as an ID token
+var ErrNotAccessToken = errors.New("token is not an access token")
+
+// ErrInvalidAudience is returned when a token was not issued for the receiving service
+var ErrInvalidAudience = errors.New("token was not issued for this service")
+
+// ErrOnBehalfOfNotAllowed is returned when a principal names a user to act for without being allowed to
+var ErrOnBehalfOfNotAllowed = errors.New("principal may not act on behalf of users")
+
+// ErrInvalidSubjectToken is returned when a service acts for a user without a valid token of that user
+var ErrInvalidSubjectToken = errors.New("invalid subject token")
+
+// PrincipalType distinguishes end users from internal services
+type PrincipalType string
+
+const (
+	// PrincipalUser is an end user authenticated with a session or OAuth access token
+	PrincipalUser PrincipalType = "user"
+
+	// PrincipalService is an internal service authenticated with a client-credentials token
+	PrincipalService PrincipalType = "service"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Type       PrincipalType
+	Subject    string
+	Role       Role
+	ClientID   string
+	Scopes     []string
+	Audiences  []string
+	OnBehalfOf string
+}
+
+// CheckAccessToken checks that verified token claims are those of an access token issued for the audience.
+// ID tokens, and access tokens issued for other services or to OAuth clients, are refused.
+func CheckAccessToken(claims map[string]interface{}, audience string) error {
+	if claims[TokenUseClaim] != TokenUseAccess {
+		return ErrNotAccessToken
+	}
+	for _, a := range audiences(claims["aud"]) {
+		if a == audience {
+			return nil
+		}
+	}
+	return ErrInvalidAudience
+}
+
+// NewPrincipal builds the principal for verified access token claims, which must be issued for the audience.
+// Client-credentials tokens, whose subject is the client itself, are service principals. A service granted
+// ActOnBehalf may name the end user it acts for in the X-On-Behalf-Of header, along with the user's own token
+// in the X-Subject-Token header.
+func NewPrincipal(claims map[string]interface{}, audience string, r *http.Request) (*Principal, error) {
+	if err := CheckAccessToken(claims, audience); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	clientID, _ := claims["client_id"].(string)
+	role, _ := claims["role"].(string)
+	scope, _ := claims["scope"].(string)
+
+	principal := &Principal{
+		Type:      PrincipalUser,
+		Subject:   subject,
+		Role:      Role(role),
+		ClientID:  clientID,
+		Scopes:    strings.Fields(scope),
+		Audiences: audiences(claims["aud"]),
+	}
+
+	onBehalfOf := r.Header.Get(OnBehalfOfHeader)
+	if clientID == "" || clientID != subject {
+		if onBehalfOf != "" {
+			return nil, ErrOnBehalfOfNotAllowed
+		}
+		return principal, nil
+	}
+
+	principal.Type = PrincipalService
+	if onBehalfOf != "" {
+		if !principal.HasScope(string(ActOnBehalf)) {
+			return nil, ErrOnBehalfOfNotAllowed
+		}
+		principal.OnBehalfOf = onBehalfOf
+	}
+	return principal, nil
+}
+
+// IsService checks if the principal is an internal service
+func (p *Principal) IsService() bool {
+	return p.Type == PrincipalService
+}
+
+// ActsForUser checks if the principal is a service acting on behalf of an end user
+func (p *Principal) ActsForUser() bool {
+	return p.OnBehalfOf != ""
+}
+
+// UserID returns the user the request acts for: the token's subject, or the end user a service acts on behalf of
+func (p *Principal) UserID() string {
+	if p.OnBehalfOf != "" {
+		return p.OnBehalfOf
+	}
+	return p.Subject
+}
+
+// HasScope checks if the principal's token was granted a scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAudience checks if the principal's token was issued for an audience
+func (p *Principal) HasAudience(audience string) bool {
+	for _, a := range p.Audiences {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// audiences reads the aud claim, which is either a string or an array of strings
+func audiences(aud interface{}) []string {
+	switch v := aud.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
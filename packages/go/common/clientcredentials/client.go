@@ -0,0 +1,167 @@
+// Package clientcredentials obtains OAuth 2.0 client-credentials tokens from the auth service
+// and attaches them to requests between internal services.
+package clientcredentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryLeeway is how long before expiry a token is replaced, so it never expires in flight
+const expiryLeeway = 30 * time.Second
+
+// Config identifies the calling service and the token it needs
+type Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Audience     string
+	Scopes       []string
+}
+
+// tokenResponse is an OAuth 2.0 token response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// TokenSource fetches client-credentials tokens and reuses each one until shortly before it expires
+type TokenSource struct {
+	config     Config
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewTokenSource creates a new token source
+func NewTokenSource(config Config) *TokenSource {
+	return &TokenSource{
+		config:     config,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Token returns a valid access token, fetching a new one when needed
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.expires) {
+		return s.token, nil
+	}
+
+	token, expiresIn, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expires = time.Now().Add(expiresIn - expiryLeeway)
+	return s.token, nil
+}
+
+// Invalidate discards the current token so the next request fetches a new one
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+// fetch requests a token from the auth service's token endpoint
+func (s *TokenSource) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if s.config.Audience != "" {
+		form.Set("audience", s.config.Audience)
+	}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.config.ClientID, s.config.ClientSecret)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch client credentials token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("unexpected status %d fetching client credentials token", resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", 0, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
+
+// Transport is an http.RoundTripper that authenticates requests with a client-credentials token.
+// A request rejected with 401 is retried once with a fresh token when its body can be replayed.
+type Transport struct {
+	Source *TokenSource
+	Base   http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// The token may have been revoked or the signing key rotated; retry with a new one
+	resp.Body.Close()
+	t.Source.Invalidate()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return t.send(retry)
+}
+
+// send attaches the current token and sends the request
+func (t *Transport) send(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	authenticated := req.Clone(req.Context())
+	authenticated.Header.Set("Authorization", "Bearer "+token)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(authenticated)
+}
+
+// NewHTTPClient creates an HTTP client that authenticates every request with the token source
+func NewHTTPClient(source *TokenSource, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &Transport{Source: source},
+		Timeout:   timeout,
+	}
+}
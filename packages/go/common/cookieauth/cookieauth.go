@@ -0,0 +1,82 @@
+package cookieauth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Config holds the names of the cookies and header used by cookie-based sessions
+type Config struct {
+	AccessTokenCookie string
+	CSRFCookie        string
+	CSRFHeader        string
+}
+
+// ErrMissingToken is returned when a request carries neither an Authorization header nor an access token cookie
+var ErrMissingToken = errors.New("authorization header or session cookie is required")
+
+// ErrInvalidAuthorization is returned when the Authorization header is not a bearer token
+var ErrInvalidAuthorization = errors.New("invalid authorization format")
+
+// ErrInvalidCSRFToken is returned when a cookie-authenticated unsafe request fails the double-submit check
+var ErrInvalidCSRFToken = errors.New("invalid CSRF token")
+
+// LoadConfig loads cookie names from environment variables
+func LoadConfig() Config {
+	cfg := Config{
+		AccessTokenCookie: os.Getenv("ACCESS_TOKEN_COOKIE"),
+		CSRFCookie:        os.Getenv("CSRF_COOKIE"),
+		CSRFHeader:        os.Getenv("CSRF_HEADER"),
+	}
+	if cfg.AccessTokenCookie == "" {
+		cfg.AccessTokenCookie = "access_token"
+	}
+	if cfg.CSRFCookie == "" {
+		cfg.CSRFCookie = "csrf_token"
+	}
+	if cfg.CSRFHeader == "" {
+		cfg.CSRFHeader = "X-CSRF-Token"
+	}
+	return cfg
+}
+
+// AccessToken returns the bearer token from the Authorization header, falling back to the access token cookie.
+// Requests authenticated by cookie must pass the CSRF check.
+func (cfg Config) AccessToken(r *http.Request) (string, error) {
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return "", ErrInvalidAuthorization
+		}
+		return parts[1], nil
+	}
+
+	cookie, err := r.Cookie(cfg.AccessTokenCookie)
+	if err != nil || cookie.Value == "" {
+		return "", ErrMissingToken
+	}
+
+	if !cfg.VerifyCSRF(r) {
+		return "", ErrInvalidCSRFToken
+	}
+	return cookie.Value, nil
+}
+
+// VerifyCSRF checks that unsafe requests echo the CSRF cookie in the CSRF header (double-submit)
+func (cfg Config) VerifyCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	cookie, err := r.Cookie(cfg.CSRFCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	header := r.Header.Get(cfg.CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) == 1
+}
@@ -0,0 +1,37 @@
+// Package dataexport defines the protocol the API gateway uses to gather a user's personal data
+// from the services that hold it.
+//
+// Each service exposes its share of a user's data:
+//
+//	GET /api/v1/internal/exports/:userID  return the user's data as a Bundle
+//
+// The gateway packages the bundles of all services into a single archive, one directory per service.
+package dataexport
+
+import "encoding/json"
+
+// File is a file of a bundle
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Bundle is the files a service exports for a user
+type Bundle struct {
+	Files []File `json:"files"`
+}
+
+// AddJSON adds a file holding a value encoded as indented JSON
+func (b *Bundle) AddJSON(path string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	b.AddText(path, string(content)+"\n")
+	return nil
+}
+
+// AddText adds a text file, such as a Markdown document
+func (b *Bundle) AddText(path, content string) {
+	b.Files = append(b.Files, File{Path: path, Content: content})
+}
@@ -0,0 +1,17 @@
+// Package erasure defines the protocol the auth service uses to erase a deleted account's data
+// from the services that hold it.
+//
+// Each service exposes an erasure resource, named by the ID the auth service assigns:
+//
+//	PUT    /api/v1/internal/erasures/:id           erase the user's data, reversibly (body: Request)
+//	DELETE /api/v1/internal/erasures/:id           undo the erasure while the account still exists
+//	POST   /api/v1/internal/erasures/:id/finalize  forget what undoing needs, making the erasure permanent
+//
+// All three calls are idempotent so the auth service can retry them after failures and crashes.
+// They return 204 No Content on success.
+package erasure
+
+// Request is the body of a request to erase a user's data
+type Request struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,130 @@
+package introspection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInactiveToken is returned when the auth service reports a token as revoked, expired or unknown
+var ErrInactiveToken = errors.New("inactive token")
+
+// Result is an OAuth 2.0 token introspection response (RFC 7662)
+type Result struct {
+	Active    bool   `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	JTI       string `json:"jti,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Verified  bool   `json:"verified,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+}
+
+// cachedResult is an introspection result with the time it stops being trusted
+type cachedResult struct {
+	result *Result
+	until  time.Time
+}
+
+// Client introspects tokens at the auth service as a confidential OAuth client and caches the results briefly,
+// so a revoked token stops working within the cache TTL
+type Client struct {
+	url          string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	cacheTTL     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedResult
+}
+
+// NewClient creates a new introspection client
+func NewClient(url, clientID, clientSecret string, cacheTTL time.Duration) *Client {
+	return &Client{
+		url:          url,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+		cacheTTL:     cacheTTL,
+		cache:        make(map[string]cachedResult),
+	}
+}
+
+// Introspect returns the introspection result for an active token, or ErrInactiveToken
+func (c *Client) Introspect(ctx context.Context, token string) (*Result, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if !ok || !now.Before(cached.until) {
+		result, err := c.fetch(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		until := now.Add(c.cacheTTL)
+		if result.Exp > 0 && time.Unix(result.Exp, 0).Before(until) {
+			until = time.Unix(result.Exp, 0)
+		}
+
+		c.mu.Lock()
+		for k, v := range c.cache {
+			if !now.Before(v.until) {
+				delete(c.cache, k)
+			}
+		}
+		c.cache[key] = cachedResult{result: result, until: until}
+		c.mu.Unlock()
+
+		cached = cachedResult{result: result, until: until}
+	}
+
+	if !cached.result.Active {
+		return nil, ErrInactiveToken
+	}
+	return cached.result, nil
+}
+
+// fetch asks the auth service to introspect a token
+func (c *Client) fetch(ctx context.Context, token string) (*Result, error) {
+	form := url.Values{}
+	form.Set("token", token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d introspecting token", resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,193 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JSONWebKey represents a public key in JWK format (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// KeySet represents a JWK set document
+type KeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeyProvider resolves the public key for a key ID
+type KeyProvider interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// Client fetches and caches the public keys published at a JWKS endpoint
+type Client struct {
+	url             string
+	httpClient      *http.Client
+	cacheTTL        time.Duration
+	minRefreshDelay time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewClient creates a new JWKS client
+func NewClient(url string, cacheTTL time.Duration) *Client {
+	return &Client{
+		url:             url,
+		httpClient:      &http.Client{Timeout: 5 * time.Second},
+		cacheTTL:        cacheTTL,
+		minRefreshDelay: 10 * time.Second,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+
+// Key returns the public key with the given key ID.
+// Unknown key IDs trigger a refresh so newly rotated keys are picked up quickly.
+func (c *Client) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	fresh := time.Since(c.fetchedAt) < c.cacheTTL
+	recent := time.Since(c.fetchedAt) < c.minRefreshDelay
+	c.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+
+	// Avoid hammering the endpoint with unknown key IDs
+	if !ok && recent {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		// Keep serving cached keys if the endpoint is temporarily unavailable
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set and replaces the cache
+func (c *Client) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set KeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// PublicKey converts the JWK into an RSA or Ed25519 public key
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// NewJSONWebKey converts an RSA or Ed25519 public key into a JWK
+func NewJSONWebKey(kid, alg string, key crypto.PublicKey) (JSONWebKey, error) {
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return JSONWebKey{}, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
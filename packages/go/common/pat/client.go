@@ -0,0 +1,150 @@
+package pat
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix marks personal access tokens so verifiers can tell them apart from JWTs
+const Prefix = "vsb_pat_"
+
+// ErrInvalidToken is returned when a personal access token is unknown, expired or revoked
+var ErrInvalidToken = errors.New("invalid personal access token")
+
+// Principal describes the user and scopes a personal access token acts for
+type Principal struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	Verified  bool      `json:"verified"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HasScope checks if the token was granted a scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyRequest represents the request for verifying a personal access token
+type VerifyRequest struct {
+	Token string `json:"token"`
+}
+
+// IsToken checks if a bearer token is a personal access token
+func IsToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// Verifier resolves a personal access token to its principal
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// cachedPrincipal is a verification result with the time it stops being trusted
+type cachedPrincipal struct {
+	principal *Principal
+	until     time.Time
+}
+
+// Client verifies personal access tokens against the auth service and caches the results briefly,
+// so a revoked token stops working within the cache TTL
+type Client struct {
+	url        string
+	httpClient *http.Client
+	cacheTTL   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedPrincipal
+}
+
+// NewClient creates a new personal access token client
+func NewClient(url string, cacheTTL time.Duration) *Client {
+	return &Client{
+		url:        url,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cacheTTL:   cacheTTL,
+		cache:      make(map[string]cachedPrincipal),
+	}
+}
+
+// Verify returns the principal for a personal access token
+func (c *Client) Verify(ctx context.Context, token string) (*Principal, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.until) {
+		return cached.principal, nil
+	}
+
+	principal, err := c.fetch(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	until := now.Add(c.cacheTTL)
+	if principal.ExpiresAt.Before(until) {
+		until = principal.ExpiresAt
+	}
+
+	c.mu.Lock()
+	for k, v := range c.cache {
+		if !now.Before(v.until) {
+			delete(c.cache, k)
+		}
+	}
+	c.cache[key] = cachedPrincipal{principal: principal, until: until}
+	c.mu.Unlock()
+
+	return principal, nil
+}
+
+// fetch asks the auth service to verify a token
+func (c *Client) fetch(ctx context.Context, token string) (*Principal, error) {
+	body, err := json.Marshal(VerifyRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify personal access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, ErrInvalidToken
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %d verifying personal access token", resp.StatusCode)
+	}
+
+	var principal Principal
+	if err := json.NewDecoder(resp.Body).Decode(&principal); err != nil {
+		return nil, fmt.Errorf("failed to decode personal access token principal: %w", err)
+	}
+	return &principal, nil
+}